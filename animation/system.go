@@ -3,9 +3,11 @@ package animation
 import (
 	"time"
 
-	"golang.org/x/exp/maps"
+	"github.com/jamestunnell/topdown/ordered"
 )
 
+// System animates the animatables in order of priority (see
+// ordered.Prioritized), then in the order they were added.
 type System interface {
 	Add(id string, x interface{})
 	Remove(id string)
@@ -15,33 +17,33 @@ type System interface {
 }
 
 type system struct {
-	animatables map[string]Animatable
+	animatables *ordered.Map[string, Animatable]
 }
 
 func NewSystem() System {
 	return &system{
-		animatables: map[string]Animatable{},
+		animatables: ordered.NewMap[string, Animatable](),
 	}
 }
 
 func (s *system) Add(id string, x interface{}) {
 	if a, ok := x.(Animatable); ok {
-		s.animatables[id] = a
+		s.animatables.SetWithPriority(id, a, ordered.PriorityOf(x))
 	}
 }
 
 func (s *system) Remove(id string) {
-	delete(s.animatables, id)
+	s.animatables.Delete(id)
 }
 
 func (s *system) Clear() {
-	maps.Clear(s.animatables)
+	s.animatables.Clear()
 }
 
 func (s *system) Animate(deltaSec float64) {
 	delta := time.Duration(deltaSec * 1e9)
 
-	for _, a := range s.animatables {
+	for _, a := range s.animatables.Values() {
 		a.UpdateAnimation(delta)
 	}
 }
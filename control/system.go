@@ -4,12 +4,14 @@ import (
 	"time"
 
 	"github.com/jamestunnell/topdown/input"
+	"github.com/jamestunnell/topdown/ordered"
 	"github.com/rs/zerolog/log"
-	"golang.org/x/exp/maps"
 )
 
 //go:generate mockgen -destination=mock_control/mocksystem.go . System
 
+// System controls the controllables in order of priority (see
+// ordered.Prioritized), then in the order they were added.
 type System interface {
 	Add(id string, x any)
 	Remove(id string)
@@ -19,13 +21,13 @@ type System interface {
 }
 
 type system struct {
-	controllables map[string]Controllable
+	controllables *ordered.Map[string, Controllable]
 	inputMgr      input.Manager
 }
 
 func NewSystem() System {
 	return &system{
-		controllables: map[string]Controllable{},
+		controllables: ordered.NewMap[string, Controllable](),
 		inputMgr:      input.NewManager(),
 	}
 }
@@ -40,12 +42,12 @@ func (s *system) Add(id string, x any) {
 
 		log.Debug().Str("id", id).Msg("adding controllable")
 
-		s.controllables[id] = c
+		s.controllables.SetWithPriority(id, c, ordered.PriorityOf(x))
 	}
 }
 
 func (s *system) Remove(id string) {
-	c, found := s.controllables[id]
+	c, found := s.controllables.Get(id)
 	if !found {
 		return
 	}
@@ -56,25 +58,25 @@ func (s *system) Remove(id string) {
 		s.inputMgr.UnwatchKey(key)
 	}
 
-	delete(s.controllables, id)
+	s.controllables.Delete(id)
 }
 
 func (s *system) Clear() {
-	for id, c := range s.controllables {
+	s.controllables.Each(func(id string, c Controllable) {
 		for _, key := range c.WatchKeys() {
 			log.Debug().Str("id", id).Stringer("key", key).Msg("un-watching key")
 
 			s.inputMgr.UnwatchKey(key)
 		}
-	}
+	})
 
-	maps.Clear(s.controllables)
+	s.controllables.Clear()
 }
 
 func (s *system) Control(deltaSec float64) {
 	s.inputMgr.UpdateKeys(time.Duration(deltaSec * 1e9))
 
-	for _, c := range s.controllables {
+	for _, c := range s.controllables.Values() {
 		c.Control(deltaSec, s.inputMgr)
 	}
 }
@@ -0,0 +1,8 @@
+package drawing
+
+// DrawableGroup is implemented by something made of several drawables,
+// like a tile map with layers drawn on different drawing layers. Each
+// drawable is added with an ID made from the group ID and its index.
+type DrawableGroup interface {
+	Drawables() []Drawable
+}
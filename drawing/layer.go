@@ -41,8 +41,8 @@ func (l *Layer) Remove(id string) bool {
 		return false
 	}
 
-	slices.Delete(l.ids, idx, idx+1)
-	slices.Delete(l.drawables, idx, idx+1)
+	l.ids = slices.Delete(l.ids, idx, idx+1)
+	l.drawables = slices.Delete(l.drawables, idx, idx+1)
 
 	return true
 }
@@ -53,12 +53,13 @@ func (l *Layer) Clear() {
 	l.drawables = nil
 }
 
-// Draw draws the layer drawables in sorted order.
+// Draw draws the layer drawables in sorted order. Drawables with the
+// same sort value are drawn in the order they were added.
 func (l *Layer) Draw(screen *ebiten.Image, cam camera.Camera) {
 	n := len(l.drawables)
 	order := sliceutil.Make(n, func(i int) int { return i })
 
-	slices.SortFunc(order, func(a, b int) bool {
+	slices.SortStableFunc(order, func(a, b int) bool {
 		return l.drawables[a].DrawSortValue() < l.drawables[b].DrawSortValue()
 	})
 
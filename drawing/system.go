@@ -1,6 +1,8 @@
 package drawing
 
 import (
+	"fmt"
+
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/jamestunnell/topdown/camera"
 	"github.com/rs/zerolog/log"
@@ -23,6 +25,7 @@ type system struct {
 	layers            []*Layer
 	debugPrintables   []DebugPrintable
 	debugPrintableIDs []string
+	groupIDs          map[string][]string
 }
 
 // NewSystem makes a new overlay drawing system.
@@ -32,37 +35,31 @@ func NewSystem(cam camera.Camera) System {
 		layers:            []*Layer{},
 		debugPrintables:   []DebugPrintable{},
 		debugPrintableIDs: []string{},
+		groupIDs:          map[string][]string{},
 	}
 }
 
 // Add will add the given object as an overlay drawable if it conforms
-// to the Drawable interface.
+// to the Drawable interface. The drawables of a DrawableGroup are added
+// individually.
 func (s *system) Add(id string, x interface{}) {
-	d, ok := x.(Drawable)
-	if ok {
-		order := d.DrawLayer()
+	if g, ok := x.(DrawableGroup); ok {
+		ids := []string{}
 
-		idx := slices.IndexFunc(s.layers, func(l *Layer) bool {
-			return l.Order() == order
-		})
+		for i, d := range g.Drawables() {
+			drawableID := fmt.Sprintf("%s/%d", id, i)
 
-		if idx == -1 {
-			l := NewLayer(order)
+			s.addDrawable(drawableID, d)
 
-			s.layers = append(s.layers, l)
-
-			slices.SortFunc(s.layers, func(a, b *Layer) bool {
-				return a.Order() < b.Order()
-			})
-
-			idx = slices.IndexFunc(s.layers, func(l *Layer) bool {
-				return l.Order() == order
-			})
+			ids = append(ids, drawableID)
 		}
 
-		s.layers[idx].Add(id, d)
+		s.groupIDs[id] = ids
+	}
 
-		log.Debug().Str("id", id).Msg("added drawable")
+	d, ok := x.(Drawable)
+	if ok {
+		s.addDrawable(id, d)
 	}
 
 	dp, ok := x.(DebugPrintable)
@@ -74,19 +71,55 @@ func (s *system) Add(id string, x interface{}) {
 	}
 }
 
+func (s *system) addDrawable(id string, d Drawable) {
+	order := d.DrawLayer()
+
+	idx := slices.IndexFunc(s.layers, func(l *Layer) bool {
+		return l.Order() == order
+	})
+
+	if idx == -1 {
+		l := NewLayer(order)
+
+		s.layers = append(s.layers, l)
+
+		slices.SortFunc(s.layers, func(a, b *Layer) bool {
+			return a.Order() < b.Order()
+		})
+
+		idx = slices.IndexFunc(s.layers, func(l *Layer) bool {
+			return l.Order() == order
+		})
+	}
+
+	s.layers[idx].Add(id, d)
+
+	log.Debug().Str("id", id).Msg("added drawable")
+}
+
 // Remove will remove a drawable or debug printable with the given
 // ID if it is found.
 func (s *system) Remove(id string) {
+	for _, drawableID := range s.groupIDs[id] {
+		s.removeDrawable(drawableID)
+	}
+
+	delete(s.groupIDs, id)
+
+	s.removeDrawable(id)
+
+	if idx := slices.Index(s.debugPrintableIDs, id); idx != -1 {
+		s.debugPrintableIDs = slices.Delete(s.debugPrintableIDs, idx, idx+1)
+		s.debugPrintables = slices.Delete(s.debugPrintables, idx, idx+1)
+	}
+}
+
+func (s *system) removeDrawable(id string) {
 	for _, l := range s.layers {
 		if l.Remove(id) {
 			break
 		}
 	}
-
-	if idx := slices.Index(s.debugPrintableIDs, id); idx != -1 {
-		slices.Delete(s.debugPrintableIDs, idx, idx+1)
-		slices.Delete(s.debugPrintables, idx, idx+1)
-	}
 }
 
 // Clear will remove all drawables.
@@ -97,6 +130,7 @@ func (s *system) Clear() {
 
 	s.debugPrintableIDs = []string{}
 	s.debugPrintables = []DebugPrintable{}
+	s.groupIDs = map[string][]string{}
 }
 
 // Draw will draw all drawables by layer order.
@@ -3,9 +3,12 @@ package engine
 import (
 	"fmt"
 
+	"github.com/jamestunnell/topdown/ldtk"
+	"github.com/jamestunnell/topdown/procgen"
 	"github.com/jamestunnell/topdown/registry"
 	"github.com/jamestunnell/topdown/resource"
 	"github.com/jamestunnell/topdown/sprite"
+	"github.com/jamestunnell/topdown/tiled"
 	"github.com/jamestunnell/topdown/tilegrid"
 )
 
@@ -19,6 +22,36 @@ func SetupTypes(reg registry.Registry[resource.Type], extraTypes ...resource.Typ
 
 	reg.Add(bgType)
 
+	mapType, err := tilegrid.NewMapType()
+	if err != nil {
+		return fmt.Errorf("failed to make map type: %w", err)
+	}
+
+	reg.Add(mapType)
+
+	autoTileType, err := tilegrid.NewAutoTileType()
+	if err != nil {
+		return fmt.Errorf("failed to make autotile type: %w", err)
+	}
+
+	reg.Add(autoTileType)
+
+	chunkedMapType, err := tilegrid.NewChunkedMapType()
+	if err != nil {
+		return fmt.Errorf("failed to make chunked map type: %w", err)
+	}
+
+	reg.Add(chunkedMapType)
+
+	procgenType, err := procgen.NewMapType()
+	if err != nil {
+		return fmt.Errorf("failed to make procgen type: %w", err)
+	}
+
+	reg.Add(procgenType)
+	reg.Add(tiled.Types()...)
+	reg.Add(ldtk.NewProjectType())
+
 	for _, t := range extraTypes {
 		reg.Add(t)
 	}
@@ -6,22 +6,48 @@ import (
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/rs/zerolog/log"
+	"github.com/xeipuuv/gojsonschema"
 	"github.com/zergon321/cirno"
 
 	"github.com/jamestunnell/topdown"
 	"github.com/jamestunnell/topdown/animation"
 	"github.com/jamestunnell/topdown/camera"
 	"github.com/jamestunnell/topdown/drawing"
+	"github.com/jamestunnell/topdown/movecollide"
 	"github.com/jamestunnell/topdown/resource"
 )
 
-type Character struct {
-	Animations   *animation.Animations `json:"animations"`
-	ColliderSize topdown.Size[float64] `jons:"colliderSize"`
-	Position     topdown.Vector        `json:"position"`
+// CharacterSchemaStr is the JSON schema for the character parts of
+// players and non-players.
+const CharacterSchemaStr = `{
+  "$id": "https://github.com/jamestunnell/topdown/examples/adventure/character.json",
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "Character",
+  "description": "A player or non-player character.",
+  "type": "object",
+  "required": ["animations", "position", "collider"],
+  "properties": {
+    "animations": { "$ref": "https://github.com/jamestunnell/topdown/animations.json" },
+    "collider": { "$ref": "https://github.com/jamestunnell/topdown/collider.json" },
+    "position": { "$ref": "https://github.com/jamestunnell/topdown/vector.json" },
+    "layer": { "$ref": "https://github.com/jamestunnell/topdown/layer.json" },
+    "collidesWith": { "$ref": "https://github.com/jamestunnell/topdown/layer.json" },
+    "collisionResponse": { "$ref": "https://github.com/jamestunnell/topdown/resolver.json" }
+  }
+}`
 
-	Collider  cirno.Shape
+type Character struct {
+	Animations   *animation.Animations     `json:"animations"`
+	ColliderSpec *movecollide.ColliderSpec `json:"collider"`
+	Position     topdown.Vector            `json:"position"`
+	Layer        movecollide.Layer         `json:"layer"`
+	CollidesWith movecollide.Layer         `json:"collidesWith"`
+	// CollisionResponse picks the collision resolver. Slide is used by default.
+	CollisionResponse *movecollide.ResolverSpec `json:"collisionResponse"`
+
+	Collider  cirno.Shape `json:"-"`
+	Resolver  movecollide.Resolver
+	Surface   *movecollide.Surface
 	Direction topdown.Vector
 	Velocity  topdown.Vector
 }
@@ -35,15 +61,27 @@ func (ch *Character) Initialize(mgr resource.Manager) error {
 		return errors.New("failed to start idle animation")
 	}
 
-	cCenter := cirno.NewVector(ch.Position.X, ch.Position.Y)
-	colliderRect, err := cirno.NewRectangle(
-		cCenter, ch.ColliderSize.Width, ch.ColliderSize.Height, 0)
+	if ch.ColliderSpec == nil {
+		return errors.New("collider is missing")
+	}
 
+	collider, err := movecollide.NewCollider(ch.ColliderSpec, ch.Position)
 	if err != nil {
-		return fmt.Errorf("failed to make collider rect: %w", err)
+		return fmt.Errorf("failed to make collider: %w", err)
+	}
+
+	ch.Collider = collider
+
+	if ch.CollisionResponse == nil {
+		ch.CollisionResponse = &movecollide.ResolverSpec{Type: movecollide.ResolverTypeSlide}
 	}
 
-	ch.Collider = colliderRect
+	resolver, err := movecollide.NewResolver(ch.CollisionResponse)
+	if err != nil {
+		return fmt.Errorf("failed to make collision resolver: %w", err)
+	}
+
+	ch.Resolver = resolver
 	ch.Velocity = topdown.Vector{}
 	ch.Direction = topdown.Vec(0, 1)
 
@@ -97,27 +135,49 @@ func (ch *Character) ColliderShape() cirno.Shape {
 	return ch.Collider
 }
 
-func (ch *Character) ResolveCollision(moveDiff cirno.Vector, shapes cirno.Shapes) cirno.Vector {
-	// shapeIDs := []string{}
-	// for s := range shapes {
-	// 	shapeIDs = append(shapeIDs, s.Data().(string))
-	// }
-
-	// log.Debug().Str("shapeIDs", strings.Join(shapeIDs, ",")).Msg("resolving collision")
+func (ch *Character) CollisionLayer() movecollide.Layer {
+	if ch.Layer == movecollide.LayerNone {
+		return movecollide.LayerDefault
+	}
 
-	// most basic collision resolution: back up to where there is no collision
-	newPos, _, _, err := cirno.Approximate(ch.Collider, moveDiff, 0, shapes, 100, false)
-	if err != nil {
-		log.Warn().Err(err).Msg("failed to resolve collision")
+	return ch.Layer
+}
 
-		return cirno.Zero()
+func (ch *Character) CollisionMask() movecollide.Layer {
+	if ch.CollidesWith == movecollide.LayerNone {
+		return movecollide.LayerAll
 	}
 
-	pos := cirno.NewVector(ch.Position.X, ch.Position.Y)
+	return ch.CollidesWith
+}
+
+func (ch *Character) CollisionResolver() movecollide.Resolver {
+	return ch.Resolver
+}
 
-	return newPos.Subtract(pos)
+// SetSurface is told about the surface under the character each tick.
+func (ch *Character) SetSurface(s *movecollide.Surface) {
+	ch.Surface = s
 }
 
 func (ch *Character) maxY() float64 {
-	return ch.Position.Y + ch.ColliderSize.Height/2.0
+	_, max := movecollide.ShapeBounds(ch.Collider)
+
+	return max.Y
+}
+
+func makeCharacterSchema() (*gojsonschema.Schema, error) {
+	schema, err := resource.MakeJSONSchema(
+		CharacterSchemaStr,
+		animation.SchemaStr,
+		movecollide.ColliderSchemaStr,
+		movecollide.LayerSchemaStr,
+		movecollide.ResolverSchemaStr,
+		topdown.VectorSchemaStr,
+		topdown.SizeSchemaStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make JSON schema: %w", err)
+	}
+
+	return schema, nil
 }
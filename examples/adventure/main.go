@@ -20,9 +20,19 @@ func main() {
 		PlayerRef: "adventurer.player",
 		WorldRef:  "adventure.world",
 	}
+	playerType, err := NewPlayerType()
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to make player type")
+	}
+
+	nonPlayerType, err := NewNonPlayerType()
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to make non-player type")
+	}
+
 	types := []resource.Type{
-		&PlayerType{},
-		&NonPlayerType{},
+		playerType,
+		nonPlayerType,
 		&WorldType{},
 	}
 	cfg := &engine.Config{
@@ -1,11 +1,23 @@
 package main
 
 import (
+	"github.com/xeipuuv/gojsonschema"
+
 	"github.com/jamestunnell/topdown/jsonfile"
 	"github.com/jamestunnell/topdown/resource"
 )
 
 type NonPlayerType struct {
+	schema *gojsonschema.Schema
+}
+
+func NewNonPlayerType() (resource.Type, error) {
+	schema, err := makeCharacterSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	return &NonPlayerType{schema: schema}, nil
 }
 
 type NonPlayer struct {
@@ -17,5 +29,5 @@ func (t *NonPlayerType) Name() string {
 }
 
 func (t *NonPlayerType) Load(path string) (resource.Resource, error) {
-	return jsonfile.Read[*NonPlayer](path)
+	return jsonfile.ReadAndValidate[*NonPlayer](path, t.schema)
 }
@@ -63,6 +63,8 @@ func (p *Play) Initialize(screenSize topdown.Size[int], mgr resource.Manager) er
 	p.animation = animation.NewSystem()
 	p.screenSize = screenSize
 
+	// added in a fixed order, which is the order the systems process them in
+	ids := []string{"camera", "player", "world"}
 	objs := map[string]any{
 		"camera": cam,
 		"player": p.player,
@@ -70,10 +72,13 @@ func (p *Play) Initialize(screenSize topdown.Size[int], mgr resource.Manager) er
 	}
 
 	for i, npc := range p.world.NPCs {
+		ids = append(ids, p.world.NPCRefs[i])
 		objs[p.world.NPCRefs[i]] = npc
 	}
 
-	for id, obj := range objs {
+	for _, id := range ids {
+		obj := objs[id]
+
 		p.animation.Add(id, obj)
 		p.control.Add(id, obj)
 		p.drawing.Add(id, obj)
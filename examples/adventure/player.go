@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/xeipuuv/gojsonschema"
 
 	"github.com/jamestunnell/topdown"
 	"github.com/jamestunnell/topdown/debug"
@@ -14,6 +15,16 @@ import (
 )
 
 type PlayerType struct {
+	schema *gojsonschema.Schema
+}
+
+func NewPlayerType() (resource.Type, error) {
+	schema, err := makeCharacterSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	return &PlayerType{schema: schema}, nil
 }
 
 type Player struct {
@@ -32,7 +43,7 @@ func (t *PlayerType) Name() string {
 }
 
 func (t *PlayerType) Load(path string) (resource.Resource, error) {
-	return jsonfile.Read[*Player](path)
+	return jsonfile.ReadAndValidate[*Player](path, t.schema)
 }
 
 func (p *Player) Initialize(mgr resource.Manager) error {
@@ -54,7 +65,7 @@ func (p *Player) Control(deltaSec float64, inputMgr input.Manager) {
 }
 
 func (p *Player) PlanMovement(deltaSec float64) topdown.Vector {
-	return p.Velocity.Multiply(deltaSec)
+	return p.Velocity.Multiply(deltaSec * p.Surface.SpeedFactor())
 }
 
 func (p *Player) Move(moveDiff topdown.Vector) {
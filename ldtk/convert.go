@@ -0,0 +1,383 @@
+package ldtk
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/jsonfile"
+	"github.com/jamestunnell/topdown/movecollide"
+	"github.com/jamestunnell/topdown/sprite"
+	"github.com/jamestunnell/topdown/tilegrid"
+)
+
+// IntGrid value identifiers that make solid tiles. Other named values make
+// tiles with a surface named after the value.
+const (
+	IntGridSolid = "solid"
+	IntGridWall  = "wall"
+)
+
+// Tile flip bits.
+const (
+	FlipX = 1 << 0
+	FlipY = 1 << 1
+)
+
+// converter makes a project from an LDtk project.
+type converter struct {
+	dir        string
+	project    *Project
+	intGridIDs map[int]map[int]string
+	tilesets   map[int]*tileset
+}
+
+// levelConverter makes a level tile map.
+type levelConverter struct {
+	*converter
+
+	tileMap    *tilegrid.TileMap
+	layerNames map[string]bool
+}
+
+func newProject(path string, p *jsonProject) (*Project, error) {
+	c := &converter{
+		dir: filepath.Dir(path),
+		project: &Project{
+			Levels:   make([]*Level, len(p.Levels)),
+			Surfaces: map[string]*movecollide.Surface{},
+		},
+		intGridIDs: map[int]map[int]string{},
+		tilesets:   map[int]*tileset{},
+	}
+
+	for _, def := range p.Defs.Layers {
+		ids := map[int]string{}
+
+		for _, val := range def.IntGridValues {
+			ids[val.Value] = val.Identifier
+
+			if val.Identifier != "" && !solid(val.Identifier) {
+				c.project.Surfaces[val.Identifier] = &movecollide.Surface{Name: val.Identifier}
+			}
+		}
+
+		c.intGridIDs[def.UID] = ids
+	}
+
+	for _, def := range p.Defs.Tilesets {
+		// tilesets without an image, like the built-in icons, can't be used
+		if def.RelPath == "" {
+			continue
+		}
+
+		if def.TileGridSize <= 0 || def.Columns <= 0 {
+			return nil, fmt.Errorf("tileset '%s' has an invalid tile size or column count", def.Identifier)
+		}
+
+		ts := &tileset{
+			Identifier: def.Identifier,
+			Image:      filepath.Join(c.dir, def.RelPath),
+			TileSize:   def.TileGridSize,
+			Count:      def.Columns * def.Rows,
+			Columns:    def.Columns,
+			Padding:    def.Padding,
+			Spacing:    def.Spacing,
+			ref:        fmt.Sprintf("%s.%s", path, def.Identifier),
+		}
+
+		c.tilesets[def.UID] = ts
+		c.project.tilesets = append(c.project.tilesets, ts)
+	}
+
+	// linear layouts are placed end to end, instead of by world position
+	offset := 0
+
+	for i, l := range p.Levels {
+		if l.LayerInstances == nil && l.ExternalRelPath != "" {
+			external, err := jsonfile.Read[*jsonLevel](filepath.Join(c.dir, l.ExternalRelPath))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read level '%s': %w", l.Identifier, err)
+			}
+
+			l = external
+		}
+
+		origin := topdown.Pt(float64(l.WorldX), float64(l.WorldY))
+
+		switch p.WorldLayout {
+		case WorldLayoutLinearHorizontal:
+			origin = topdown.Pt(float64(offset), 0)
+			offset += l.PxWid
+		case WorldLayoutLinearVertical:
+			origin = topdown.Pt(0, float64(offset))
+			offset += l.PxHei
+		}
+
+		level, err := c.convertLevel(l, origin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert level '%s': %w", l.Identifier, err)
+		}
+
+		c.project.Levels[i] = level
+	}
+
+	return c.project, nil
+}
+
+func solid(intGridID string) bool {
+	return intGridID == IntGridSolid || intGridID == IntGridWall
+}
+
+func (c *converter) convertLevel(l *jsonLevel, origin topdown.Point[float64]) (*Level, error) {
+	lc := &levelConverter{
+		converter: c,
+		tileMap: &tilegrid.TileMap{
+			Origin:     origin,
+			TileLinks:  map[string]*tilegrid.TileLink{},
+			EmptyTile:  tilegrid.DefaultEmptyTile,
+			Layers:     []*tilegrid.TileLayer{},
+			Spawns:     []*tilegrid.SpawnPoint{},
+			Triggers:   []*tilegrid.Area{},
+			Colliders:  []*tilegrid.Area{},
+			Properties: convertFields(l.FieldInstances),
+		},
+		layerNames: map[string]bool{},
+	}
+
+	// bottom layer first
+	for i := len(l.LayerInstances) - 1; i >= 0; i-- {
+		layer := l.LayerInstances[i]
+
+		var err error
+
+		switch layer.Type {
+		case LayerTypeEntities:
+			lc.addEntities(layer)
+		case LayerTypeIntGrid:
+			if err = lc.addIntGrid(layer); err == nil {
+				err = lc.addTiles(layer, layer.Identifier+"/tiles", layer.AutoLayerTiles)
+			}
+		case LayerTypeAutoLayer:
+			err = lc.addTiles(layer, layer.Identifier, layer.AutoLayerTiles)
+		case LayerTypeTiles:
+			err = lc.addTiles(layer, layer.Identifier, layer.GridTiles)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to add layer '%s': %w", layer.Identifier, err)
+		}
+	}
+
+	return &Level{
+		TileMap:    lc.tileMap,
+		Identifier: l.Identifier,
+		IID:        l.IID,
+		WorldArea: topdown.Rectangle[float64]{
+			Min: origin,
+			Max: origin.Add(topdown.Pt(float64(l.PxWid), float64(l.PxHei))),
+		},
+		WorldDepth: l.WorldDepth,
+	}, nil
+}
+
+// setGridSize sets the map tile size to the layer grid size, which must
+// be the same for all the layers with tiles. Layers with tiles can't be
+// offset, since their tiles are placed on the map grid.
+func (lc *levelConverter) setGridSize(layer *jsonLayer) error {
+	size := topdown.Sz(layer.GridSize, layer.GridSize)
+
+	switch {
+	case layer.PxTotalOffsetX != 0 || layer.PxTotalOffsetY != 0:
+		return fmt.Errorf("layer offset %d,%d is not supported",
+			layer.PxTotalOffsetX, layer.PxTotalOffsetY)
+	case layer.GridSize <= 0:
+		return fmt.Errorf("invalid grid size %d", layer.GridSize)
+	case lc.tileMap.TileSize == topdown.Size[int]{}:
+		lc.tileMap.TileSize = size
+	case lc.tileMap.TileSize != size:
+		return fmt.Errorf("grid size %d differs from other layers", layer.GridSize)
+	}
+
+	return nil
+}
+
+func (lc *levelConverter) addIntGrid(layer *jsonLayer) error {
+	if err := lc.setGridSize(layer); err != nil {
+		return err
+	}
+
+	if len(layer.IntGridCSV) != layer.CWid*layer.CHei {
+		return fmt.Errorf("layer has %d cells instead of %dx%d", len(layer.IntGridCSV), layer.CWid, layer.CHei)
+	}
+
+	rows := emptyRows(layer.CWid, layer.CHei)
+
+	for i, val := range layer.IntGridCSV {
+		if val == 0 {
+			continue
+		}
+
+		// tile IDs are like "Collisions=1"
+		tileID := fmt.Sprintf("%s=%d", layer.Identifier, val)
+
+		if _, found := lc.tileMap.TileLinks[tileID]; !found {
+			intGridID := lc.intGridIDs[layer.LayerDefUID][val]
+
+			lc.tileMap.TileLinks[tileID] = &tilegrid.TileLink{
+				Solid:   solid(intGridID),
+				Surface: lc.project.Surfaces[intGridID],
+			}
+		}
+
+		rows[i/layer.CWid][i%layer.CWid] = tileID
+	}
+
+	lc.addLayer(layer, layer.Identifier, rows)
+
+	return nil
+}
+
+// addTiles adds layers with the tiles. Tiles that are stacked in the same
+// cell go in extra layers, with the top-most tiles in the last layer.
+func (lc *levelConverter) addTiles(layer *jsonLayer, name string, tiles []*jsonTile) error {
+	if len(tiles) == 0 {
+		return nil
+	}
+
+	if err := lc.setGridSize(layer); err != nil {
+		return err
+	}
+
+	if layer.TilesetDefUID == nil {
+		return fmt.Errorf("layer has no tileset")
+	}
+
+	ts, found := lc.tilesets[*layer.TilesetDefUID]
+	if !found {
+		return fmt.Errorf("tileset %d not found", *layer.TilesetDefUID)
+	}
+
+	stacks := [][][]string{}
+
+	for _, tile := range tiles {
+		col, row := tile.Px[0]/layer.GridSize, tile.Px[1]/layer.GridSize
+		if col < 0 || col >= layer.CWid || row < 0 || row >= layer.CHei {
+			return fmt.Errorf("tile at %d,%d is outside the layer", tile.Px[0], tile.Px[1])
+		}
+
+		if tile.T < 0 || tile.T >= ts.Count {
+			return fmt.Errorf("tileset '%s' has no tile %d", ts.Identifier, tile.T)
+		}
+
+		stack := 0
+		for stack < len(stacks) && stacks[stack][row][col] != tilegrid.DefaultEmptyTile {
+			stack++
+		}
+
+		if stack == len(stacks) {
+			stacks = append(stacks, emptyRows(layer.CWid, layer.CHei))
+		}
+
+		stacks[stack][row][col] = lc.tileID(ts, tile)
+	}
+
+	for i, rows := range stacks {
+		stackName := name
+		if i > 0 {
+			stackName = fmt.Sprintf("%s %d", name, i+1)
+		}
+
+		lc.addLayer(layer, stackName, rows)
+	}
+
+	return nil
+}
+
+// tileID gets the tile ID for a tile, linking the tile the first time.
+// Tile IDs are like "Dungeon.12", followed by h and v for the horizontal
+// and vertical flips.
+func (lc *levelConverter) tileID(ts *tileset, tile *jsonTile) string {
+	tileID := fmt.Sprintf("%s.%d", ts.Identifier, tile.T)
+
+	if tile.F&FlipX != 0 {
+		tileID += "h"
+	}
+
+	if tile.F&FlipY != 0 {
+		tileID += "v"
+	}
+
+	if _, found := lc.tileMap.TileLinks[tileID]; !found {
+		l := &sprite.IDLink{SpriteSheetRef: ts.ref, SpriteID: strconv.Itoa(tile.T)}
+
+		lc.tileMap.TileLinks[tileID] = &tilegrid.TileLink{
+			Sprite: l.String(),
+			FlipX:  tile.F&FlipX != 0,
+			FlipY:  tile.F&FlipY != 0,
+		}
+	}
+
+	return tileID
+}
+
+func (lc *levelConverter) addLayer(layer *jsonLayer, name string, rows [][]string) {
+	tileRows := make([]string, len(rows))
+
+	for i, row := range rows {
+		tileRows[i] = strings.Join(row, tilegrid.RefIDSeparator)
+	}
+
+	unique := name
+	for i := 2; lc.layerNames[unique]; i++ {
+		unique = fmt.Sprintf("%s %d", name, i)
+	}
+
+	lc.layerNames[unique] = true
+
+	tl := &tilegrid.TileLayer{
+		Name:     unique,
+		Hidden:   !layer.Visible,
+		TileRows: tileRows,
+	}
+
+	if layer.Opacity != 1 {
+		opacity := layer.Opacity
+
+		tl.Opacity = &opacity
+	}
+
+	lc.tileMap.Layers = append(lc.tileMap.Layers, tl)
+}
+
+// addEntities adds a spawn point for each entity, at the entity pivot.
+func (lc *levelConverter) addEntities(layer *jsonLayer) {
+	for _, e := range layer.EntityInstances {
+		pos := topdown.Vec(
+			lc.tileMap.Origin.X+float64(e.Px[0]+layer.PxTotalOffsetX),
+			lc.tileMap.Origin.Y+float64(e.Px[1]+layer.PxTotalOffsetY))
+
+		lc.tileMap.Spawns = append(lc.tileMap.Spawns, &tilegrid.SpawnPoint{
+			Name:       e.IID,
+			Type:       e.Identifier,
+			Position:   pos,
+			Properties: convertFields(e.FieldInstances),
+		})
+	}
+}
+
+func emptyRows(nCols, nRows int) [][]string {
+	rows := make([][]string, nRows)
+
+	for i := range rows {
+		rows[i] = make([]string, nCols)
+
+		for j := range rows[i] {
+			rows[i][j] = tilegrid.DefaultEmptyTile
+		}
+	}
+
+	return rows
+}
@@ -0,0 +1,51 @@
+package ldtk
+
+import (
+	"github.com/jamestunnell/topdown/tilegrid"
+)
+
+const (
+	FieldTypeInt      = "Int"
+	FieldTypeIntArray = "Array<Int>"
+)
+
+// convertFields makes properties from field values. Ints become int
+// instead of float64, and the other values are kept as they are.
+func convertFields(fields []*jsonField) tilegrid.Properties {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	props := tilegrid.Properties{}
+
+	for _, f := range fields {
+		val := f.Value
+
+		switch f.Type {
+		case FieldTypeInt:
+			val = toInt(val)
+		case FieldTypeIntArray:
+			if vals, ok := val.([]any); ok {
+				ints := make([]any, len(vals))
+
+				for i, v := range vals {
+					ints[i] = toInt(v)
+				}
+
+				val = ints
+			}
+		}
+
+		props[f.Identifier] = val
+	}
+
+	return props
+}
+
+func toInt(val any) any {
+	if f, ok := val.(float64); ok {
+		return int(f)
+	}
+
+	return val
+}
@@ -0,0 +1,103 @@
+package ldtk
+
+// The parts of the LDtk JSON format that are imported.
+
+const (
+	LayerTypeIntGrid   = "IntGrid"
+	LayerTypeEntities  = "Entities"
+	LayerTypeTiles     = "Tiles"
+	LayerTypeAutoLayer = "AutoLayer"
+
+	WorldLayoutFree             = "Free"
+	WorldLayoutGridVania        = "GridVania"
+	WorldLayoutLinearHorizontal = "LinearHorizontal"
+	WorldLayoutLinearVertical   = "LinearVertical"
+)
+
+type jsonProject struct {
+	WorldLayout string       `json:"worldLayout"`
+	Defs        jsonDefs     `json:"defs"`
+	Levels      []*jsonLevel `json:"levels"`
+}
+
+type jsonDefs struct {
+	Layers   []*jsonLayerDef   `json:"layers"`
+	Tilesets []*jsonTilesetDef `json:"tilesets"`
+}
+
+type jsonLayerDef struct {
+	UID           int                 `json:"uid"`
+	Identifier    string              `json:"identifier"`
+	Type          string              `json:"type"`
+	IntGridValues []*jsonIntGridValue `json:"intGridValues"`
+}
+
+type jsonIntGridValue struct {
+	Value      int    `json:"value"`
+	Identifier string `json:"identifier"`
+}
+
+type jsonTilesetDef struct {
+	UID          int    `json:"uid"`
+	Identifier   string `json:"identifier"`
+	RelPath      string `json:"relPath"`
+	TileGridSize int    `json:"tileGridSize"`
+	Spacing      int    `json:"spacing"`
+	Padding      int    `json:"padding"`
+	Columns      int    `json:"__cWid"`
+	Rows         int    `json:"__cHei"`
+}
+
+type jsonLevel struct {
+	Identifier      string       `json:"identifier"`
+	IID             string       `json:"iid"`
+	WorldX          int          `json:"worldX"`
+	WorldY          int          `json:"worldY"`
+	WorldDepth      int          `json:"worldDepth"`
+	PxWid           int          `json:"pxWid"`
+	PxHei           int          `json:"pxHei"`
+	FieldInstances  []*jsonField `json:"fieldInstances"`
+	ExternalRelPath string       `json:"externalRelPath"`
+	// LayerInstances are ordered from top to bottom, and are missing when
+	// the level is saved in a separate file.
+	LayerInstances []*jsonLayer `json:"layerInstances"`
+}
+
+type jsonLayer struct {
+	Identifier      string        `json:"__identifier"`
+	Type            string        `json:"__type"`
+	CWid            int           `json:"__cWid"`
+	CHei            int           `json:"__cHei"`
+	GridSize        int           `json:"__gridSize"`
+	Opacity         float64       `json:"__opacity"`
+	PxTotalOffsetX  int           `json:"__pxTotalOffsetX"`
+	PxTotalOffsetY  int           `json:"__pxTotalOffsetY"`
+	TilesetDefUID   *int          `json:"__tilesetDefUid"`
+	LayerDefUID     int           `json:"layerDefUid"`
+	Visible         bool          `json:"visible"`
+	IntGridCSV      []int         `json:"intGridCsv"`
+	AutoLayerTiles  []*jsonTile   `json:"autoLayerTiles"`
+	GridTiles       []*jsonTile   `json:"gridTiles"`
+	EntityInstances []*jsonEntity `json:"entityInstances"`
+}
+
+// jsonTile is a tile placed in a layer, with the tile pixel position and
+// flip bits.
+type jsonTile struct {
+	Px [2]int `json:"px"`
+	F  int    `json:"f"`
+	T  int    `json:"t"`
+}
+
+type jsonEntity struct {
+	Identifier     string       `json:"__identifier"`
+	IID            string       `json:"iid"`
+	Px             [2]int       `json:"px"`
+	FieldInstances []*jsonField `json:"fieldInstances"`
+}
+
+type jsonField struct {
+	Identifier string `json:"__identifier"`
+	Type       string `json:"__type"`
+	Value      any    `json:"__value"`
+}
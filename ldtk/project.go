@@ -0,0 +1,100 @@
+package ldtk
+
+import (
+	"fmt"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/movecollide"
+	"github.com/jamestunnell/topdown/resource"
+	"github.com/jamestunnell/topdown/sprite"
+	"github.com/jamestunnell/topdown/tilegrid"
+)
+
+// Project is an LDtk project, with a tile map for each level. Tilesets
+// become sprite sheets when the project is initialized.
+type Project struct {
+	Levels []*Level
+	// Surfaces has the surfaces made from IntGrid values, by name. They are
+	// shared by all the levels, so they can be tuned after loading.
+	Surfaces map[string]*movecollide.Surface
+
+	tilesets []*tileset
+}
+
+// Level is a tile map placed at the level position in the world. IntGrid
+// layers become layers of tiles without sprites, which are solid or have
+// a surface. Tile layers and auto-layers become layers of tiles, and
+// entities become spawn points with their fields as properties.
+type Level struct {
+	*tilegrid.TileMap
+
+	Identifier string
+	IID        string
+	// WorldArea is the area covered by the level in the world.
+	WorldArea topdown.Rectangle[float64]
+	// WorldDepth separates levels in the same area, like building floors.
+	WorldDepth int
+}
+
+type tileset struct {
+	Identifier string
+	Image      string
+	TileSize   int
+	Count      int
+	Columns    int
+	Padding    int
+	Spacing    int
+
+	// ref is unique to the tileset, for naming its sprite sheet
+	ref string
+}
+
+// Initialize adds a sprite sheet for each tileset to the resource manager,
+// then initializes the levels.
+func (p *Project) Initialize(mgr resource.Manager) error {
+	for _, ts := range p.tilesets {
+		if err := ts.addSheet(mgr); err != nil {
+			return fmt.Errorf("failed to add sprite sheet for tileset '%s': %w", ts.Identifier, err)
+		}
+	}
+
+	for _, l := range p.Levels {
+		if err := l.Initialize(mgr); err != nil {
+			return fmt.Errorf("failed to initialize level '%s': %w", l.Identifier, err)
+		}
+	}
+
+	return nil
+}
+
+// Level gets a level by identifier.
+func (p *Project) Level(identifier string) (*Level, bool) {
+	for _, l := range p.Levels {
+		if l.Identifier == identifier {
+			return l, true
+		}
+	}
+
+	return nil, false
+}
+
+func (ts *tileset) addSheet(mgr resource.Manager) error {
+	img, err := sprite.LoadImage(ts.Image)
+	if err != nil {
+		return fmt.Errorf("failed to load image '%s': %w", ts.Image, err)
+	}
+
+	mgr.Add(ts.Image, img)
+
+	sprites := sprite.GridSprites(
+		topdown.Sz(ts.TileSize, ts.TileSize), ts.Count, ts.Columns, ts.Padding, ts.Spacing)
+	sheet := sprite.NewSheet(ts.Image, sprites...)
+
+	if err = sheet.Initialize(mgr); err != nil {
+		return err
+	}
+
+	mgr.Add(ts.ref, sheet)
+
+	return nil
+}
@@ -0,0 +1,169 @@
+package ldtk_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/ldtk"
+	"github.com/jamestunnell/topdown/movecollide"
+	"github.com/jamestunnell/topdown/tilegrid"
+)
+
+func TestLoadProject(t *testing.T) {
+	p := loadProject(t, filepath.Join("testdata", "world.ldtk"))
+
+	require.Len(t, p.Levels, 2)
+
+	water, found := p.Surfaces["water"]
+
+	require.True(t, found)
+	assert.Equal(t, &movecollide.Surface{Name: "water"}, water)
+	assert.NotContains(t, p.Surfaces, "wall")
+
+	start, found := p.Level("Start")
+
+	require.True(t, found)
+	assert.Equal(t, "start-iid", start.IID)
+	assert.Equal(t, topdown.Rect(0.0, 0.0, 24.0, 16.0), start.WorldArea)
+	assert.Equal(t, topdown.Pt(0.0, 0.0), start.Origin)
+	assert.Equal(t, topdown.Sz(8, 8), start.TileSize)
+	assert.Equal(t, tilegrid.Properties{"music": "cave"}, start.Properties)
+
+	// bottom first, with stacked tiles in extra layers
+	layerNames := []string{}
+	for _, l := range start.Layers {
+		layerNames = append(layerNames, l.Name)
+	}
+
+	assert.Equal(t, []string{"Ground", "Ground 2", "Walls", "Walls/tiles"}, layerNames)
+
+	ground, walls, wallTiles := start.Layers[0], start.Layers[2], start.Layers[3]
+
+	assert.Equal(t, []string{"Dungeon.0 Dungeon.1h -", "- - Dungeon.7v"}, ground.TileRows)
+	assert.Equal(t, []string{"- Dungeon.2hv -", "- - -"}, start.Layers[1].TileRows)
+	require.NotNil(t, ground.Opacity)
+	assert.Equal(t, 0.5, *ground.Opacity)
+	assert.Equal(t, []string{"Walls=1 - Walls=2", "Walls=1 Walls=3 -"}, walls.TileRows)
+	assert.Equal(t, []string{"Dungeon.5 - -", "- - -"}, wallTiles.TileRows)
+
+	assert.Equal(t, &tilegrid.TileLink{Solid: true}, start.TileLinks["Walls=1"])
+	assert.Same(t, water, start.TileLinks["Walls=2"].Surface)
+	assert.Equal(t, &tilegrid.TileLink{}, start.TileLinks["Walls=3"])
+
+	link := start.TileLinks["Dungeon.2hv"]
+
+	require.NotNil(t, link)
+	assert.Regexp(t, `world\.ldtk\.Dungeon#2$`, link.Sprite)
+	assert.True(t, link.FlipX)
+	assert.True(t, link.FlipY)
+
+	require.Len(t, start.Spawns, 1)
+	assert.Equal(t, &tilegrid.SpawnPoint{
+		Name:     "player-iid",
+		Type:     "Player",
+		Position: topdown.Vec(4, 12),
+		Properties: tilegrid.Properties{
+			"hp":   3,
+			"keys": []any{1, 2},
+		},
+	}, start.Spawns[0])
+
+	// read from a separate level file
+	next, found := p.Level("Next")
+
+	require.True(t, found)
+	assert.Equal(t, topdown.Rect(24.0, 0.0, 32.0, 8.0), next.WorldArea)
+	require.Len(t, next.Layers, 1)
+	assert.Equal(t, []string{"Walls=1"}, next.Layers[0].TileRows)
+	require.Len(t, next.Spawns, 1)
+	assert.Equal(t, topdown.Vec(24, 0), next.Spawns[0].Position)
+
+	_, found = p.Level("Missing")
+
+	assert.False(t, found)
+}
+
+func TestLoadProjectLinearLayout(t *testing.T) {
+	path := writeProject(t, `{
+		"worldLayout": "LinearHorizontal",
+		"levels": [
+			{"identifier": "A", "worldX": -1, "worldY": -1, "pxWid": 16, "pxHei": 8, "layerInstances": [`+wallsLayer+`]},
+			{"identifier": "B", "worldX": -1, "worldY": -1, "pxWid": 32, "pxHei": 8, "layerInstances": [`+wallsLayer+`]}
+		]
+	}`)
+	p := loadProject(t, path)
+
+	require.Len(t, p.Levels, 2)
+	assert.Equal(t, topdown.Rect(0.0, 0.0, 16.0, 8.0), p.Levels[0].WorldArea)
+	assert.Equal(t, topdown.Rect(16.0, 0.0, 48.0, 8.0), p.Levels[1].WorldArea)
+}
+
+func TestLoadProjectInvalid(t *testing.T) {
+	projects := map[string]string{
+		"missing level file": `{"levels": [{"identifier": "A", "externalRelPath": "missing.ldtkl"}]}`,
+		"cell count": `{"levels": [{"identifier": "A", "layerInstances": [
+			{"__identifier": "Walls", "__type": "IntGrid", "__cWid": 2, "__cHei": 1, "__gridSize": 8, "intGridCsv": [1]}
+		]}]}`,
+		"grid sizes": `{"levels": [{"identifier": "A", "layerInstances": [` + wallsLayer + `,
+			{"__identifier": "Other", "__type": "IntGrid", "__cWid": 1, "__cHei": 1, "__gridSize": 16, "intGridCsv": [1]}
+		]}]}`,
+		"unknown tileset": `{"levels": [{"identifier": "A", "layerInstances": [
+			{"__identifier": "Ground", "__type": "Tiles", "__cWid": 1, "__cHei": 1, "__gridSize": 8,
+				"__tilesetDefUid": 5, "gridTiles": [{"px": [0, 0], "t": 0}]}
+		]}]}`,
+		"offset int grid": `{"levels": [{"identifier": "A", "layerInstances": [
+			{"__identifier": "Walls", "__type": "IntGrid", "__cWid": 1, "__cHei": 1, "__gridSize": 8,
+				"__pxTotalOffsetX": 4, "intGridCsv": [1]}
+		]}]}`,
+		"offset tiles": `{
+			"defs": {"tilesets": [{"identifier": "T", "uid": 5, "relPath": "t.png", "tileGridSize": 8, "__cWid": 1, "__cHei": 1}]},
+			"levels": [{"identifier": "A", "layerInstances": [
+				{"__identifier": "Ground", "__type": "Tiles", "__cWid": 1, "__cHei": 1, "__gridSize": 8,
+					"__pxTotalOffsetY": -8, "__tilesetDefUid": 5, "gridTiles": [{"px": [0, 0], "t": 0}]}
+			]}]
+		}`,
+		"tile outside": `{
+			"defs": {"tilesets": [{"identifier": "T", "uid": 5, "relPath": "t.png", "tileGridSize": 8, "__cWid": 1, "__cHei": 1}]},
+			"levels": [{"identifier": "A", "layerInstances": [
+				{"__identifier": "Ground", "__type": "Tiles", "__cWid": 1, "__cHei": 1, "__gridSize": 8,
+					"__tilesetDefUid": 5, "gridTiles": [{"px": [8, 0], "t": 0}]}
+			]}]
+		}`,
+	}
+
+	for name, content := range projects {
+		t.Run(name, func(t *testing.T) {
+			_, err := ldtk.NewProjectType().Load(writeProject(t, content))
+
+			assert.Error(t, err)
+		})
+	}
+}
+
+const wallsLayer = `{"__identifier": "Walls", "__type": "IntGrid", "__cWid": 1, "__cHei": 1,
+	"__gridSize": 8, "__opacity": 1, "visible": true, "intGridCsv": [1]}`
+
+func writeProject(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "test.ldtk")
+
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func loadProject(t *testing.T, path string) *ldtk.Project {
+	r, err := ldtk.NewProjectType().Load(path)
+
+	require.NoError(t, err)
+
+	p, ok := r.(*ldtk.Project)
+
+	require.True(t, ok)
+
+	return p
+}
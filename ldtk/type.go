@@ -0,0 +1,33 @@
+package ldtk
+
+import (
+	"fmt"
+
+	"github.com/jamestunnell/topdown/jsonfile"
+	"github.com/jamestunnell/topdown/resource"
+)
+
+// ProjectType loads LDtk projects.
+type ProjectType struct{}
+
+func NewProjectType() resource.Type {
+	return &ProjectType{}
+}
+
+func (t *ProjectType) Name() string {
+	return "ldtk"
+}
+
+func (t *ProjectType) Load(path string) (resource.Resource, error) {
+	p, err := jsonfile.Read[*jsonProject](path)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := newProject(path, p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert project: %w", err)
+	}
+
+	return project, nil
+}
@@ -0,0 +1,71 @@
+package movecollide
+
+import (
+	"github.com/zergon321/cirno"
+)
+
+//go:generate mockgen -destination=mock_movecollide/mockbackend.go . Backend
+
+// Backend stores the shapes used by the move-collide system, and finds
+// shapes that might overlap (broadphase) and those that do (narrowphase).
+type Backend interface {
+	Add(shape cirno.Shape) error
+	Remove(shape cirno.Shape) error
+	// Update must be called after a shape is moved.
+	Update(shape cirno.Shape) error
+	// Query finds the shapes that might overlap the given bounds.
+	Query(min, max cirno.Vector) (cirno.Shapes, error)
+	// Collide checks if a shape overlaps another shape that its mask
+	// includes the identity of.
+	Collide(shape, other cirno.Shape) (bool, error)
+	// Bounds gets the world bounds. For an unbounded world, the bounds
+	// cover all the shapes, and bounded is false.
+	Bounds() (min, max cirno.Vector, bounded bool)
+}
+
+// LineQuerier is an optional interface for a backend to find the shapes
+// that might overlap a line more closely than by its bounds, as for long
+// diagonal rays.
+type LineQuerier interface {
+	QueryLine(a, b cirno.Vector) (cirno.Shapes, error)
+}
+
+// collidedBy finds the shapes that the given shape overlaps, using its mask.
+func collidedBy(b Backend, shape cirno.Shape) (cirno.Shapes, error) {
+	candidates, err := query(b, shape)
+	if err != nil {
+		return nil, err
+	}
+
+	shapes := cirno.Shapes{}
+
+	for other := range candidates {
+		if other == shape {
+			continue
+		}
+
+		overlapped, err := b.Collide(shape, other)
+		if err != nil {
+			return nil, err
+		}
+
+		if overlapped {
+			shapes.Insert(other)
+		}
+	}
+
+	return shapes, nil
+}
+
+// query finds the shapes that might overlap the given shape.
+func query(b Backend, shape cirno.Shape) (cirno.Shapes, error) {
+	if line, ok := shape.(*cirno.Line); ok {
+		if lq, ok := b.(LineQuerier); ok {
+			return lq.QueryLine(line.P(), line.Q())
+		}
+	}
+
+	min, max := shapeBounds(shape)
+
+	return b.Query(min, max)
+}
@@ -0,0 +1,327 @@
+package movecollide_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zergon321/cirno"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/movecollide"
+)
+
+type makeBackendFunc func(t testing.TB) movecollide.Backend
+
+var backends = map[string]makeBackendFunc{
+	"cirno": func(t testing.TB) movecollide.Backend {
+		b, err := movecollide.NewCirnoBackend(1000, 1000,
+			movecollide.CollisionSpaceCapacity, movecollide.CollisionSpaceSubdivision)
+
+		require.NoError(t, err)
+
+		return b
+	},
+	"spatialHash": func(t testing.TB) movecollide.Backend {
+		b, err := movecollide.NewSpatialHash(movecollide.DefaultCellSize)
+
+		require.NoError(t, err)
+
+		return b
+	},
+	"boundedSpatialHash": func(t testing.TB) movecollide.Backend {
+		b, err := movecollide.NewBoundedSpatialHash(1000, 1000, movecollide.DefaultCellSize)
+
+		require.NoError(t, err)
+
+		return b
+	},
+}
+
+func TestBackends(t *testing.T) {
+	for name, makeBackend := range backends {
+		t.Run(name, func(t *testing.T) {
+			b := makeBackend(t)
+
+			a := newTaggedRect(t, 100, 100, 10, 10)
+			c := newTaggedRect(t, 300, 100, 10, 10)
+
+			require.NoError(t, b.Add(a))
+			require.NoError(t, b.Add(c))
+
+			found, err := b.Query(cirno.NewVector(90, 90), cirno.NewVector(110, 110))
+
+			require.NoError(t, err)
+			assert.Contains(t, found, a)
+			assert.NotContains(t, found, c)
+
+			c.SetPosition(cirno.NewVector(105, 100))
+
+			require.NoError(t, b.Update(c))
+
+			found, err = b.Query(cirno.NewVector(90, 90), cirno.NewVector(110, 110))
+
+			require.NoError(t, err)
+			assert.Contains(t, found, c)
+
+			// bounds centered outside the world can be queried
+			found, err = b.Query(cirno.NewVector(-5000, 90), cirno.NewVector(96, 110))
+
+			require.NoError(t, err)
+			assert.Contains(t, found, a)
+
+			overlapped, err := b.Collide(a, c)
+
+			require.NoError(t, err)
+			assert.True(t, overlapped)
+
+			require.NoError(t, b.Remove(c))
+
+			found, err = b.Query(cirno.NewVector(90, 90), cirno.NewVector(110, 110))
+
+			require.NoError(t, err)
+			assert.NotContains(t, found, c)
+		})
+	}
+}
+
+func TestSpatialHash(t *testing.T) {
+	_, err := movecollide.NewSpatialHash(0)
+
+	assert.Error(t, err)
+
+	b, err := movecollide.NewSpatialHash(10)
+
+	require.NoError(t, err)
+
+	_, _, bounded := b.Bounds()
+
+	assert.False(t, bounded)
+
+	a := newTaggedRect(t, -1000, -500, 10, 10)
+
+	assert.Error(t, b.Remove(a))
+	assert.Error(t, b.Update(a))
+	require.NoError(t, b.Add(a))
+	assert.Error(t, b.Add(a))
+
+	min, max, bounded := b.Bounds()
+
+	assert.False(t, bounded)
+	assert.Equal(t, cirno.NewVector(-1005, -505), min)
+	assert.Equal(t, cirno.NewVector(-995, -495), max)
+
+	c := newTaggedRect(t, 0, 0, 10, 10)
+
+	require.NoError(t, b.Add(c))
+
+	min, max, _ = b.Bounds()
+
+	assert.Equal(t, cirno.NewVector(-1005, -505), min)
+	assert.Equal(t, cirno.NewVector(5, 5), max)
+
+	// the bounds shrink when shapes at the edge move in or go away
+	c.SetPosition(cirno.NewVector(-500, -300))
+
+	require.NoError(t, b.Update(c))
+
+	_, max, _ = b.Bounds()
+
+	assert.Equal(t, cirno.NewVector(-495, -295), max)
+
+	require.NoError(t, b.Remove(a))
+
+	min, max, _ = b.Bounds()
+
+	assert.Equal(t, cirno.NewVector(-505, -305), min)
+	assert.Equal(t, cirno.NewVector(-495, -295), max)
+}
+
+func TestBoundedSpatialHash(t *testing.T) {
+	_, err := movecollide.NewBoundedSpatialHash(0, 100, 10)
+
+	assert.Error(t, err)
+
+	_, err = movecollide.NewBoundedSpatialHash(100, 100, 0)
+
+	assert.Error(t, err)
+
+	b, err := movecollide.NewBoundedSpatialHash(100, 50, 10)
+
+	require.NoError(t, err)
+	require.NoError(t, b.Add(newTaggedRect(t, 500, 500, 10, 10)))
+
+	// the world bounds do not follow the shapes
+	min, max, bounded := b.Bounds()
+
+	assert.True(t, bounded)
+	assert.Equal(t, cirno.Zero(), min)
+	assert.Equal(t, cirno.NewVector(100, 50), max)
+}
+
+func TestSpatialHashQueryLine(t *testing.T) {
+	b, err := movecollide.NewSpatialHash(10)
+
+	require.NoError(t, err)
+
+	lq, ok := b.(movecollide.LineQuerier)
+
+	require.True(t, ok)
+
+	onLine := newTaggedRect(t, 55, 55, 4, 4)
+	offLine := newTaggedRect(t, 85, 15, 4, 4)
+
+	require.NoError(t, b.Add(onLine))
+	require.NoError(t, b.Add(offLine))
+
+	// the off-line shape is inside the bounds of the line, but not in
+	// the cells it crosses
+	found, err := b.Query(cirno.NewVector(0, 0), cirno.NewVector(100, 100))
+
+	require.NoError(t, err)
+	assert.Len(t, found, 2)
+
+	for _, ends := range [][2]cirno.Vector{
+		{cirno.NewVector(0, 0), cirno.NewVector(100, 100)},
+		{cirno.NewVector(100, 100), cirno.NewVector(0, 0)},
+		{cirno.NewVector(0, 55), cirno.NewVector(100, 55)},
+		{cirno.NewVector(55, 100), cirno.NewVector(55, -100)},
+	} {
+		found, err := lq.QueryLine(ends[0], ends[1])
+
+		require.NoError(t, err)
+		assert.Contains(t, found, onLine, ends)
+		assert.NotContains(t, found, offLine, ends)
+	}
+}
+
+func TestSystemWithUnboundedWorld(t *testing.T) {
+	b, err := movecollide.NewSpatialHash(movecollide.DefaultCellSize)
+
+	require.NoError(t, err)
+
+	s, err := movecollide.NewSystemWithBackend(b)
+
+	require.NoError(t, err)
+
+	mover := newTestMover(t, -5000, -50, 10, 10)
+	wall := newTestMover(t, 3000, -50, 10, 50)
+
+	s.Add("mover", mover)
+	s.Add("wall", wall)
+
+	mover.Velocity = topdown.Vec(10000, 0)
+
+	s.MoveCollide(1)
+
+	assert.InDelta(t, 2990, mover.Position.X, 0.01)
+
+	hit, found := s.Raycast(&movecollide.Ray{
+		Origin:    cirno.NewVector(-10000, -50),
+		Direction: cirno.NewVector(1, 0),
+	})
+
+	require.True(t, found)
+	assert.Equal(t, "mover", hit.ID)
+}
+
+func BenchmarkMoveCollide(b *testing.B) {
+	for name, makeBackend := range backends {
+		for _, n := range []int{100, 1000} {
+			b.Run(fmt.Sprintf("%s/%d", name, n), func(b *testing.B) {
+				s := newBenchSystem(b, makeBackend(b), n)
+
+				b.ResetTimer()
+
+				for i := 0; i < b.N; i++ {
+					s.MoveCollide(1.0 / 60)
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkOverlapRect(b *testing.B) {
+	for name, makeBackend := range backends {
+		b.Run(name, func(b *testing.B) {
+			s := newBenchSystem(b, makeBackend(b), 1000)
+			area := topdown.Rect(400.0, 400.0, 600.0, 600.0)
+
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				s.OverlapRect(area, movecollide.LayerAll)
+			}
+		})
+	}
+}
+
+func BenchmarkRaycast(b *testing.B) {
+	for name, makeBackend := range backends {
+		b.Run(name, func(b *testing.B) {
+			s := newBenchSystem(b, makeBackend(b), 1000)
+			ray := &movecollide.Ray{Origin: cirno.NewVector(0, 0), Direction: cirno.NewVector(1, 1)}
+
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				s.RaycastAll(ray)
+			}
+		})
+	}
+
+	// a diagonal ray across a wide unbounded world only visits the cells it crosses
+	b.Run("spatialHash/wide", func(b *testing.B) {
+		s := newBenchSystem(b, backends["spatialHash"](b), 1000)
+
+		s.Add("far", &testStatic{Shapes: []cirno.Shape{newTaggedRect(b, 100000, 100000, 10, 10)}})
+
+		ray := &movecollide.Ray{Origin: cirno.NewVector(0, 0), Direction: cirno.NewVector(1, 1)}
+
+		b.ResetTimer()
+
+		for i := 0; i < b.N; i++ {
+			s.RaycastAll(ray)
+		}
+	})
+}
+
+// newBenchSystem makes a system with movers wandering around a 1000x1000 world.
+func newBenchSystem(b *testing.B, backend movecollide.Backend, n int) movecollide.System {
+	s, err := movecollide.NewSystemWithBackend(backend)
+
+	require.NoError(b, err)
+
+	rng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < n; i++ {
+		x, y := 20+rng.Float64()*960, 20+rng.Float64()*960
+		rect, err := cirno.NewRectangle(cirno.NewVector(x, y), 8, 8, 0)
+
+		require.NoError(b, err)
+
+		mover := &testMover{
+			Position: topdown.Vec(x, y),
+			Velocity: topdown.Vec(rng.Float64()*100-50, rng.Float64()*100-50),
+			Collider: rect,
+			Resolver: movecollide.NewSlideResolver(),
+		}
+
+		s.Add(fmt.Sprintf("mover%d", i), mover)
+	}
+
+	return s
+}
+
+func newTaggedRect(t testing.TB, x, y, w, h float64) *cirno.Rectangle {
+	rect, err := cirno.NewRectangle(cirno.NewVector(x, y), w, h, 0)
+
+	require.NoError(t, err)
+
+	rect.SetIdentity(int32(movecollide.LayerDefault))
+	rect.SetMask(int32(movecollide.LayerAll))
+
+	return rect
+}
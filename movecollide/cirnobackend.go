@@ -0,0 +1,97 @@
+package movecollide
+
+import (
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/zergon321/cirno"
+)
+
+type cirnoBackend struct {
+	space *cirno.Space
+	// shapes are kept to query by bounds without changing the space
+	shapes cirno.Shapes
+}
+
+const (
+	CollisionSpaceCapacity    = 1024
+	CollisionSpaceSubdivision = 10
+)
+
+// NewCirnoBackend makes a backend using a cirno quad tree space, which
+// covers a fixed world area. Each node of the tree holds up to the given
+// capacity of shapes before subdividing, up to the given levels. Queries
+// check the bounds of every shape, so a spatial hash is faster for worlds
+// with many shapes.
+func NewCirnoBackend(worldWidth, worldHeight float64, capacity, subdivisions int) (Backend, error) {
+	spaceMin := cirno.Zero()
+	spaceMax := cirno.NewVector(worldWidth, worldHeight)
+
+	log.Debug().
+		Float64("w", worldWidth).
+		Float64("h", worldHeight).
+		Msg("creating collision space")
+
+	space, err := cirno.NewSpace(
+		subdivisions, capacity,
+		2*worldWidth, 2*worldHeight, spaceMin, spaceMax, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make collision space: %w", err)
+	}
+
+	return &cirnoBackend{space: space, shapes: cirno.Shapes{}}, nil
+}
+
+func (b *cirnoBackend) Add(shape cirno.Shape) error {
+	if err := b.space.Add(shape); err != nil {
+		return err
+	}
+
+	b.shapes.Insert(shape)
+
+	return nil
+}
+
+func (b *cirnoBackend) Remove(shape cirno.Shape) error {
+	if err := b.space.Remove(shape); err != nil {
+		return err
+	}
+
+	b.shapes.Remove(shape)
+
+	return nil
+}
+
+func (b *cirnoBackend) Update(shape cirno.Shape) error {
+	b.space.AdjustShapePosition(shape)
+
+	if _, err := b.space.Update(shape); err != nil {
+		return fmt.Errorf("failed to update collision space: %w", err)
+	}
+
+	return nil
+}
+
+// Query finds the shapes whose bounds overlap the given bounds. The space
+// is left as it is, so bounds outside the world can be queried.
+func (b *cirnoBackend) Query(min, max cirno.Vector) (cirno.Shapes, error) {
+	shapes := cirno.Shapes{}
+
+	for shape := range b.shapes {
+		shapeMin, shapeMax := shapeBounds(shape)
+
+		if shapeMin.X <= max.X && shapeMax.X >= min.X && shapeMin.Y <= max.Y && shapeMax.Y >= min.Y {
+			shapes.Insert(shape)
+		}
+	}
+
+	return shapes, nil
+}
+
+func (b *cirnoBackend) Collide(shape, other cirno.Shape) (bool, error) {
+	return cirno.ResolveCollision(shape, other, true)
+}
+
+func (b *cirnoBackend) Bounds() (cirno.Vector, cirno.Vector, bool) {
+	return b.space.Min(), b.space.Max(), true
+}
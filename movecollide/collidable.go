@@ -5,7 +5,19 @@ import "github.com/zergon321/cirno"
 //go:generate mockgen -destination=mock_movecollide/mockcollidable.go . Collidable
 
 // Collidable is a component used in the move-collide system.
+// The collision resolver decides how a planned movement is adjusted when
+// it would collide with other shapes. A nil resolver stops the movement
+// at the first contact.
 type Collidable interface {
 	ColliderShape() cirno.Shape
-	ResolveCollision(cirno.Vector, cirno.Shapes) cirno.Vector
+	CollisionResolver() Resolver
+}
+
+//go:generate mockgen -destination=mock_movecollide/mockstaticcollidable.go . StaticCollidable
+
+// StaticCollidable is a component with collider shapes that never move,
+// like the walls of a map. Other entities collide with them, but they are
+// not moved by the move-collide system.
+type StaticCollidable interface {
+	StaticColliderShapes() []cirno.Shape
 }
@@ -0,0 +1,256 @@
+package movecollide
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/zergon321/cirno"
+	"golang.org/x/exp/slices"
+
+	"github.com/jamestunnell/topdown"
+)
+
+// ColliderSpec describes a collider shape, placed relative to the entity origin.
+type ColliderSpec struct {
+	// Shape is the shape type: rect, circle, or polygon.
+	Shape string `json:"shape"`
+	// Size is the size of a rect.
+	Size *topdown.Size[float64] `json:"size,omitempty"`
+	// Radius is the radius of a circle.
+	Radius float64 `json:"radius,omitempty"`
+	// Points are the vertices of a polygon, relative to the offset.
+	Points []topdown.Vector `json:"points,omitempty"`
+	// Offset is the shape center (or polygon origin) relative to the entity origin.
+	Offset topdown.Vector `json:"offset"`
+	// Rotation is the rotation of a rect or polygon in degrees, counter-clockwise.
+	Rotation float64 `json:"rotation,omitempty"`
+}
+
+const (
+	ColliderShapeRect   = "rect"
+	ColliderShapeCircle = "circle"
+	// ColliderShapePolygon is approximated by the smallest rectangle that
+	// encloses the points, so that only rectangles collide exactly. Other
+	// polygons, like triangles for slopes, collide as the whole rectangle
+	// (see ColliderSpec.Approximated).
+	ColliderShapePolygon = "polygon"
+)
+
+// ColliderSchemaStr is the JSON schema for a collider spec.
+// It requires the vector and size schemas.
+const ColliderSchemaStr = `{
+  "$id": "https://github.com/jamestunnell/topdown/collider.json",
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "Collider",
+  "description": "Collider shape, placed relative to the entity origin. A polygon collides as the smallest rectangle enclosing its points.",
+  "type": "object",
+  "required": ["shape"],
+  "properties": {
+    "shape": {"enum": ["rect", "circle", "polygon"]},
+    "size": {"$ref": "https://github.com/jamestunnell/topdown/size.json"},
+    "radius": {"type": "number", "exclusiveMinimum": 0},
+    "points": {
+      "type": "array",
+      "items": {"$ref": "https://github.com/jamestunnell/topdown/vector.json"},
+      "minItems": 3
+    },
+    "offset": {"$ref": "https://github.com/jamestunnell/topdown/vector.json"},
+    "rotation": {"type": "number"}
+  },
+  "allOf": [
+    {
+      "if": {"properties": {"shape": {"const": "rect"}}},
+      "then": {"required": ["size"]}
+    },
+    {
+      "if": {"properties": {"shape": {"const": "circle"}}},
+      "then": {"required": ["radius"]}
+    },
+    {
+      "if": {"properties": {"shape": {"const": "polygon"}}},
+      "then": {"required": ["points"]}
+    }
+  ]
+}`
+
+// NewCollider makes a collider shape for an entity at the given origin.
+// The collision library has no polygon shape, so a polygon is made into
+// the smallest rectangle, at any rotation, that encloses its points.
+func NewCollider(spec *ColliderSpec, origin topdown.Vector) (cirno.Shape, error) {
+	center := cirno.NewVector(origin.X+spec.Offset.X, origin.Y+spec.Offset.Y)
+
+	switch spec.Shape {
+	case ColliderShapeRect:
+		if spec.Size == nil {
+			return nil, fmt.Errorf("rect collider has no size")
+		}
+
+		rect, err := cirno.NewRectangle(center, spec.Size.Width, spec.Size.Height, spec.Rotation)
+		if err != nil {
+			return nil, fmt.Errorf("failed to make rect collider: %w", err)
+		}
+
+		return rect, nil
+	case ColliderShapeCircle:
+		circle, err := cirno.NewCircle(center, spec.Radius)
+		if err != nil {
+			return nil, fmt.Errorf("failed to make circle collider: %w", err)
+		}
+
+		return circle, nil
+	case ColliderShapePolygon:
+		rect, err := enclosingRect(spec.Points)
+		if err != nil {
+			return nil, fmt.Errorf("failed to make polygon collider: %w", err)
+		}
+
+		rect.RotateAround(spec.Rotation, cirno.Zero())
+		rect.Rotate(spec.Rotation)
+		rect.Move(center)
+
+		return rect, nil
+	}
+
+	return nil, fmt.Errorf("unknown collider shape '%s'", spec.Shape)
+}
+
+// Approximated checks if the collider is a polygon that collides as a
+// rectangle covering more than the polygon, like a triangle.
+func (spec *ColliderSpec) Approximated() bool {
+	if spec.Shape != ColliderShapePolygon {
+		return false
+	}
+
+	rect, err := enclosingRect(spec.Points)
+	if err != nil {
+		return false
+	}
+
+	rectArea := rect.Width() * rect.Height()
+
+	return rectArea-polygonArea(spec.Points) > 1e-6*rectArea
+}
+
+// ShapeBounds gets the axis-aligned bounding box of a shape.
+func ShapeBounds(shape cirno.Shape) (cirno.Vector, cirno.Vector) {
+	return shapeBounds(shape)
+}
+
+// enclosingRect finds the smallest rectangle enclosing the points. It is
+// aligned with one of the edges of the convex hull of the points.
+func enclosingRect(points []topdown.Vector) (*cirno.Rectangle, error) {
+	hull := convexHull(points)
+	if len(hull) < 3 {
+		return nil, fmt.Errorf("points do not enclose an area")
+	}
+
+	var best *cirno.Rectangle
+
+	bestArea := math.Inf(1)
+
+	for i, a := range hull {
+		b := hull[(i+1)%len(hull)]
+
+		xAxis, err := b.Subtract(a).Normalize()
+		if err != nil {
+			continue
+		}
+
+		yAxis := cirno.NewVector(-xAxis.Y, xAxis.X)
+		minX, minY := math.Inf(1), math.Inf(1)
+		maxX, maxY := math.Inf(-1), math.Inf(-1)
+
+		for _, p := range hull {
+			x, y := cirno.Dot(p, xAxis), cirno.Dot(p, yAxis)
+
+			minX, maxX = math.Min(minX, x), math.Max(maxX, x)
+			minY, maxY = math.Min(minY, y), math.Max(maxY, y)
+		}
+
+		w, h := maxX-minX, maxY-minY
+		if w*h >= bestArea {
+			continue
+		}
+
+		center := xAxis.MultiplyByScalar((minX + maxX) / 2).
+			Add(yAxis.MultiplyByScalar((minY + maxY) / 2))
+		angle := math.Atan2(xAxis.Y, xAxis.X) * cirno.RadToDeg
+
+		rect, err := cirno.NewRectangle(center, w, h, angle)
+		if err != nil {
+			continue
+		}
+
+		best, bestArea = rect, w*h
+	}
+
+	if best == nil {
+		return nil, fmt.Errorf("points do not enclose an area")
+	}
+
+	return best, nil
+}
+
+// polygonArea gets the area enclosed by the points, in order.
+func polygonArea(points []topdown.Vector) float64 {
+	area := 0.0
+
+	for i, a := range points {
+		b := points[(i+1)%len(points)]
+		area += a.X*b.Y - b.X*a.Y
+	}
+
+	return math.Abs(area) / 2
+}
+
+// convexHull finds the convex hull of the points, in counter-clockwise
+// order, using the monotone chain algorithm.
+func convexHull(points []topdown.Vector) []cirno.Vector {
+	sorted := make([]cirno.Vector, len(points))
+
+	for i, p := range points {
+		sorted[i] = cirno.NewVector(p.X, p.Y)
+	}
+
+	slices.SortFunc(sorted, func(a, b cirno.Vector) bool {
+		if a.X != b.X {
+			return a.X < b.X
+		}
+
+		return a.Y < b.Y
+	})
+
+	cross := func(o, a, b cirno.Vector) float64 {
+		return (a.X-o.X)*(b.Y-o.Y) - (a.Y-o.Y)*(b.X-o.X)
+	}
+
+	n := len(sorted)
+	if n < 3 {
+		return sorted
+	}
+
+	hull := []cirno.Vector{}
+
+	// lower chain, then upper chain
+	for pass := 0; pass < 2; pass++ {
+		start := len(hull)
+
+		for i := 0; i < n; i++ {
+			p := sorted[i]
+			if pass == 1 {
+				p = sorted[n-1-i]
+			}
+
+			for len(hull) >= start+2 && cross(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+				hull = hull[:len(hull)-1]
+			}
+
+			hull = append(hull, p)
+		}
+
+		// the last point starts the other chain
+		hull = hull[:len(hull)-1]
+	}
+
+	return hull
+}
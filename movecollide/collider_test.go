@@ -0,0 +1,202 @@
+package movecollide_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xeipuuv/gojsonschema"
+	"github.com/zergon321/cirno"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/movecollide"
+	"github.com/jamestunnell/topdown/resource"
+)
+
+func TestNewCollider(t *testing.T) {
+	origin := topdown.Vec(100, 50)
+
+	testCases := map[string]struct {
+		spec             *movecollide.ColliderSpec
+		expectMin        cirno.Vector
+		expectMax        cirno.Vector
+		expectRectAngle  float64
+		expectRectWidth  float64
+		expectRectHeight float64
+	}{
+		"rect": {
+			spec: &movecollide.ColliderSpec{
+				Shape:  movecollide.ColliderShapeRect,
+				Size:   &topdown.Size[float64]{Width: 20, Height: 10},
+				Offset: topdown.Vec(0, 5),
+			},
+			expectMin:        cirno.NewVector(90, 50),
+			expectMax:        cirno.NewVector(110, 60),
+			expectRectWidth:  20,
+			expectRectHeight: 10,
+		},
+		"rotated rect": {
+			spec: &movecollide.ColliderSpec{
+				Shape:    movecollide.ColliderShapeRect,
+				Size:     &topdown.Size[float64]{Width: 20, Height: 10},
+				Rotation: 90,
+			},
+			expectMin:        cirno.NewVector(95, 40),
+			expectMax:        cirno.NewVector(105, 60),
+			expectRectAngle:  90,
+			expectRectWidth:  20,
+			expectRectHeight: 10,
+		},
+		"circle": {
+			spec: &movecollide.ColliderSpec{
+				Shape:  movecollide.ColliderShapeCircle,
+				Radius: 5,
+				Offset: topdown.Vec(-5, 0),
+			},
+			expectMin: cirno.NewVector(90, 45),
+			expectMax: cirno.NewVector(100, 55),
+		},
+		"polygon": {
+			spec: &movecollide.ColliderSpec{
+				Shape: movecollide.ColliderShapePolygon,
+				// concave, with a notch that the enclosing rect covers
+				Points: []topdown.Vector{{X: -10, Y: 0}, {X: 10, Y: 0}, {X: 10, Y: 10}, {X: 0, Y: 5}, {X: -10, Y: 10}},
+				Offset: topdown.Vec(0, -10),
+			},
+			expectMin:        cirno.NewVector(90, 40),
+			expectMax:        cirno.NewVector(110, 50),
+			expectRectWidth:  20,
+			expectRectHeight: 10,
+		},
+		"rotated polygon": {
+			spec: &movecollide.ColliderSpec{
+				Shape:    movecollide.ColliderShapePolygon,
+				Points:   []topdown.Vector{{X: 0, Y: 0}, {X: 20, Y: 0}, {X: 20, Y: 10}, {X: 0, Y: 10}},
+				Rotation: 90,
+			},
+			expectMin:        cirno.NewVector(90, 50),
+			expectMax:        cirno.NewVector(100, 70),
+			expectRectAngle:  90,
+			expectRectWidth:  20,
+			expectRectHeight: 10,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			shape, err := movecollide.NewCollider(tc.spec, origin)
+
+			require.NoError(t, err)
+
+			min, max := movecollide.ShapeBounds(shape)
+
+			assert.InDelta(t, tc.expectMin.X, min.X, 1e-6)
+			assert.InDelta(t, tc.expectMin.Y, min.Y, 1e-6)
+			assert.InDelta(t, tc.expectMax.X, max.X, 1e-6)
+			assert.InDelta(t, tc.expectMax.Y, max.Y, 1e-6)
+
+			if rect, ok := shape.(*cirno.Rectangle); ok {
+				assert.InDelta(t, tc.expectRectAngle, rect.Angle(), 1e-6)
+				assert.InDelta(t, tc.expectRectWidth, rect.Width(), 1e-6)
+				assert.InDelta(t, tc.expectRectHeight, rect.Height(), 1e-6)
+			}
+		})
+	}
+}
+
+func TestColliderApproximated(t *testing.T) {
+	specs := map[string]struct {
+		spec   *movecollide.ColliderSpec
+		expect bool
+	}{
+		"rect":   {spec: &movecollide.ColliderSpec{Shape: movecollide.ColliderShapeRect, Size: &topdown.Size[float64]{Width: 4, Height: 2}}},
+		"circle": {spec: &movecollide.ColliderSpec{Shape: movecollide.ColliderShapeCircle, Radius: 3}},
+		"rect polygon": {
+			spec: &movecollide.ColliderSpec{
+				Shape:  movecollide.ColliderShapePolygon,
+				Points: []topdown.Vector{{X: 0, Y: 0}, {X: 3, Y: 3}, {X: 1, Y: 5}, {X: -2, Y: 2}},
+			},
+		},
+		"triangle": {
+			spec: &movecollide.ColliderSpec{
+				Shape:  movecollide.ColliderShapePolygon,
+				Points: []topdown.Vector{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 0, Y: 4}},
+			},
+			expect: true,
+		},
+		"L shape": {
+			spec: &movecollide.ColliderSpec{
+				Shape:  movecollide.ColliderShapePolygon,
+				Points: []topdown.Vector{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 4}, {X: 0, Y: 4}},
+			},
+			expect: true,
+		},
+	}
+
+	for name, tc := range specs {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expect, tc.spec.Approximated())
+		})
+	}
+}
+
+func TestNewColliderInvalid(t *testing.T) {
+	specs := map[string]*movecollide.ColliderSpec{
+		"unknown shape":   {Shape: "hexagon"},
+		"rect no size":    {Shape: movecollide.ColliderShapeRect},
+		"circle radius":   {Shape: movecollide.ColliderShapeCircle},
+		"polygon line":    {Shape: movecollide.ColliderShapePolygon, Points: []topdown.Vector{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 2}}},
+		"polygon no area": {Shape: movecollide.ColliderShapePolygon},
+	}
+
+	for name, spec := range specs {
+		t.Run(name, func(t *testing.T) {
+			_, err := movecollide.NewCollider(spec, topdown.Vec(0, 0))
+
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestColliderSchema(t *testing.T) {
+	schema, err := resource.MakeJSONSchema(
+		movecollide.ColliderSchemaStr, topdown.VectorSchemaStr, topdown.SizeSchemaStr)
+
+	require.NoError(t, err)
+
+	valid := []string{
+		`{"shape": "rect", "size": {"w": 10, "h": 5}, "offset": {"x": 0, "y": 2}, "rotation": 45}`,
+		`{"shape": "circle", "radius": 4}`,
+		`{"shape": "polygon", "points": [{"x": 0, "y": 0}, {"x": 4, "y": 0}, {"x": 0, "y": 4}]}`,
+	}
+	invalid := []string{
+		`{"size": {"w": 10, "h": 5}}`,
+		`{"shape": "hexagon"}`,
+		`{"shape": "rect"}`,
+		`{"shape": "circle", "radius": 0}`,
+		`{"shape": "polygon", "points": [{"x": 0, "y": 0}, {"x": 4, "y": 0}]}`,
+	}
+
+	for _, str := range valid {
+		result, err := schema.Validate(gojsonschema.NewStringLoader(str))
+
+		require.NoError(t, err)
+		assert.True(t, result.Valid(), str)
+
+		var spec movecollide.ColliderSpec
+
+		require.NoError(t, json.Unmarshal([]byte(str), &spec))
+
+		_, err = movecollide.NewCollider(&spec, topdown.Vec(0, 0))
+
+		assert.NoError(t, err)
+	}
+
+	for _, str := range invalid {
+		result, err := schema.Validate(gojsonschema.NewStringLoader(str))
+
+		require.NoError(t, err)
+		assert.False(t, result.Valid(), str)
+	}
+}
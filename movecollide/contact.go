@@ -0,0 +1,249 @@
+package movecollide
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/zergon321/cirno"
+)
+
+// Collision describes a planned movement that would collide with other shapes.
+type Collision struct {
+	// ID is the ID of the moving entity.
+	ID string
+	// Object is the moving entity.
+	Object any
+	// Shape is the collider shape of the moving entity.
+	Shape cirno.Shape
+	// Move is the planned movement.
+	Move cirno.Vector
+	// Contact is the first contact made along the planned movement.
+	Contact *Contact
+
+	system *system
+}
+
+// Contact describes the first shape hit along a movement.
+type Contact struct {
+	ID     string
+	Object any
+	Shape  cirno.Shape
+	// Normal is the unit surface normal at the contact, pointing
+	// from the other shape toward the moving shape.
+	Normal cirno.Vector
+	// Point is where the shapes meet.
+	Point cirno.Vector
+	// Fraction is the fraction of the movement that can be made before
+	// making contact.
+	Fraction float64
+}
+
+const (
+	// SweepIterations is the number of bisections used to find where a
+	// movement first makes contact.
+	SweepIterations = 16
+)
+
+// Sweep finds the first contact made if the collider were moved by the
+// given amount after first being offset from its current position.
+// Returns false if no contact would be made.
+func (c *Collision) Sweep(offset, move cirno.Vector) (*Contact, bool) {
+	contact, err := c.system.sweep(c.Shape, offset, move)
+	if err != nil {
+		return nil, false
+	}
+
+	return contact, contact != nil
+}
+
+// Push attempts to move another entity by the given amount. The other
+// entity stops short at anything it would collide with, which makes a
+// collision event. Returns the movement actually made, which is zero if
+// the other entity isn't movable.
+func (c *Collision) Push(id string, move cirno.Vector) cirno.Vector {
+	return c.system.push(id, move, c.system.velocities[c.ID])
+}
+
+// sweep finds the first contact made by a shape moving from its current
+// position plus offset. Shapes that the shape already overlaps at the
+// starting position are ignored, so that overlapping shapes can separate.
+func (s *system) sweep(shape cirno.Shape, offset, move cirno.Vector) (*Contact, error) {
+	origin := shape.Center()
+	start := origin.Add(offset)
+
+	defer s.placeShape(shape, origin)
+
+	shape.SetPosition(start)
+
+	min, max := sweptBounds(shape, move)
+
+	area, err := boundsRect(min, max)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make swept area: %w", err)
+	}
+
+	candidates, err := s.overlapping(area, Layer(shape.GetMask()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find shapes in swept area: %w", err)
+	}
+
+	delete(candidates, shape)
+
+	for other := range candidates {
+		if overlapsAt(shape, start, other) {
+			delete(candidates, other)
+		}
+	}
+
+	// check candidates in a fixed order, so ties always go the same way
+	others := sortShapes(candidates)
+
+	if len(others) == 0 {
+		return nil, nil
+	}
+
+	// step along the movement, no more than half the shape thickness at a
+	// time so that nothing is passed over, to find the first overlap
+	steps := 1
+	if thickness := shapeThickness(shape); thickness > 0 {
+		steps = int(math.Ceil(2 * move.Magnitude() / thickness))
+	}
+
+	lo, hi := 0.0, 0.0
+
+	for i := 1; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+
+		if firstOverlapAt(shape, start.Add(move.MultiplyByScalar(t)), others) != nil {
+			hi = t
+
+			break
+		}
+
+		lo = t
+	}
+
+	if hi == 0 {
+		return nil, nil
+	}
+
+	// bisect to find the last free fraction of the movement
+	for i := 0; i < SweepIterations; i++ {
+		mid := (lo + hi) / 2
+
+		if firstOverlapAt(shape, start.Add(move.MultiplyByScalar(mid)), others) != nil {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+
+	hit := firstOverlapAt(shape, start.Add(move.MultiplyByScalar(hi)), others)
+	point := contactPoint(shape, hit)
+
+	shape.SetPosition(start.Add(move.MultiplyByScalar(lo)))
+
+	normal, err := shape.NormalTo(hit)
+	if err != nil || normal.ApproximatelyEqual(cirno.Zero()) {
+		// fall back to opposing the movement
+		normal = move
+	}
+
+	if normal, err = normal.Normalize(); err != nil {
+		return nil, fmt.Errorf("failed to normalize contact normal: %w", err)
+	}
+
+	// the normal should oppose the movement
+	if cirno.Dot(normal, move) > 0 {
+		normal = normal.MultiplyByScalar(-1)
+	}
+
+	contact := &Contact{
+		Shape:    hit,
+		Normal:   normal,
+		Point:    point,
+		Fraction: lo,
+	}
+
+	if id, ok := hit.Data().(string); ok {
+		contact.ID = id
+		contact.Object = s.object(id)
+	}
+
+	return contact, nil
+}
+
+// push moves an entity as far as it can go by the given amount, without
+// pushing anything else. The entity is taken to be moving at the velocity
+// of the pusher.
+func (s *system) push(id string, move, velocity cirno.Vector) cirno.Vector {
+	m, found := s.movables[id]
+	if !found {
+		return cirno.Zero()
+	}
+
+	shape, found := s.colliderShapes[id]
+	if !found {
+		return cirno.Zero()
+	}
+
+	contact, err := s.sweep(shape, cirno.Zero(), move)
+	if err != nil {
+		return cirno.Zero()
+	}
+
+	if contact != nil {
+		s.queueCollision(id, contact, velocity)
+
+		move = move.MultiplyByScalar(contact.Fraction)
+	}
+
+	s.moveEntity(id, m, move)
+
+	return move
+}
+
+// placeShape sets the shape position and updates the collision backend.
+func (s *system) placeShape(shape cirno.Shape, pos cirno.Vector) error {
+	shape.SetPosition(pos)
+
+	if err := s.backend.Update(shape); err != nil {
+		return fmt.Errorf("failed to update collision backend: %w", err)
+	}
+
+	return nil
+}
+
+func overlapsAt(shape cirno.Shape, pos cirno.Vector, other cirno.Shape) bool {
+	shape.SetPosition(pos)
+
+	overlapped, err := cirno.ResolveCollision(shape, other, true)
+
+	return err == nil && overlapped
+}
+
+func firstOverlapAt(shape cirno.Shape, pos cirno.Vector, others []cirno.Shape) cirno.Shape {
+	for _, other := range others {
+		if overlapsAt(shape, pos, other) {
+			return other
+		}
+	}
+
+	return nil
+}
+
+// contactPoint finds the middle of the points where overlapping shapes meet.
+func contactPoint(shape, other cirno.Shape) cirno.Vector {
+	points, err := cirno.Contact(shape, other)
+	if err != nil || len(points) == 0 {
+		return shape.Center()
+	}
+
+	sum := cirno.Zero()
+
+	for _, p := range points {
+		sum = sum.Add(p)
+	}
+
+	return sum.MultiplyByScalar(1 / float64(len(points)))
+}
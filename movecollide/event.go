@@ -0,0 +1,130 @@
+package movecollide
+
+import (
+	"github.com/zergon321/cirno"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// CollisionEvent describes a collision resolved during MoveCollide, from
+// the perspective of one of the two entities involved.
+type CollisionEvent struct {
+	ID     string
+	Object any
+	// OtherID is the ID of the other entity. For world boundaries,
+	// it is the boundary name ("north", "east", "south", or "west").
+	OtherID string
+	// Other is the other entity, which is nil for world boundaries.
+	Other any
+	// Point is where the entities met.
+	Point cirno.Vector
+	// Normal is the unit surface normal at the contact, pointing from
+	// the other entity toward this entity.
+	Normal cirno.Vector
+	// RelativeVelocity is the velocity of this entity relative to the other
+	// entity just before the collision, in units per second.
+	RelativeVelocity cirno.Vector
+}
+
+// CollisionHandler is an optional interface for an entity to be told
+// about collisions it is involved in, whether it was moving or not.
+type CollisionHandler interface {
+	HandleCollision(e *CollisionEvent)
+}
+
+// CollisionListener is told about all collisions. Each event is from the
+// perspective of the entity that was moving.
+type CollisionListener interface {
+	OnCollision(e *CollisionEvent)
+}
+
+// CollisionListenerFunc adapts a function to be used as a CollisionListener.
+type CollisionListenerFunc func(e *CollisionEvent)
+
+type collisionPair struct {
+	id, otherID string
+}
+
+// OnCollision calls the function.
+func (f CollisionListenerFunc) OnCollision(e *CollisionEvent) {
+	f(e)
+}
+
+// Reverse makes the event from the perspective of the other entity.
+func (e *CollisionEvent) Reverse() *CollisionEvent {
+	return &CollisionEvent{
+		ID:               e.OtherID,
+		Object:           e.Other,
+		OtherID:          e.ID,
+		Other:            e.Object,
+		Point:            e.Point,
+		Normal:           e.Normal.MultiplyByScalar(-1),
+		RelativeVelocity: e.RelativeVelocity.MultiplyByScalar(-1),
+	}
+}
+
+func (s *system) AddCollisionListener(id string, l CollisionListener) {
+	s.listeners[id] = l
+}
+
+func (s *system) RemoveCollisionListener(id string) {
+	delete(s.listeners, id)
+}
+
+// queueCollision makes an event for a contact made while moving, unless the
+// same pair of entities already collided during this tick.
+func (s *system) queueCollision(id string, contact *Contact, moveVelocity cirno.Vector) {
+	pair := collisionPair{id: id, otherID: contact.ID}
+	reversePair := collisionPair{id: contact.ID, otherID: id}
+
+	if _, found := s.collided[pair]; found {
+		return
+	}
+
+	if _, found := s.collided[reversePair]; found {
+		return
+	}
+
+	s.collided[pair] = struct{}{}
+
+	e := &CollisionEvent{
+		ID:               id,
+		Object:           s.object(id),
+		OtherID:          contact.ID,
+		Other:            contact.Object,
+		Point:            contact.Point,
+		Normal:           contact.Normal,
+		RelativeVelocity: moveVelocity.Subtract(s.velocities[contact.ID]),
+	}
+
+	s.events = append(s.events, e)
+}
+
+// dispatchCollisions delivers the queued events to both entities and to
+// the listeners.
+func (s *system) dispatchCollisions() {
+	events := s.events
+	listenerIDs := maps.Keys(s.listeners)
+
+	slices.Sort(listenerIDs)
+
+	s.events = []*CollisionEvent{}
+
+	maps.Clear(s.collided)
+
+	for _, e := range events {
+		if h, ok := e.Object.(CollisionHandler); ok {
+			h.HandleCollision(e)
+		}
+
+		if h, ok := e.Other.(CollisionHandler); ok {
+			h.HandleCollision(e.Reverse())
+		}
+
+		for _, id := range listenerIDs {
+			if l, found := s.listeners[id]; found {
+				l.OnCollision(e)
+			}
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package movecollide_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/movecollide"
+)
+
+type handlerMover struct {
+	*testMover
+	Events []*movecollide.CollisionEvent
+}
+
+func TestCollisionEvents(t *testing.T) {
+	s, err := movecollide.NewSystem(200, 200)
+
+	require.NoError(t, err)
+
+	mover := &handlerMover{testMover: newTestMover(t, 50, 50, 10, 10)}
+	wall := &handlerMover{testMover: newTestMover(t, 80, 50, 10, 50)}
+	heard := []*movecollide.CollisionEvent{}
+
+	s.Add("mover", mover)
+	s.Add("wall", wall)
+
+	s.AddCollisionListener("test", movecollide.CollisionListenerFunc(func(e *movecollide.CollisionEvent) {
+		heard = append(heard, e)
+	}))
+
+	mover.Velocity = topdown.Vec(40, 0)
+
+	s.MoveCollide(1)
+
+	// one event per tick, even though every sub-step after the first is blocked
+	require.Len(t, mover.Events, 1)
+	require.Len(t, wall.Events, 1)
+	require.Len(t, heard, 1)
+
+	e := mover.Events[0]
+
+	assert.Equal(t, "mover", e.ID)
+	assert.Equal(t, mover, e.Object)
+	assert.Equal(t, "wall", e.OtherID)
+	assert.Equal(t, wall, e.Other)
+	assert.InDelta(t, 75, e.Point.X, 0.01)
+	assert.InDelta(t, -1, e.Normal.X, 1e-6)
+	assert.InDelta(t, 40, e.RelativeVelocity.X, 1e-6)
+	assert.Equal(t, e, heard[0])
+
+	e = wall.Events[0]
+
+	assert.Equal(t, "wall", e.ID)
+	assert.Equal(t, "mover", e.OtherID)
+	assert.InDelta(t, 1, e.Normal.X, 1e-6)
+	assert.InDelta(t, -40, e.RelativeVelocity.X, 1e-6)
+
+	// pressing against the wall keeps colliding
+	s.MoveCollide(1)
+
+	assert.Len(t, mover.Events, 2)
+	assert.Len(t, heard, 2)
+
+	s.RemoveCollisionListener("test")
+
+	s.MoveCollide(1)
+
+	assert.Len(t, mover.Events, 3)
+	assert.Len(t, heard, 2)
+}
+
+func TestCollisionEventsWithBoundary(t *testing.T) {
+	s, err := movecollide.NewSystem(200, 200)
+
+	require.NoError(t, err)
+
+	mover := &handlerMover{testMover: newTestMover(t, 180, 50, 10, 10)}
+
+	s.Add("mover", mover)
+
+	mover.Velocity = topdown.Vec(40, 0)
+
+	s.MoveCollide(1)
+
+	require.Len(t, mover.Events, 1)
+	assert.Equal(t, "east", mover.Events[0].OtherID)
+	assert.Nil(t, mover.Events[0].Other)
+	assert.InDelta(t, 195, mover.Position.X, 0.01)
+}
+
+func TestCollisionEventsSlidingIntoCorner(t *testing.T) {
+	s := newWholeMoveSystem(t)
+
+	mover := &handlerMover{testMover: newTestMover(t, 50, 50, 10, 10)}
+	side := &handlerMover{testMover: newTestMover(t, 70, 50, 10, 60)}
+	floor := &handlerMover{testMover: newTestMover(t, 50, 70, 60, 10)}
+	heard := []string{}
+
+	s.Add("mover", mover)
+	s.Add("side", side)
+	s.Add("floor", floor)
+
+	s.AddCollisionListener("test", movecollide.CollisionListenerFunc(func(e *movecollide.CollisionEvent) {
+		heard = append(heard, e.OtherID)
+	}))
+
+	// hits the side first, then slides down into the floor
+	mover.Resolver = movecollide.NewSlideResolver()
+	mover.Velocity = topdown.Vec(20, 15)
+
+	s.MoveCollide(1)
+
+	assert.InDelta(t, 60, mover.Position.X, 0.01)
+	assert.InDelta(t, 60, mover.Position.Y, 0.01)
+	assert.Equal(t, []string{"side", "floor"}, heard)
+	require.Len(t, mover.Events, 2)
+	require.Len(t, side.Events, 1)
+	require.Len(t, floor.Events, 1)
+	assert.InDelta(t, -1, mover.Events[1].Normal.Y, 1e-6)
+	assert.InDelta(t, 1, floor.Events[0].Normal.Y, 1e-6)
+}
+
+func TestCollisionEventsWhenPushedIntoWall(t *testing.T) {
+	s := newWholeMoveSystem(t)
+
+	mover := &massiveMover{testMover: newTestMover(t, 50, 50, 10, 10), mass: 2}
+	crate := &handlerMover{testMover: newTestMover(t, 70, 50, 10, 10)}
+	wall := &handlerMover{testMover: newTestMover(t, 90, 50, 10, 50)}
+	heard := []*movecollide.CollisionEvent{}
+
+	s.Add("mover", mover)
+	s.Add("crate", crate)
+	s.Add("wall", wall)
+
+	s.AddCollisionListener("test", movecollide.CollisionListenerFunc(func(e *movecollide.CollisionEvent) {
+		heard = append(heard, e)
+	}))
+
+	mover.Resolver = movecollide.NewPushResolver()
+	mover.Velocity = topdown.Vec(30, 0)
+
+	s.MoveCollide(1)
+
+	assert.InDelta(t, 80, crate.Position.X, 0.01)
+	require.Len(t, crate.Events, 2)
+	assert.Equal(t, "mover", crate.Events[0].OtherID)
+	assert.Equal(t, "wall", crate.Events[1].OtherID)
+	assert.InDelta(t, 30, crate.Events[1].RelativeVelocity.X, 1e-6)
+	require.Len(t, wall.Events, 1)
+	assert.Equal(t, "crate", wall.Events[0].OtherID)
+	assert.Len(t, heard, 2)
+}
+
+func (m *handlerMover) HandleCollision(e *movecollide.CollisionEvent) {
+	m.Events = append(m.Events, e)
+}
@@ -0,0 +1,205 @@
+package movecollide
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Layer is a set of collision layer bit flags. A shape belongs to a
+// layer, and has a mask made of the layers it collides with.
+type Layer int32
+
+// Layered is an optional interface for a collidable to pick its collision
+// layer and the layers it collides with. Otherwise, LayerDefault and
+// LayerAll are used. Collision checks are made from the perspective of
+// the moving collidable, using its mask and the layer of the other shape.
+type Layered interface {
+	CollisionLayer() Layer
+	CollisionMask() Layer
+}
+
+// TriggerLayered is an optional interface for a triggerable to pick the
+// layers its trigger shape can detect. Otherwise, LayerAll is used.
+type TriggerLayered interface {
+	TriggerMask() Layer
+}
+
+const (
+	LayerNone Layer = 0
+	// LayerTrigger is reserved for trigger shapes.
+	LayerTrigger    Layer = 1 << 0
+	LayerDefault    Layer = 1 << 1
+	LayerPlayer     Layer = 1 << 2
+	LayerEnemy      Layer = 1 << 3
+	LayerProjectile Layer = 1 << 4
+	LayerWall       Layer = 1 << 5
+	LayerPickup     Layer = 1 << 6
+	// LayerAll includes all layers except for triggers.
+	LayerAll Layer = ^LayerTrigger
+
+	LayerNameAll = "all"
+
+	// MaxLayers is the number of layers that fit into a layer mask. The
+	// sign bit is left out, since a shape on a negative layer would never
+	// collide with anything.
+	MaxLayers = 31
+)
+
+var layerNames = []string{
+	"trigger",
+	"default",
+	"player",
+	"enemy",
+	"projectile",
+	"wall",
+	"pickup",
+}
+
+// LayerSchemaStr is the JSON schema for a layer or set of layers.
+const LayerSchemaStr = `{
+  "$id": "https://github.com/jamestunnell/topdown/layer.json",
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "Layer",
+  "description": "Collision layer name(s).",
+  "oneOf": [
+    {"type": "string", "minLength": 1},
+    {
+      "type": "array",
+      "items": {"type": "string", "minLength": 1}
+    }
+  ]
+}`
+
+// RegisterLayer adds a custom named layer and returns it. If the name is
+// already in use, the existing layer is returned.
+func RegisterLayer(name string) (Layer, error) {
+	if l, err := ParseLayer(name); err == nil {
+		return l, nil
+	}
+
+	if len(layerNames) == MaxLayers {
+		return LayerNone, fmt.Errorf("failed to register layer '%s': all %d layers are in use", name, MaxLayers)
+	}
+
+	layerNames = append(layerNames, name)
+
+	return Layer(1 << (len(layerNames) - 1)), nil
+}
+
+// ParseLayer gets the layer with the given name.
+func ParseLayer(name string) (Layer, error) {
+	if name == LayerNameAll {
+		return LayerAll, nil
+	}
+
+	for i, layerName := range layerNames {
+		if layerName == name {
+			return Layer(1 << i), nil
+		}
+	}
+
+	return LayerNone, fmt.Errorf("unknown layer '%s'", name)
+}
+
+// ParseLayers combines the layers with the given names.
+func ParseLayers(names ...string) (Layer, error) {
+	l := LayerNone
+
+	for _, name := range names {
+		layer, err := ParseLayer(name)
+		if err != nil {
+			return LayerNone, err
+		}
+
+		l |= layer
+	}
+
+	return l, nil
+}
+
+// Has checks if all of the given layers are included.
+func (l Layer) Has(other Layer) bool {
+	return l&other == other
+}
+
+// Names gets the names of the included layers.
+func (l Layer) Names() []string {
+	if l == LayerAll {
+		return []string{LayerNameAll}
+	}
+
+	names := []string{}
+
+	for i, name := range layerNames {
+		if l&Layer(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// String makes a string listing the included layer names.
+func (l Layer) String() string {
+	return strings.Join(l.Names(), "|")
+}
+
+// MarshalJSON makes a single layer name, or a list of names.
+func (l Layer) MarshalJSON() ([]byte, error) {
+	names := l.Names()
+
+	if len(names) == 1 {
+		return json.Marshal(names[0])
+	}
+
+	return json.Marshal(names)
+}
+
+// UnmarshalJSON parses a single layer name, or a list of names.
+func (l *Layer) UnmarshalJSON(d []byte) error {
+	var names []string
+
+	if err := json.Unmarshal(d, &names); err != nil {
+		var name string
+
+		if err = json.Unmarshal(d, &name); err != nil {
+			return fmt.Errorf("layer is not a string or list of strings: %w", err)
+		}
+
+		names = []string{name}
+	}
+
+	layer, err := ParseLayers(names...)
+	if err != nil {
+		return err
+	}
+
+	*l = layer
+
+	return nil
+}
+
+func colliderTags(x any) (Layer, Layer) {
+	layer := LayerDefault
+	mask := LayerAll
+
+	if l, ok := x.(Layered); ok {
+		layer = l.CollisionLayer()
+		mask = l.CollisionMask()
+	}
+
+	// colliders never collide with triggers
+	return layer &^ LayerTrigger, mask &^ LayerTrigger
+}
+
+func triggerTags(x any) (Layer, Layer) {
+	mask := LayerAll
+
+	if l, ok := x.(TriggerLayered); ok {
+		mask = l.TriggerMask()
+	}
+
+	// triggers don't detect other triggers
+	return LayerTrigger, mask &^ LayerTrigger
+}
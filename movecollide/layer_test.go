@@ -0,0 +1,191 @@
+package movecollide_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xeipuuv/gojsonschema"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/movecollide"
+	"github.com/jamestunnell/topdown/resource"
+)
+
+type layeredMover struct {
+	*testMover
+
+	Layer, Mask movecollide.Layer
+}
+
+func TestParseLayers(t *testing.T) {
+	l, err := movecollide.ParseLayers("player", "wall")
+
+	require.NoError(t, err)
+
+	assert.True(t, l.Has(movecollide.LayerPlayer))
+	assert.True(t, l.Has(movecollide.LayerWall))
+	assert.False(t, l.Has(movecollide.LayerEnemy))
+	assert.Equal(t, []string{"player", "wall"}, l.Names())
+
+	_, err = movecollide.ParseLayers("player", "unknown")
+
+	assert.Error(t, err)
+
+	l, err = movecollide.ParseLayer(movecollide.LayerNameAll)
+
+	require.NoError(t, err)
+	assert.Equal(t, movecollide.LayerAll, l)
+}
+
+func TestRegisterLayer(t *testing.T) {
+	l, err := movecollide.RegisterLayer("water")
+
+	require.NoError(t, err)
+
+	l2, err := movecollide.ParseLayer("water")
+
+	require.NoError(t, err)
+	assert.Equal(t, l, l2)
+
+	// registering again gives the same layer
+	l3, err := movecollide.RegisterLayer("water")
+
+	require.NoError(t, err)
+	assert.Equal(t, l, l3)
+
+	// built-in names are not re-registered
+	l4, err := movecollide.RegisterLayer("enemy")
+
+	require.NoError(t, err)
+	assert.Equal(t, movecollide.LayerEnemy, l4)
+}
+
+func TestRegisterLayerLimit(t *testing.T) {
+	var (
+		last movecollide.Layer
+		err  error
+	)
+
+	for i := 0; err == nil; i++ {
+		var l movecollide.Layer
+
+		if l, err = movecollide.RegisterLayer(fmt.Sprintf("custom%d", i)); err == nil {
+			last = l
+		}
+	}
+
+	assert.Equal(t, movecollide.Layer(1<<(movecollide.MaxLayers-1)), last)
+
+	// the last layer still collides
+	s, err := movecollide.NewSystem(300, 300)
+
+	require.NoError(t, err)
+
+	mover := &layeredMover{testMover: newTestMover(t, 50, 50, 10, 10), Layer: last, Mask: last}
+	wall := &layeredMover{testMover: newTestMover(t, 100, 50, 10, 10), Layer: last, Mask: last}
+
+	s.Add("mover", mover)
+	s.Add("wall", wall)
+
+	mover.Velocity = topdown.Vec(60, 0)
+
+	s.MoveCollide(1)
+
+	assert.InDelta(t, 90, mover.Position.X, 0.01)
+}
+
+func TestLayerJSON(t *testing.T) {
+	var val struct {
+		Layer movecollide.Layer `json:"layer"`
+		Mask  movecollide.Layer `json:"mask"`
+	}
+
+	d := []byte(`{"layer": "enemy", "mask": ["player", "projectile"]}`)
+
+	require.NoError(t, json.Unmarshal(d, &val))
+
+	assert.Equal(t, movecollide.LayerEnemy, val.Layer)
+	assert.Equal(t, movecollide.LayerPlayer|movecollide.LayerProjectile, val.Mask)
+
+	d2, err := json.Marshal(val)
+
+	require.NoError(t, err)
+	assert.JSONEq(t, string(d), string(d2))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"layer": 5}`), &val))
+	assert.Error(t, json.Unmarshal([]byte(`{"layer": "unknown"}`), &val))
+}
+
+func TestLayerSchema(t *testing.T) {
+	schema, err := resource.MakeJSONSchema(movecollide.LayerSchemaStr)
+
+	require.NoError(t, err)
+
+	valid := []string{`"player"`, `["player", "wall"]`, `"all"`}
+	invalid := []string{`5`, `""`, `[""]`, `{"layer": "player"}`}
+
+	for _, str := range valid {
+		result, err := schema.Validate(gojsonschema.NewStringLoader(str))
+
+		require.NoError(t, err)
+		assert.True(t, result.Valid(), str)
+	}
+
+	for _, str := range invalid {
+		result, err := schema.Validate(gojsonschema.NewStringLoader(str))
+
+		require.NoError(t, err)
+		assert.False(t, result.Valid(), str)
+	}
+}
+
+func TestLayerMasks(t *testing.T) {
+	s, err := movecollide.NewSystem(300, 300)
+
+	require.NoError(t, err)
+
+	player := &layeredMover{
+		testMover: newTestMover(t, 50, 50, 10, 10),
+		Layer:     movecollide.LayerPlayer,
+		Mask:      movecollide.LayerWall,
+	}
+	pickup := &layeredMover{
+		testMover: newTestMover(t, 80, 50, 10, 10),
+		Layer:     movecollide.LayerPickup,
+		Mask:      movecollide.LayerNone,
+	}
+	wall := &layeredMover{
+		testMover: newTestMover(t, 200, 50, 10, 10),
+		Layer:     movecollide.LayerWall,
+		Mask:      movecollide.LayerAll,
+	}
+
+	s.Add("player", player)
+	s.Add("pickup", pickup)
+	s.Add("wall", wall)
+
+	// passes through the pickup
+	player.Velocity = topdown.Vec(60, 0)
+
+	s.MoveCollide(1)
+
+	assert.Equal(t, topdown.Vec(110, 50), player.Position)
+
+	// stopped at the wall
+	player.Velocity = topdown.Vec(90, 0)
+
+	s.MoveCollide(1)
+
+	assert.InDelta(t, 190, player.Position.X, 0.01)
+}
+
+func (m *layeredMover) CollisionLayer() movecollide.Layer {
+	return m.Layer
+}
+
+func (m *layeredMover) CollisionMask() movecollide.Layer {
+	return m.Mask
+}
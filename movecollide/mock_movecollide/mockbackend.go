@@ -0,0 +1,123 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/jamestunnell/topdown/movecollide (interfaces: Backend)
+
+// Package mock_movecollide is a generated GoMock package.
+package mock_movecollide
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	cirno "github.com/zergon321/cirno"
+)
+
+// MockBackend is a mock of Backend interface.
+type MockBackend struct {
+	ctrl     *gomock.Controller
+	recorder *MockBackendMockRecorder
+}
+
+// MockBackendMockRecorder is the mock recorder for MockBackend.
+type MockBackendMockRecorder struct {
+	mock *MockBackend
+}
+
+// NewMockBackend creates a new mock instance.
+func NewMockBackend(ctrl *gomock.Controller) *MockBackend {
+	mock := &MockBackend{ctrl: ctrl}
+	mock.recorder = &MockBackendMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBackend) EXPECT() *MockBackendMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockBackend) Add(arg0 cirno.Shape) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockBackendMockRecorder) Add(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockBackend)(nil).Add), arg0)
+}
+
+// Bounds mocks base method.
+func (m *MockBackend) Bounds() (cirno.Vector, cirno.Vector, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Bounds")
+	ret0, _ := ret[0].(cirno.Vector)
+	ret1, _ := ret[1].(cirno.Vector)
+	ret2, _ := ret[2].(bool)
+	return ret0, ret1, ret2
+}
+
+// Bounds indicates an expected call of Bounds.
+func (mr *MockBackendMockRecorder) Bounds() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Bounds", reflect.TypeOf((*MockBackend)(nil).Bounds))
+}
+
+// Collide mocks base method.
+func (m *MockBackend) Collide(arg0, arg1 cirno.Shape) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Collide", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Collide indicates an expected call of Collide.
+func (mr *MockBackendMockRecorder) Collide(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Collide", reflect.TypeOf((*MockBackend)(nil).Collide), arg0, arg1)
+}
+
+// Query mocks base method.
+func (m *MockBackend) Query(arg0, arg1 cirno.Vector) (cirno.Shapes, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Query", arg0, arg1)
+	ret0, _ := ret[0].(cirno.Shapes)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Query indicates an expected call of Query.
+func (mr *MockBackendMockRecorder) Query(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockBackend)(nil).Query), arg0, arg1)
+}
+
+// Remove mocks base method.
+func (m *MockBackend) Remove(arg0 cirno.Shape) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Remove", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Remove indicates an expected call of Remove.
+func (mr *MockBackendMockRecorder) Remove(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockBackend)(nil).Remove), arg0)
+}
+
+// Update mocks base method.
+func (m *MockBackend) Update(arg0 cirno.Shape) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockBackendMockRecorder) Update(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockBackend)(nil).Update), arg0)
+}
@@ -8,6 +8,7 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	movecollide "github.com/jamestunnell/topdown/movecollide"
 	cirno "github.com/zergon321/cirno"
 )
 
@@ -48,16 +49,16 @@ func (mr *MockCollidableMockRecorder) ColliderShape() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ColliderShape", reflect.TypeOf((*MockCollidable)(nil).ColliderShape))
 }
 
-// ResolveCollision mocks base method.
-func (m *MockCollidable) ResolveCollision(arg0 cirno.Vector, arg1 cirno.Shapes) cirno.Vector {
+// CollisionResolver mocks base method.
+func (m *MockCollidable) CollisionResolver() movecollide.Resolver {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ResolveCollision", arg0, arg1)
-	ret0, _ := ret[0].(cirno.Vector)
+	ret := m.ctrl.Call(m, "CollisionResolver")
+	ret0, _ := ret[0].(movecollide.Resolver)
 	return ret0
 }
 
-// ResolveCollision indicates an expected call of ResolveCollision.
-func (mr *MockCollidableMockRecorder) ResolveCollision(arg0, arg1 interface{}) *gomock.Call {
+// CollisionResolver indicates an expected call of CollisionResolver.
+func (mr *MockCollidableMockRecorder) CollisionResolver() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveCollision", reflect.TypeOf((*MockCollidable)(nil).ResolveCollision), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CollisionResolver", reflect.TypeOf((*MockCollidable)(nil).CollisionResolver))
 }
@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/jamestunnell/topdown/movecollide (interfaces: Resolver)
+
+// Package mock_movecollide is a generated GoMock package.
+package mock_movecollide
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	movecollide "github.com/jamestunnell/topdown/movecollide"
+	cirno "github.com/zergon321/cirno"
+)
+
+// MockResolver is a mock of Resolver interface.
+type MockResolver struct {
+	ctrl     *gomock.Controller
+	recorder *MockResolverMockRecorder
+}
+
+// MockResolverMockRecorder is the mock recorder for MockResolver.
+type MockResolverMockRecorder struct {
+	mock *MockResolver
+}
+
+// NewMockResolver creates a new mock instance.
+func NewMockResolver(ctrl *gomock.Controller) *MockResolver {
+	mock := &MockResolver{ctrl: ctrl}
+	mock.recorder = &MockResolverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockResolver) EXPECT() *MockResolverMockRecorder {
+	return m.recorder
+}
+
+// Resolve mocks base method.
+func (m *MockResolver) Resolve(arg0 *movecollide.Collision) cirno.Vector {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Resolve", arg0)
+	ret0, _ := ret[0].(cirno.Vector)
+	return ret0
+}
+
+// Resolve indicates an expected call of Resolve.
+func (mr *MockResolverMockRecorder) Resolve(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resolve", reflect.TypeOf((*MockResolver)(nil).Resolve), arg0)
+}
@@ -0,0 +1,49 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/jamestunnell/topdown/movecollide (interfaces: StaticCollidable)
+
+// Package mock_movecollide is a generated GoMock package.
+package mock_movecollide
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	cirno "github.com/zergon321/cirno"
+)
+
+// MockStaticCollidable is a mock of StaticCollidable interface.
+type MockStaticCollidable struct {
+	ctrl     *gomock.Controller
+	recorder *MockStaticCollidableMockRecorder
+}
+
+// MockStaticCollidableMockRecorder is the mock recorder for MockStaticCollidable.
+type MockStaticCollidableMockRecorder struct {
+	mock *MockStaticCollidable
+}
+
+// NewMockStaticCollidable creates a new mock instance.
+func NewMockStaticCollidable(ctrl *gomock.Controller) *MockStaticCollidable {
+	mock := &MockStaticCollidable{ctrl: ctrl}
+	mock.recorder = &MockStaticCollidableMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStaticCollidable) EXPECT() *MockStaticCollidableMockRecorder {
+	return m.recorder
+}
+
+// StaticColliderShapes mocks base method.
+func (m *MockStaticCollidable) StaticColliderShapes() []cirno.Shape {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StaticColliderShapes")
+	ret0, _ := ret[0].([]cirno.Shape)
+	return ret0
+}
+
+// StaticColliderShapes indicates an expected call of StaticColliderShapes.
+func (mr *MockStaticCollidableMockRecorder) StaticColliderShapes() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StaticColliderShapes", reflect.TypeOf((*MockStaticCollidable)(nil).StaticColliderShapes))
+}
@@ -8,6 +8,7 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	topdown "github.com/jamestunnell/topdown"
 	movecollide "github.com/jamestunnell/topdown/movecollide"
 )
 
@@ -46,6 +47,60 @@ func (mr *MockSystemMockRecorder) Add(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockSystem)(nil).Add), arg0, arg1)
 }
 
+// AddCollisionListener mocks base method.
+func (m *MockSystem) AddCollisionListener(arg0 string, arg1 movecollide.CollisionListener) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AddCollisionListener", arg0, arg1)
+}
+
+// AddCollisionListener indicates an expected call of AddCollisionListener.
+func (mr *MockSystemMockRecorder) AddCollisionListener(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCollisionListener", reflect.TypeOf((*MockSystem)(nil).AddCollisionListener), arg0, arg1)
+}
+
+// Boxcast mocks base method.
+func (m *MockSystem) Boxcast(arg0 *movecollide.BoxCast) (*movecollide.RayHit, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Boxcast", arg0)
+	ret0, _ := ret[0].(*movecollide.RayHit)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// Boxcast indicates an expected call of Boxcast.
+func (mr *MockSystemMockRecorder) Boxcast(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Boxcast", reflect.TypeOf((*MockSystem)(nil).Boxcast), arg0)
+}
+
+// Circlecast mocks base method.
+func (m *MockSystem) Circlecast(arg0 *movecollide.CircleCast) (*movecollide.RayHit, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Circlecast", arg0)
+	ret0, _ := ret[0].(*movecollide.RayHit)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// Circlecast indicates an expected call of Circlecast.
+func (mr *MockSystemMockRecorder) Circlecast(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Circlecast", reflect.TypeOf((*MockSystem)(nil).Circlecast), arg0)
+}
+
+// Clear mocks base method.
+func (m *MockSystem) Clear() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Clear")
+}
+
+// Clear indicates an expected call of Clear.
+func (mr *MockSystemMockRecorder) Clear() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Clear", reflect.TypeOf((*MockSystem)(nil).Clear))
+}
+
 // MoveCollide mocks base method.
 func (m *MockSystem) MoveCollide(arg0 float64) {
 	m.ctrl.T.Helper()
@@ -58,6 +113,49 @@ func (mr *MockSystemMockRecorder) MoveCollide(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveCollide", reflect.TypeOf((*MockSystem)(nil).MoveCollide), arg0)
 }
 
+// Nearest mocks base method.
+func (m *MockSystem) Nearest(arg0 topdown.Point[float64], arg1 float64, arg2 movecollide.Layer) (*movecollide.Overlap, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Nearest", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*movecollide.Overlap)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// Nearest indicates an expected call of Nearest.
+func (mr *MockSystemMockRecorder) Nearest(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Nearest", reflect.TypeOf((*MockSystem)(nil).Nearest), arg0, arg1, arg2)
+}
+
+// OverlapCircle mocks base method.
+func (m *MockSystem) OverlapCircle(arg0 topdown.Point[float64], arg1 float64, arg2 movecollide.Layer) []*movecollide.Overlap {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OverlapCircle", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*movecollide.Overlap)
+	return ret0
+}
+
+// OverlapCircle indicates an expected call of OverlapCircle.
+func (mr *MockSystemMockRecorder) OverlapCircle(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OverlapCircle", reflect.TypeOf((*MockSystem)(nil).OverlapCircle), arg0, arg1, arg2)
+}
+
+// OverlapRect mocks base method.
+func (m *MockSystem) OverlapRect(arg0 topdown.Rectangle[float64], arg1 movecollide.Layer) []*movecollide.Overlap {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OverlapRect", arg0, arg1)
+	ret0, _ := ret[0].([]*movecollide.Overlap)
+	return ret0
+}
+
+// OverlapRect indicates an expected call of OverlapRect.
+func (mr *MockSystemMockRecorder) OverlapRect(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OverlapRect", reflect.TypeOf((*MockSystem)(nil).OverlapRect), arg0, arg1)
+}
+
 // Raycast mocks base method.
 func (m *MockSystem) Raycast(arg0 *movecollide.Ray) (*movecollide.RayHit, bool) {
 	m.ctrl.T.Helper()
@@ -72,3 +170,96 @@ func (mr *MockSystemMockRecorder) Raycast(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Raycast", reflect.TypeOf((*MockSystem)(nil).Raycast), arg0)
 }
+
+// RaycastAll mocks base method.
+func (m *MockSystem) RaycastAll(arg0 *movecollide.Ray) []*movecollide.RayHit {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RaycastAll", arg0)
+	ret0, _ := ret[0].([]*movecollide.RayHit)
+	return ret0
+}
+
+// RaycastAll indicates an expected call of RaycastAll.
+func (mr *MockSystemMockRecorder) RaycastAll(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RaycastAll", reflect.TypeOf((*MockSystem)(nil).RaycastAll), arg0)
+}
+
+// Remove mocks base method.
+func (m *MockSystem) Remove(arg0 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Remove", arg0)
+}
+
+// Remove indicates an expected call of Remove.
+func (mr *MockSystemMockRecorder) Remove(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockSystem)(nil).Remove), arg0)
+}
+
+// RemoveCollisionListener mocks base method.
+func (m *MockSystem) RemoveCollisionListener(arg0 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RemoveCollisionListener", arg0)
+}
+
+// RemoveCollisionListener indicates an expected call of RemoveCollisionListener.
+func (mr *MockSystemMockRecorder) RemoveCollisionListener(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveCollisionListener", reflect.TypeOf((*MockSystem)(nil).RemoveCollisionListener), arg0)
+}
+
+// SetMaxStepFraction mocks base method.
+func (m *MockSystem) SetMaxStepFraction(arg0 float64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetMaxStepFraction", arg0)
+}
+
+// SetMaxStepFraction indicates an expected call of SetMaxStepFraction.
+func (mr *MockSystemMockRecorder) SetMaxStepFraction(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMaxStepFraction", reflect.TypeOf((*MockSystem)(nil).SetMaxStepFraction), arg0)
+}
+
+// SurfaceAt mocks base method.
+func (m *MockSystem) SurfaceAt(arg0 topdown.Point[float64]) (*movecollide.Surface, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SurfaceAt", arg0)
+	ret0, _ := ret[0].(*movecollide.Surface)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// SurfaceAt indicates an expected call of SurfaceAt.
+func (mr *MockSystemMockRecorder) SurfaceAt(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SurfaceAt", reflect.TypeOf((*MockSystem)(nil).SurfaceAt), arg0)
+}
+
+// Teleport mocks base method.
+func (m *MockSystem) Teleport(arg0 string, arg1 topdown.Point[float64]) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Teleport", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Teleport indicates an expected call of Teleport.
+func (mr *MockSystemMockRecorder) Teleport(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Teleport", reflect.TypeOf((*MockSystem)(nil).Teleport), arg0, arg1)
+}
+
+// UpdateShapes mocks base method.
+func (m *MockSystem) UpdateShapes(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateShapes", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateShapes indicates an expected call of UpdateShapes.
+func (mr *MockSystemMockRecorder) UpdateShapes(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateShapes", reflect.TypeOf((*MockSystem)(nil).UpdateShapes), arg0)
+}
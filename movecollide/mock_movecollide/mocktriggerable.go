@@ -8,6 +8,7 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	movecollide "github.com/jamestunnell/topdown/movecollide"
 	cirno "github.com/zergon321/cirno"
 )
 
@@ -35,7 +36,7 @@ func (m *MockTriggerable) EXPECT() *MockTriggerableMockRecorder {
 }
 
 // TriggerEnter mocks base method.
-func (m *MockTriggerable) TriggerEnter(arg0 cirno.Shape) {
+func (m *MockTriggerable) TriggerEnter(arg0 *movecollide.Trigger) {
 	m.ctrl.T.Helper()
 	m.ctrl.Call(m, "TriggerEnter", arg0)
 }
@@ -47,7 +48,7 @@ func (mr *MockTriggerableMockRecorder) TriggerEnter(arg0 interface{}) *gomock.Ca
 }
 
 // TriggerExit mocks base method.
-func (m *MockTriggerable) TriggerExit(arg0 cirno.Shape) {
+func (m *MockTriggerable) TriggerExit(arg0 *movecollide.Trigger) {
 	m.ctrl.T.Helper()
 	m.ctrl.Call(m, "TriggerExit", arg0)
 }
@@ -59,7 +60,7 @@ func (mr *MockTriggerableMockRecorder) TriggerExit(arg0 interface{}) *gomock.Cal
 }
 
 // TriggerRemain mocks base method.
-func (m *MockTriggerable) TriggerRemain(arg0 cirno.Shape) {
+func (m *MockTriggerable) TriggerRemain(arg0 *movecollide.Trigger) {
 	m.ctrl.T.Helper()
 	m.ctrl.Call(m, "TriggerRemain", arg0)
 }
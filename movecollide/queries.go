@@ -0,0 +1,320 @@
+package movecollide
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/rs/zerolog/log"
+	"github.com/zergon321/cirno"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+type Ray struct {
+	Origin, Direction cirno.Vector
+	// Distance limits how far the ray goes. The ray crosses the whole
+	// world if the distance is not positive.
+	Distance float64
+	// Mask limits the layers the ray can hit. All layers can be hit if
+	// the mask is LayerNone.
+	Mask Layer
+}
+
+// CircleCast is a circle moved along a ray.
+type CircleCast struct {
+	Ray
+	Radius float64
+}
+
+// BoxCast is an axis-aligned box moved along a ray.
+type BoxCast struct {
+	Ray
+	Width, Height float64
+}
+
+// RayHit is an entity hit by a ray or a cast shape.
+type RayHit struct {
+	ID       string
+	Position cirno.Vector
+	Object   any
+	// Distance is how far along the ray the hit was made.
+	Distance float64
+	// Normal is the unit surface normal at the hit, for shape casts.
+	Normal cirno.Vector
+}
+
+// Overlap is an entity found by an area query.
+type Overlap struct {
+	ID     string
+	Object any
+}
+
+func (s *system) Raycast(r *Ray) (*RayHit, bool) {
+	hits := s.RaycastAll(r)
+	if len(hits) == 0 {
+		return nil, false
+	}
+
+	return hits[0], true
+}
+
+func (s *system) RaycastAll(r *Ray) []*RayHit {
+	dir, end, err := s.rayEnd(r)
+	if err != nil {
+		log.Warn().Err(err).Msg("raycast failed")
+
+		return []*RayHit{}
+	}
+
+	line, err := cirno.NewLine(r.Origin, end)
+	if err != nil {
+		log.Warn().Err(err).Msg("raycast failed")
+
+		return []*RayHit{}
+	}
+
+	shapes, err := s.overlapping(line, queryMask(r.Mask))
+	if err != nil {
+		log.Warn().Err(err).Msg("raycast failed")
+
+		return []*RayHit{}
+	}
+
+	nearest := map[string]*RayHit{}
+
+	for shape := range shapes {
+		id, obj, ok := s.entity(shape)
+
+		// a ray can't hit a shape that it starts within
+		if !ok || shape.ContainsPoint(r.Origin) {
+			continue
+		}
+
+		contacts, err := cirno.Contact(line, shape)
+		if err != nil {
+			log.Warn().Err(err).Str("id", id).Msg("failed to find ray contact")
+
+			continue
+		}
+
+		for _, contact := range contacts {
+			dist := cirno.Dot(contact.Subtract(r.Origin), dir)
+
+			if hit, found := nearest[id]; !found || dist < hit.Distance {
+				nearest[id] = &RayHit{ID: id, Position: contact, Object: obj, Distance: dist}
+			}
+		}
+	}
+
+	hits := maps.Values(nearest)
+
+	slices.SortFunc(hits, func(a, b *RayHit) bool {
+		if a.Distance == b.Distance {
+			return a.ID < b.ID
+		}
+
+		return a.Distance < b.Distance
+	})
+
+	return hits
+}
+
+func (s *system) Circlecast(c *CircleCast) (*RayHit, bool) {
+	circle, err := cirno.NewCircle(c.Origin, c.Radius)
+	if err != nil {
+		log.Warn().Err(err).Msg("circlecast failed")
+
+		return nil, false
+	}
+
+	return s.shapecast(circle, &c.Ray)
+}
+
+func (s *system) Boxcast(b *BoxCast) (*RayHit, bool) {
+	rect, err := cirno.NewRectangle(b.Origin, b.Width, b.Height, 0)
+	if err != nil {
+		log.Warn().Err(err).Msg("boxcast failed")
+
+		return nil, false
+	}
+
+	return s.shapecast(rect, &b.Ray)
+}
+
+func (s *system) OverlapRect(area topdown.Rectangle[float64], mask Layer) []*Overlap {
+	rect, err := boundsRect(
+		cirno.NewVector(area.Min.X, area.Min.Y),
+		cirno.NewVector(area.Max.X, area.Max.Y))
+	if err != nil {
+		log.Warn().Err(err).Msg("rect overlap query failed")
+
+		return []*Overlap{}
+	}
+
+	return s.overlaps(rect, mask)
+}
+
+func (s *system) OverlapCircle(center topdown.Point[float64], radius float64, mask Layer) []*Overlap {
+	circle, err := cirno.NewCircle(cirno.NewVector(center.X, center.Y), radius)
+	if err != nil {
+		log.Warn().Err(err).Msg("circle overlap query failed")
+
+		return []*Overlap{}
+	}
+
+	return s.overlaps(circle, mask)
+}
+
+func (s *system) Nearest(pos topdown.Point[float64], radius float64, layers Layer) (*Overlap, bool) {
+	var nearest *Overlap
+
+	minDist := math.Inf(1)
+	center := cirno.NewVector(pos.X, pos.Y)
+	mask := queryMask(layers)
+
+	// overlaps are sorted by ID, so ties go to the first ID
+	for _, o := range s.OverlapCircle(pos, radius, layers) {
+		// static collidables have only static shapes, and an entity can
+		// have shapes in layers that are not searched
+		for _, shape := range s.entityShapes(o.ID) {
+			if Layer(shape.GetIdentity())&mask == 0 {
+				continue
+			}
+
+			if dist := pointDistance(shape, center); dist < minDist {
+				nearest = o
+				minDist = dist
+			}
+		}
+	}
+
+	return nearest, nearest != nil
+}
+
+// shapecast moves a shape along a ray to find the first entity hit.
+func (s *system) shapecast(shape cirno.Shape, r *Ray) (*RayHit, bool) {
+	dir, end, err := s.rayEnd(r)
+	if err != nil {
+		log.Warn().Err(err).Msg("shape cast failed")
+
+		return nil, false
+	}
+
+	shape.SetIdentity(int32(LayerNone))
+	shape.SetMask(int32(queryMask(r.Mask)))
+
+	if err := s.backend.Add(shape); err != nil {
+		log.Warn().Err(err).Msg("shape cast failed")
+
+		return nil, false
+	}
+
+	defer s.backend.Remove(shape)
+
+	contact, err := s.sweep(shape, cirno.Zero(), end.Subtract(r.Origin))
+	if err != nil {
+		log.Warn().Err(err).Msg("shape cast failed")
+
+		return nil, false
+	}
+
+	if contact == nil || contact.Object == nil {
+		return nil, false
+	}
+
+	dist := cirno.Distance(r.Origin, end) * contact.Fraction
+
+	hit := &RayHit{
+		ID:       contact.ID,
+		Position: r.Origin.Add(dir.MultiplyByScalar(dist)),
+		Object:   contact.Object,
+		Distance: dist,
+		Normal:   contact.Normal,
+	}
+
+	return hit, true
+}
+
+// overlaps finds the entities overlapping a query shape, sorted by ID.
+func (s *system) overlaps(query cirno.Shape, mask Layer) []*Overlap {
+	shapes, err := s.overlapping(query, queryMask(mask))
+	if err != nil {
+		log.Warn().Err(err).Msg("overlap query failed")
+
+		return []*Overlap{}
+	}
+
+	found := map[string]*Overlap{}
+
+	for shape := range shapes {
+		if id, obj, ok := s.entity(shape); ok {
+			found[id] = &Overlap{ID: id, Object: obj}
+		}
+	}
+
+	overlaps := maps.Values(found)
+
+	slices.SortFunc(overlaps, func(a, b *Overlap) bool {
+		return a.ID < b.ID
+	})
+
+	return overlaps
+}
+
+// overlapping finds the shapes overlapping a query shape that is not in
+// the collision backend. The query shape is given no identity, so that
+// nothing can collide with it.
+func (s *system) overlapping(query cirno.Shape, mask Layer) (cirno.Shapes, error) {
+	query.SetIdentity(int32(LayerNone))
+	query.SetMask(int32(mask))
+
+	shapes, err := collidedBy(s.backend, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find overlapping shapes: %w", err)
+	}
+
+	return shapes, nil
+}
+
+// entity gets the ID and object of the entity a shape belongs to.
+// Returns false for shapes that don't belong to an entity, like boundaries.
+func (s *system) entity(shape cirno.Shape) (string, any, bool) {
+	id, ok := shape.Data().(string)
+	if !ok {
+		return "", nil, false
+	}
+
+	obj, found := s.objects.Get(id)
+
+	return id, obj, found
+}
+
+// rayEnd gets the normalized ray direction and the end point of the ray.
+func (s *system) rayEnd(r *Ray) (cirno.Vector, cirno.Vector, error) {
+	dir, err := r.Direction.Normalize()
+	if err != nil {
+		return cirno.Zero(), cirno.Zero(), fmt.Errorf("invalid ray direction: %w", err)
+	}
+
+	dist := r.Distance
+	if dist <= 0 {
+		// far enough to reach any corner of the world
+		min, max, _ := s.backend.Bounds()
+
+		for _, corner := range []cirno.Vector{min, max, cirno.NewVector(min.X, max.Y), cirno.NewVector(max.X, min.Y)} {
+			dist = math.Max(dist, cirno.Distance(r.Origin, corner))
+		}
+	}
+
+	return dir, r.Origin.Add(dir.MultiplyByScalar(dist)), nil
+}
+
+// queryMask uses all layers for an empty mask. Queries never find triggers.
+func queryMask(mask Layer) Layer {
+	if mask == LayerNone {
+		mask = LayerAll
+	}
+
+	return mask &^ LayerTrigger
+}
@@ -0,0 +1,223 @@
+package movecollide_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zergon321/cirno"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/movecollide"
+)
+
+func TestRaycast(t *testing.T) {
+	s, objs := newQueryWorld(t)
+
+	hit, found := s.Raycast(&movecollide.Ray{
+		Origin:    cirno.NewVector(10, 50),
+		Direction: cirno.NewVector(1, 0),
+	})
+
+	require.True(t, found)
+	assert.Equal(t, "enemy1", hit.ID)
+	assert.Equal(t, objs["enemy1"], hit.Object)
+	assert.InDelta(t, 45, hit.Position.X, 1e-6)
+	assert.InDelta(t, 35, hit.Distance, 1e-6)
+
+	// too short to reach
+	_, found = s.Raycast(&movecollide.Ray{
+		Origin:    cirno.NewVector(10, 50),
+		Direction: cirno.NewVector(1, 0),
+		Distance:  20,
+	})
+
+	assert.False(t, found)
+}
+
+func TestRaycastAll(t *testing.T) {
+	s, _ := newQueryWorld(t)
+
+	ray := &movecollide.Ray{
+		Origin:    cirno.NewVector(10, 50),
+		Direction: cirno.NewVector(1, 0),
+	}
+
+	assert.Equal(t, []string{"enemy1", "wall", "enemy2"}, rayHitIDs(s.RaycastAll(ray)))
+
+	ray.Mask = movecollide.LayerEnemy
+
+	assert.Equal(t, []string{"enemy1", "enemy2"}, rayHitIDs(s.RaycastAll(ray)))
+
+	// the zone trigger shape is never hit
+	ray.Origin = cirno.NewVector(10, 120)
+	ray.Mask = movecollide.LayerNone
+
+	assert.Empty(t, s.RaycastAll(ray))
+}
+
+func TestCirclecast(t *testing.T) {
+	s, _ := newQueryWorld(t)
+
+	// passes by enemy1 but hits the thin wall
+	hit, found := s.Circlecast(&movecollide.CircleCast{
+		Ray: movecollide.Ray{
+			Origin:    cirno.NewVector(10, 70),
+			Direction: cirno.NewVector(1, 0),
+		},
+		Radius: 5,
+	})
+
+	require.True(t, found)
+	assert.Equal(t, "wall", hit.ID)
+	assert.InDelta(t, 94, hit.Position.X, 0.01)
+	assert.InDelta(t, -1, hit.Normal.X, 1e-6)
+}
+
+func TestBoxcast(t *testing.T) {
+	s, _ := newQueryWorld(t)
+
+	hit, found := s.Boxcast(&movecollide.BoxCast{
+		Ray: movecollide.Ray{
+			Origin:    cirno.NewVector(10, 70),
+			Direction: cirno.NewVector(1, 0),
+			Mask:      movecollide.LayerEnemy,
+		},
+		Width:  10,
+		Height: 32,
+	})
+
+	require.True(t, found)
+	assert.Equal(t, "enemy1", hit.ID)
+	assert.InDelta(t, 40, hit.Position.X, 0.01)
+
+	_, found = s.Boxcast(&movecollide.BoxCast{
+		Ray: movecollide.Ray{
+			Origin:    cirno.NewVector(10, 150),
+			Direction: cirno.NewVector(1, 0),
+		},
+		Width:  10,
+		Height: 10,
+	})
+
+	assert.False(t, found)
+}
+
+func TestOverlapQueries(t *testing.T) {
+	s, objs := newQueryWorld(t)
+
+	overlaps := s.OverlapRect(topdown.Rect(0.0, 0.0, 110.0, 100.0), movecollide.LayerNone)
+
+	assert.Equal(t, []string{"enemy1", "wall"}, overlapIDs(overlaps))
+	assert.Equal(t, objs["enemy1"], overlaps[0].Object)
+
+	overlaps = s.OverlapRect(topdown.Rect(0.0, 0.0, 200.0, 100.0), movecollide.LayerEnemy)
+
+	assert.Equal(t, []string{"enemy1", "enemy2"}, overlapIDs(overlaps))
+
+	overlaps = s.OverlapCircle(topdown.Pt(100.0, 50.0), 60, movecollide.LayerAll)
+
+	assert.Equal(t, []string{"enemy1", "enemy2", "wall"}, overlapIDs(overlaps))
+
+	assert.Empty(t, s.OverlapCircle(topdown.Pt(100.0, 150.0), 10, movecollide.LayerNone))
+}
+
+func TestNearest(t *testing.T) {
+	s, objs := newQueryWorld(t)
+
+	nearest, found := s.Nearest(topdown.Pt(130.0, 50.0), 100, movecollide.LayerEnemy)
+
+	require.True(t, found)
+	assert.Equal(t, "enemy2", nearest.ID)
+	assert.Equal(t, objs["enemy2"], nearest.Object)
+
+	nearest, found = s.Nearest(topdown.Pt(60.0, 50.0), 100, movecollide.LayerEnemy)
+
+	require.True(t, found)
+	assert.Equal(t, "enemy1", nearest.ID)
+
+	_, found = s.Nearest(topdown.Pt(130.0, 150.0), 20, movecollide.LayerEnemy)
+
+	assert.False(t, found)
+}
+
+func TestNearestStaticWall(t *testing.T) {
+	s, err := movecollide.NewSystem(200, 200)
+
+	require.NoError(t, err)
+
+	// a row of wall tiles, with only static shapes
+	walls := &testStatic{Shapes: []cirno.Shape{
+		newTaggedRect(t, 45, 25, 10, 10),
+		newTaggedRect(t, 55, 25, 10, 10),
+		newTaggedRect(t, 65, 25, 10, 10),
+	}}
+
+	s.Add("walls", walls)
+	s.Add("mover", newTestMover(t, 60, 60, 10, 10))
+
+	// the corner of the last wall tile is closer than the mover
+	nearest, found := s.Nearest(topdown.Pt(75.0, 42.0), 50, movecollide.LayerNone)
+
+	require.True(t, found)
+	assert.Equal(t, "walls", nearest.ID)
+	assert.Equal(t, walls, nearest.Object)
+
+	nearest, found = s.Nearest(topdown.Pt(60.0, 75.0), 50, movecollide.LayerNone)
+
+	require.True(t, found)
+	assert.Equal(t, "mover", nearest.ID)
+}
+
+// newQueryWorld makes a world with two enemies on either side of a thin
+// wall, and a trigger zone.
+func newQueryWorld(t *testing.T) (movecollide.System, map[string]any) {
+	s, err := movecollide.NewSystem(200, 200)
+
+	require.NoError(t, err)
+
+	objs := map[string]any{
+		"enemy1": &layeredMover{
+			testMover: newTestMover(t, 50, 50, 10, 10),
+			Layer:     movecollide.LayerEnemy,
+			Mask:      movecollide.LayerAll,
+		},
+		"wall": &layeredMover{
+			testMover: newTestMover(t, 100, 50, 2, 80),
+			Layer:     movecollide.LayerWall,
+			Mask:      movecollide.LayerAll,
+		},
+		"enemy2": &layeredMover{
+			testMover: newTestMover(t, 150, 50, 10, 10),
+			Layer:     movecollide.LayerEnemy,
+			Mask:      movecollide.LayerAll,
+		},
+		"zone": newTestZone(t, 30, 120, 20, 20),
+	}
+
+	for id, obj := range objs {
+		s.Add(id, obj)
+	}
+
+	return s, objs
+}
+
+func rayHitIDs(hits []*movecollide.RayHit) []string {
+	ids := make([]string, len(hits))
+
+	for i, hit := range hits {
+		ids[i] = hit.ID
+	}
+
+	return ids
+}
+
+func overlapIDs(overlaps []*movecollide.Overlap) []string {
+	ids := make([]string, len(overlaps))
+
+	for i, o := range overlaps {
+		ids[i] = o.ID
+	}
+
+	return ids
+}
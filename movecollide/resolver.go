@@ -0,0 +1,258 @@
+package movecollide
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/zergon321/cirno"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+//go:generate mockgen -destination=mock_movecollide/mockresolver.go . Resolver
+
+// Resolver decides how a planned movement is adjusted when it would
+// collide with other shapes. Returns the movement to make instead.
+type Resolver interface {
+	Resolve(c *Collision) cirno.Vector
+}
+
+// ResolverFunc adapts a function to be used as a Resolver.
+type ResolverFunc func(c *Collision) cirno.Vector
+
+// Massive is an optional interface for an entity to give its mass, which
+// is used by the push resolver. Otherwise, DefaultMass is used.
+type Massive interface {
+	CollisionMass() float64
+}
+
+// Bounceable is an optional interface for a movable to be told that the
+// bounce resolver deflected its movement, so it can reflect its velocity.
+type Bounceable interface {
+	Bounce(normal topdown.Vector, restitution float64)
+}
+
+// ResolverSpec picks a resolver by type name, along with its parameters.
+// In JSON, it can be given as just the type name.
+type ResolverSpec struct {
+	Type string `json:"type"`
+	// Restitution is the fraction of movement kept after bouncing.
+	Restitution float64 `json:"restitution,omitempty"`
+}
+
+// MakeResolverFunc makes a resolver using the given spec.
+type MakeResolverFunc func(spec *ResolverSpec) (Resolver, error)
+
+const (
+	ResolverTypeStop   = "stop"
+	ResolverTypeSlide  = "slide"
+	ResolverTypeBounce = "bounce"
+	ResolverTypePush   = "push"
+
+	// DefaultMass is the mass of an entity that is not Massive.
+	DefaultMass = 1.0
+	// DeflectIterations is the most times a movement can be deflected
+	// in a single resolve, as when sliding into a corner.
+	DeflectIterations = 3
+)
+
+// ResolverSchemaStr is the JSON schema for a resolver spec.
+const ResolverSchemaStr = `{
+  "$id": "https://github.com/jamestunnell/topdown/resolver.json",
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "Resolver",
+  "description": "Collision resolver type, or type with parameters.",
+  "oneOf": [
+    {"type": "string", "minLength": 1},
+    {
+      "type": "object",
+      "properties": {
+        "type": {"type": "string", "minLength": 1},
+        "restitution": {"type": "number", "minimum": 0, "maximum": 1}
+      },
+      "required": ["type"]
+    }
+  ]
+}`
+
+var resolverMakers = map[string]MakeResolverFunc{
+	ResolverTypeStop: func(*ResolverSpec) (Resolver, error) {
+		return NewStopResolver(), nil
+	},
+	ResolverTypeSlide: func(*ResolverSpec) (Resolver, error) {
+		return NewSlideResolver(), nil
+	},
+	ResolverTypeBounce: func(spec *ResolverSpec) (Resolver, error) {
+		return NewBounceResolver(spec.Restitution), nil
+	},
+	ResolverTypePush: func(*ResolverSpec) (Resolver, error) {
+		return NewPushResolver(), nil
+	},
+}
+
+type stopResolver struct{}
+
+type slideResolver struct{}
+
+type bounceResolver struct {
+	restitution float64
+}
+
+type pushResolver struct{}
+
+// RegisterResolver adds a custom resolver type that can be picked by name.
+func RegisterResolver(typ string, makeResolver MakeResolverFunc) {
+	resolverMakers[typ] = makeResolver
+}
+
+// ResolverTypes gets the names of all the resolver types, sorted.
+func ResolverTypes() []string {
+	types := maps.Keys(resolverMakers)
+
+	slices.Sort(types)
+
+	return types
+}
+
+// NewResolver makes a resolver using the given spec.
+func NewResolver(spec *ResolverSpec) (Resolver, error) {
+	makeResolver, found := resolverMakers[spec.Type]
+	if !found {
+		return nil, fmt.Errorf("unknown resolver type '%s'", spec.Type)
+	}
+
+	return makeResolver(spec)
+}
+
+// NewStopResolver makes a resolver that stops movement at the first contact.
+func NewStopResolver() Resolver {
+	return &stopResolver{}
+}
+
+// NewSlideResolver makes a resolver that stops at the first contact and
+// slides along the surface with the rest of the movement.
+func NewSlideResolver() Resolver {
+	return &slideResolver{}
+}
+
+// NewBounceResolver makes a resolver that reflects the rest of the
+// movement off the surface at the first contact, keeping the given
+// fraction (restitution) of the movement that goes into the surface.
+func NewBounceResolver(restitution float64) Resolver {
+	return &bounceResolver{restitution: restitution}
+}
+
+// NewPushResolver makes a resolver that pushes lighter movables out of
+// the way and slides along anything else.
+func NewPushResolver() Resolver {
+	return &pushResolver{}
+}
+
+// Resolve calls the function.
+func (f ResolverFunc) Resolve(c *Collision) cirno.Vector {
+	return f(c)
+}
+
+// UnmarshalJSON parses a resolver type name, or an object with the type and parameters.
+func (spec *ResolverSpec) UnmarshalJSON(d []byte) error {
+	var typ string
+
+	if err := json.Unmarshal(d, &typ); err == nil {
+		*spec = ResolverSpec{Type: typ}
+
+		return nil
+	}
+
+	type plainSpec ResolverSpec
+
+	var ps plainSpec
+
+	if err := json.Unmarshal(d, &ps); err != nil {
+		return fmt.Errorf("resolver is not a string or object: %w", err)
+	}
+
+	*spec = ResolverSpec(ps)
+
+	return nil
+}
+
+func (r *stopResolver) Resolve(c *Collision) cirno.Vector {
+	return c.Move.MultiplyByScalar(c.Contact.Fraction)
+}
+
+func (r *slideResolver) Resolve(c *Collision) cirno.Vector {
+	return deflect(c, cirno.Zero(), c.Move, 0, nil)
+}
+
+func (r *bounceResolver) Resolve(c *Collision) cirno.Vector {
+	bounced := false
+
+	onContact := func(contact *Contact) {
+		b, ok := c.Object.(Bounceable)
+		if !ok || bounced {
+			return
+		}
+
+		bounced = true
+
+		b.Bounce(topdown.Vec(contact.Normal.X, contact.Normal.Y), r.restitution)
+	}
+
+	return deflect(c, cirno.Zero(), c.Move, r.restitution, onContact)
+}
+
+func (r *pushResolver) Resolve(c *Collision) cirno.Vector {
+	contact := c.Contact
+	allowed := c.Move.MultiplyByScalar(contact.Fraction)
+	rest := c.Move.Subtract(allowed)
+
+	if contact.Object == nil || massOf(contact.Object) >= massOf(c.Object) {
+		return deflect(c, allowed, rest, 0, nil)
+	}
+
+	// the part of the movement that goes into the other entity
+	into := contact.Normal.MultiplyByScalar(cirno.Dot(rest, contact.Normal))
+	pushed := c.Push(contact.ID, into)
+
+	return deflect(c, allowed, rest.Subtract(into).Add(pushed), 0, nil)
+}
+
+// deflect makes the remaining movement from the given offset, deflecting
+// it off of each surface contacted. The part of the movement going into
+// the surface is reflected, scaled by restitution (zero to slide). Each
+// contact makes a collision event, as when sliding into a corner.
+func deflect(c *Collision, offset, remaining cirno.Vector, restitution float64, onContact func(*Contact)) cirno.Vector {
+	for i := 0; i < DeflectIterations; i++ {
+		if remaining.ApproximatelyEqual(cirno.Zero()) {
+			return offset
+		}
+
+		contact, hit := c.Sweep(offset, remaining)
+		if !hit {
+			return offset.Add(remaining)
+		}
+
+		c.system.queueCollision(c.ID, contact, c.system.velocities[c.ID])
+
+		if onContact != nil {
+			onContact(contact)
+		}
+
+		offset = offset.Add(remaining.MultiplyByScalar(contact.Fraction))
+		rest := remaining.MultiplyByScalar(1 - contact.Fraction)
+		into := cirno.Dot(rest, contact.Normal)
+
+		remaining = rest.Subtract(contact.Normal.MultiplyByScalar((1 + restitution) * into))
+	}
+
+	return offset
+}
+
+func massOf(x any) float64 {
+	if m, ok := x.(Massive); ok {
+		return m.CollisionMass()
+	}
+
+	return DefaultMass
+}
@@ -0,0 +1,225 @@
+package movecollide_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xeipuuv/gojsonschema"
+	"github.com/zergon321/cirno"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/movecollide"
+	"github.com/jamestunnell/topdown/resource"
+)
+
+type massiveMover struct {
+	*testMover
+	mass float64
+}
+
+type bouncingMover struct {
+	*testMover
+	Normals []topdown.Vector
+}
+
+func TestStopResolver(t *testing.T) {
+	mover := resolveInto(t, movecollide.NewStopResolver(), topdown.Vec(40, 10))
+
+	assert.InDelta(t, 70, mover.Position.X, 0.01)
+	assert.InDelta(t, 55, mover.Position.Y, 0.01)
+}
+
+func TestSlideResolver(t *testing.T) {
+	mover := resolveInto(t, movecollide.NewSlideResolver(), topdown.Vec(40, 10))
+
+	assert.InDelta(t, 70, mover.Position.X, 0.01)
+	assert.InDelta(t, 60, mover.Position.Y, 0.01)
+}
+
+func TestBounceResolver(t *testing.T) {
+	s := newWholeMoveSystem(t)
+
+	mover := &bouncingMover{testMover: newTestMover(t, 50, 50, 10, 10)}
+	wall := newTestMover(t, 80, 50, 10, 10)
+
+	mover.Resolver = movecollide.NewBounceResolver(0.5)
+	mover.Velocity = topdown.Vec(40, 0)
+
+	s.Add("mover", mover)
+	s.Add("wall", wall)
+
+	s.MoveCollide(1)
+
+	// 20 to the wall, then half of the other 20 back
+	assert.InDelta(t, 60, mover.Position.X, 0.01)
+	require.Len(t, mover.Normals, 1)
+	assert.InDelta(t, -1, mover.Normals[0].X, 1e-6)
+}
+
+func TestPushResolver(t *testing.T) {
+	testCases := map[string]struct {
+		crateMass      float64
+		moverX, crateX float64
+	}{
+		"lighter": {crateMass: 1, moverX: 80, crateX: 90},
+		"heavier": {crateMass: 3, moverX: 70, crateX: 80},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			s := newWholeMoveSystem(t)
+
+			mover := &massiveMover{testMover: newTestMover(t, 50, 50, 10, 10), mass: 2}
+			crate := &massiveMover{testMover: newTestMover(t, 80, 50, 10, 10), mass: tc.crateMass}
+
+			mover.Resolver = movecollide.NewPushResolver()
+			mover.Velocity = topdown.Vec(30, 0)
+
+			s.Add("mover", mover)
+			s.Add("crate", crate)
+
+			s.MoveCollide(1)
+
+			assert.InDelta(t, tc.moverX, mover.Position.X, 0.01)
+			assert.InDelta(t, tc.crateX, crate.Position.X, 0.01)
+			assert.InDelta(t, tc.crateX, crate.Collider.Center().X, 0.01)
+		})
+	}
+}
+
+func TestNilResolverStops(t *testing.T) {
+	mover := resolveInto(t, nil, topdown.Vec(40, 0))
+
+	assert.InDelta(t, 70, mover.Position.X, 0.01)
+}
+
+func TestCustomResolver(t *testing.T) {
+	var collision *movecollide.Collision
+
+	r := movecollide.ResolverFunc(func(c *movecollide.Collision) cirno.Vector {
+		collision = c
+
+		return cirno.Zero()
+	})
+
+	mover := resolveInto(t, r, topdown.Vec(40, 0))
+
+	assert.Equal(t, topdown.Vec(50, 50), mover.Position)
+	require.NotNil(t, collision)
+	assert.Equal(t, "mover", collision.ID)
+	assert.Equal(t, cirno.NewVector(40, 0), collision.Move)
+	require.NotNil(t, collision.Contact)
+	assert.Equal(t, "wall", collision.Contact.ID)
+	assert.InDelta(t, 0.5, collision.Contact.Fraction, 0.001)
+	assert.InDelta(t, -1, collision.Contact.Normal.X, 1e-6)
+
+	// sweeping after a sideways offset misses the wall
+	_, hit := collision.Sweep(cirno.NewVector(0, 50), collision.Move)
+
+	assert.False(t, hit)
+}
+
+func TestResolverSpecJSON(t *testing.T) {
+	var spec movecollide.ResolverSpec
+
+	require.NoError(t, json.Unmarshal([]byte(`"slide"`), &spec))
+	assert.Equal(t, movecollide.ResolverTypeSlide, spec.Type)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"type": "bounce", "restitution": 0.5}`), &spec))
+	assert.Equal(t, movecollide.ResolverTypeBounce, spec.Type)
+	assert.Equal(t, 0.5, spec.Restitution)
+
+	assert.Error(t, json.Unmarshal([]byte(`5`), &spec))
+
+	for _, typ := range movecollide.ResolverTypes() {
+		r, err := movecollide.NewResolver(&movecollide.ResolverSpec{Type: typ})
+
+		assert.NoError(t, err)
+		assert.NotNil(t, r)
+	}
+
+	_, err := movecollide.NewResolver(&movecollide.ResolverSpec{Type: "unknown"})
+
+	assert.Error(t, err)
+}
+
+func TestResolverSchema(t *testing.T) {
+	schema, err := resource.MakeJSONSchema(movecollide.ResolverSchemaStr)
+
+	require.NoError(t, err)
+
+	valid := []string{`"slide"`, `{"type": "bounce", "restitution": 0.5}`}
+	invalid := []string{`""`, `{"restitution": 0.5}`, `{"type": "bounce", "restitution": 2}`}
+
+	for _, str := range valid {
+		result, err := schema.Validate(gojsonschema.NewStringLoader(str))
+
+		require.NoError(t, err)
+		assert.True(t, result.Valid(), str)
+	}
+
+	for _, str := range invalid {
+		result, err := schema.Validate(gojsonschema.NewStringLoader(str))
+
+		require.NoError(t, err)
+		assert.False(t, result.Valid(), str)
+	}
+}
+
+func TestRegisterResolver(t *testing.T) {
+	movecollide.RegisterResolver("freeze", func(*movecollide.ResolverSpec) (movecollide.Resolver, error) {
+		return movecollide.ResolverFunc(func(*movecollide.Collision) cirno.Vector {
+			return cirno.Zero()
+		}), nil
+	})
+
+	assert.Contains(t, movecollide.ResolverTypes(), "freeze")
+
+	r, err := movecollide.NewResolver(&movecollide.ResolverSpec{Type: "freeze"})
+
+	require.NoError(t, err)
+
+	mover := resolveInto(t, r, topdown.Vec(40, 0))
+
+	assert.Equal(t, topdown.Vec(50, 50), mover.Position)
+}
+
+// newWholeMoveSystem makes a system that resolves each movement all at
+// once, without sub-stepping.
+func newWholeMoveSystem(t *testing.T) movecollide.System {
+	s, err := movecollide.NewSystem(200, 200)
+
+	require.NoError(t, err)
+
+	s.SetMaxStepFraction(0)
+
+	return s
+}
+
+// resolveInto moves a mover at (50, 50) toward a wall at (80, 50).
+func resolveInto(t *testing.T, r movecollide.Resolver, velocity topdown.Vector) *testMover {
+	s := newWholeMoveSystem(t)
+
+	mover := newTestMover(t, 50, 50, 10, 10)
+	wall := newTestMover(t, 80, 50, 10, 50)
+
+	mover.Resolver = r
+	mover.Velocity = velocity
+
+	s.Add("mover", mover)
+	s.Add("wall", wall)
+
+	s.MoveCollide(1)
+
+	return mover
+}
+
+func (m *massiveMover) CollisionMass() float64 {
+	return m.mass
+}
+
+func (m *bouncingMover) Bounce(normal topdown.Vector, restitution float64) {
+	m.Normals = append(m.Normals, normal)
+}
@@ -0,0 +1,120 @@
+package movecollide
+
+import (
+	"math"
+
+	"github.com/zergon321/cirno"
+	"golang.org/x/exp/slices"
+)
+
+// shapeBounds gets the axis-aligned bounding box of a shape.
+func shapeBounds(shape cirno.Shape) (cirno.Vector, cirno.Vector) {
+	switch s := shape.(type) {
+	case *cirno.Rectangle:
+		vertices := s.Vertices()
+
+		return vectorBounds(vertices[:]...)
+	case *cirno.Circle:
+		r := cirno.NewVector(s.Radius(), s.Radius())
+
+		return s.Center().Subtract(r), s.Center().Add(r)
+	case *cirno.Line:
+		return s.GetBoundingBox()
+	}
+
+	return shape.Center(), shape.Center()
+}
+
+// pointDistance gets the distance from a point to the nearest point of a
+// shape, which is zero inside the shape. Shapes other than circles are
+// measured to their bounding box.
+func pointDistance(shape cirno.Shape, p cirno.Vector) float64 {
+	if c, ok := shape.(*cirno.Circle); ok {
+		return math.Max(0, cirno.Distance(p, c.Center())-c.Radius())
+	}
+
+	min, max := shapeBounds(shape)
+	dx := math.Max(0, math.Max(min.X-p.X, p.X-max.X))
+	dy := math.Max(0, math.Max(min.Y-p.Y, p.Y-max.Y))
+
+	return math.Hypot(dx, dy)
+}
+
+// shapeThickness gets the smallest size of a shape across its center.
+func shapeThickness(shape cirno.Shape) float64 {
+	switch s := shape.(type) {
+	case *cirno.Rectangle:
+		return math.Min(s.Width(), s.Height())
+	case *cirno.Circle:
+		return 2 * s.Radius()
+	}
+
+	return 0
+}
+
+// sweptBounds gets the bounding box of a shape moving from its current position.
+func sweptBounds(shape cirno.Shape, move cirno.Vector) (cirno.Vector, cirno.Vector) {
+	min, max := shapeBounds(shape)
+
+	return vectorBounds(min, max, min.Add(move), max.Add(move))
+}
+
+func vectorBounds(vs ...cirno.Vector) (cirno.Vector, cirno.Vector) {
+	min := cirno.NewVector(math.Inf(1), math.Inf(1))
+	max := cirno.NewVector(math.Inf(-1), math.Inf(-1))
+
+	for _, v := range vs {
+		min = cirno.NewVector(math.Min(min.X, v.X), math.Min(min.Y, v.Y))
+		max = cirno.NewVector(math.Max(max.X, v.X), math.Max(max.Y, v.Y))
+	}
+
+	return min, max
+}
+
+// boundsRect makes a rectangle shape covering the given bounds. A
+// little room is added so that the rectangle is never degenerate.
+func boundsRect(min, max cirno.Vector) (*cirno.Rectangle, error) {
+	const pad = 1e-3
+
+	center := min.Add(max).MultiplyByScalar(0.5)
+
+	return cirno.NewRectangle(center, max.X-min.X+pad, max.Y-min.Y+pad, 0)
+}
+
+// sortShapes puts shapes in order of the ID they were tagged with, then by
+// layer, and then by position, so that they are always visited in the same
+// order. Shapes of the same entity, like the walls of a map, are ordered
+// top to bottom and left to right.
+func sortShapes(shapes cirno.Shapes) []cirno.Shape {
+	sorted := make([]cirno.Shape, 0, len(shapes))
+
+	for shape := range shapes {
+		sorted = append(sorted, shape)
+	}
+
+	slices.SortFunc(sorted, func(a, b cirno.Shape) bool {
+		aID, _ := a.Data().(string)
+		bID, _ := b.Data().(string)
+
+		if aID != bID {
+			return aID < bID
+		}
+
+		if a.GetIdentity() != b.GetIdentity() {
+			return a.GetIdentity() < b.GetIdentity()
+		}
+
+		aMin, aMax := shapeBounds(a)
+		bMin, bMax := shapeBounds(b)
+
+		for _, pair := range [][2]float64{{aMin.Y, bMin.Y}, {aMin.X, bMin.X}, {aMax.Y, bMax.Y}, {aMax.X, bMax.X}} {
+			if pair[0] != pair[1] {
+				return pair[0] < pair[1]
+			}
+		}
+
+		return false
+	})
+
+	return sorted
+}
@@ -0,0 +1,276 @@
+package movecollide
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/zergon321/cirno"
+)
+
+type spatialHash struct {
+	cellSize   float64
+	cells      map[cellKey]cirno.Shapes
+	shapeCells map[cirno.Shape][]cellKey
+	extents    map[cirno.Shape]extent
+	bounds     extent
+	// stale is set when a shape at the edge of the bounds moves in or is
+	// removed, so the bounds may need to shrink
+	stale bool
+	// world is the fixed world area of a bounded spatial hash
+	world *extent
+}
+
+type cellKey struct {
+	x, y int
+}
+
+type extent struct {
+	min, max cirno.Vector
+}
+
+// DefaultCellSize is a spatial hash cell size that suits colliders
+// about the size of a tile or character.
+const DefaultCellSize = 64.0
+
+// NewSpatialHash makes a backend that buckets shapes into square cells
+// by their bounds. Cells are only made where there are shapes, so the
+// world is unbounded. Cells should be a bit larger than most shapes.
+func NewSpatialHash(cellSize float64) (Backend, error) {
+	if cellSize <= 0 {
+		return nil, fmt.Errorf("cell size %f is not positive", cellSize)
+	}
+
+	h := &spatialHash{
+		cellSize:   cellSize,
+		cells:      map[cellKey]cirno.Shapes{},
+		shapeCells: map[cirno.Shape][]cellKey{},
+		extents:    map[cirno.Shape]extent{},
+	}
+
+	return h, nil
+}
+
+// NewBoundedSpatialHash makes a spatial hash backend for a world of the
+// given size, which gets boundaries like a cirno backend.
+func NewBoundedSpatialHash(worldWidth, worldHeight, cellSize float64) (Backend, error) {
+	if worldWidth <= 0 || worldHeight <= 0 {
+		return nil, fmt.Errorf("world size %fx%f is not positive", worldWidth, worldHeight)
+	}
+
+	b, err := NewSpatialHash(cellSize)
+	if err != nil {
+		return nil, err
+	}
+
+	h := b.(*spatialHash)
+	h.world = &extent{min: cirno.Zero(), max: cirno.NewVector(worldWidth, worldHeight)}
+
+	return h, nil
+}
+
+func (h *spatialHash) Add(shape cirno.Shape) error {
+	if _, found := h.shapeCells[shape]; found {
+		return fmt.Errorf("shape already added")
+	}
+
+	h.insert(shape)
+
+	return nil
+}
+
+func (h *spatialHash) Remove(shape cirno.Shape) error {
+	if _, found := h.shapeCells[shape]; !found {
+		return fmt.Errorf("shape not found")
+	}
+
+	if h.atEdge(h.remove(shape)) {
+		h.stale = true
+	}
+
+	return nil
+}
+
+func (h *spatialHash) Update(shape cirno.Shape) error {
+	if _, found := h.shapeCells[shape]; !found {
+		return fmt.Errorf("shape not found")
+	}
+
+	from := h.remove(shape)
+
+	h.insert(shape)
+
+	if h.leavesEdge(from, h.extents[shape]) {
+		h.stale = true
+	}
+
+	return nil
+}
+
+func (h *spatialHash) Query(min, max cirno.Vector) (cirno.Shapes, error) {
+	shapes := cirno.Shapes{}
+
+	h.eachKey(min, max, func(key cellKey) {
+		for shape := range h.cells[key] {
+			shapes.Insert(shape)
+		}
+	})
+
+	return shapes, nil
+}
+
+// QueryLine finds the shapes in the cells that a line crosses.
+func (h *spatialHash) QueryLine(a, b cirno.Vector) (cirno.Shapes, error) {
+	shapes := cirno.Shapes{}
+
+	h.eachLineKey(a, b, func(key cellKey) {
+		for shape := range h.cells[key] {
+			shapes.Insert(shape)
+		}
+	})
+
+	return shapes, nil
+}
+
+func (h *spatialHash) Collide(shape, other cirno.Shape) (bool, error) {
+	return cirno.ResolveCollision(shape, other, true)
+}
+
+func (h *spatialHash) Bounds() (cirno.Vector, cirno.Vector, bool) {
+	if h.world != nil {
+		return h.world.min, h.world.max, true
+	}
+
+	if len(h.extents) == 0 {
+		return cirno.Zero(), cirno.Zero(), false
+	}
+
+	if h.stale {
+		corners := make([]cirno.Vector, 0, 2*len(h.extents))
+
+		for _, e := range h.extents {
+			corners = append(corners, e.min, e.max)
+		}
+
+		h.bounds.min, h.bounds.max = vectorBounds(corners...)
+		h.stale = false
+	}
+
+	return h.bounds.min, h.bounds.max, false
+}
+
+func (h *spatialHash) insert(shape cirno.Shape) {
+	min, max := shapeBounds(shape)
+	keys := []cellKey{}
+
+	h.eachKey(min, max, func(key cellKey) {
+		cell, found := h.cells[key]
+		if !found {
+			cell = cirno.Shapes{}
+
+			h.cells[key] = cell
+		}
+
+		cell.Insert(shape)
+
+		keys = append(keys, key)
+	})
+
+	h.shapeCells[shape] = keys
+
+	if len(h.extents) == 0 {
+		h.bounds = extent{min: min, max: max}
+		h.stale = false
+	} else {
+		h.bounds.min, h.bounds.max = vectorBounds(h.bounds.min, h.bounds.max, min, max)
+	}
+
+	h.extents[shape] = extent{min: min, max: max}
+}
+
+func (h *spatialHash) remove(shape cirno.Shape) extent {
+	for _, key := range h.shapeCells[shape] {
+		cell := h.cells[key]
+
+		cell.Remove(shape)
+
+		// drop empty cells so the hash shrinks again
+		if len(cell) == 0 {
+			delete(h.cells, key)
+		}
+	}
+
+	e := h.extents[shape]
+
+	delete(h.shapeCells, shape)
+	delete(h.extents, shape)
+
+	return e
+}
+
+// atEdge checks if a shape extent reaches the edge of the bounds.
+func (h *spatialHash) atEdge(e extent) bool {
+	return e.min.X <= h.bounds.min.X || e.min.Y <= h.bounds.min.Y ||
+		e.max.X >= h.bounds.max.X || e.max.Y >= h.bounds.max.Y
+}
+
+// leavesEdge checks if a shape moving from one extent to another no
+// longer reaches an edge of the bounds that it did.
+func (h *spatialHash) leavesEdge(from, to extent) bool {
+	return (from.min.X <= h.bounds.min.X && to.min.X > h.bounds.min.X) ||
+		(from.min.Y <= h.bounds.min.Y && to.min.Y > h.bounds.min.Y) ||
+		(from.max.X >= h.bounds.max.X && to.max.X < h.bounds.max.X) ||
+		(from.max.Y >= h.bounds.max.Y && to.max.Y < h.bounds.max.Y)
+}
+
+func (h *spatialHash) eachKey(min, max cirno.Vector, f func(cellKey)) {
+	x0, y0 := h.cell(min)
+	x1, y1 := h.cell(max)
+
+	for y := y0; y <= y1; y++ {
+		for x := x0; x <= x1; x++ {
+			f(cellKey{x: x, y: y})
+		}
+	}
+}
+
+// eachLineKey visits the cells crossed by a line, in order from a to b,
+// stepping to the neighboring cell whose border the line crosses first.
+func (h *spatialHash) eachLineKey(a, b cirno.Vector, f func(cellKey)) {
+	x, y := h.cell(a)
+	endX, endY := h.cell(b)
+	stepX, nextX, deltaX := h.lineSteps(a.X, b.X-a.X, x)
+	stepY, nextY, deltaY := h.lineSteps(a.Y, b.Y-a.Y, y)
+
+	f(cellKey{x: x, y: y})
+
+	for x != endX || y != endY {
+		// rounding can't carry the walk past the end cell
+		if y == endY || (x != endX && nextX < nextY) {
+			x += stepX
+			nextX += deltaX
+		} else {
+			y += stepY
+			nextY += deltaY
+		}
+
+		f(cellKey{x: x, y: y})
+	}
+}
+
+// lineSteps gets the cell step along one axis of a line, the fraction of
+// the line at which the first cell border is crossed, and the fraction
+// between borders.
+func (h *spatialHash) lineSteps(pos, delta float64, cell int) (int, float64, float64) {
+	switch {
+	case delta > 0:
+		return 1, (float64(cell+1)*h.cellSize - pos) / delta, h.cellSize / delta
+	case delta < 0:
+		return -1, (float64(cell)*h.cellSize - pos) / delta, -h.cellSize / delta
+	}
+
+	return 0, math.Inf(1), math.Inf(1)
+}
+
+func (h *spatialHash) cell(v cirno.Vector) (int, int) {
+	return int(math.Floor(v.X / h.cellSize)), int(math.Floor(v.Y / h.cellSize))
+}
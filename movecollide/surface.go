@@ -0,0 +1,106 @@
+package movecollide
+
+import (
+	"github.com/jamestunnell/topdown"
+)
+
+// Surface describes the terrain at a position, like mud, ice or water.
+type Surface struct {
+	Name string `json:"name"`
+	// SpeedScale scales movement on the surface, like 0.5 for mud.
+	// Movement is unchanged if it is not positive.
+	SpeedScale float64 `json:"speedScale,omitempty"`
+	// FrictionScale scales friction on the surface, like 0.1 for ice.
+	// Friction is unchanged if it is not positive.
+	FrictionScale float64 `json:"frictionScale,omitempty"`
+	// Swim is set for surfaces that must be swum through, like deep water.
+	Swim bool `json:"swim,omitempty"`
+	// Damage is the damage per second taken while on the surface.
+	Damage float64 `json:"damage,omitempty"`
+}
+
+// SurfaceSource is a component that has surfaces at some positions, like
+// a tile grid.
+type SurfaceSource interface {
+	SurfaceAt(pos topdown.Point[float64]) (*Surface, bool)
+}
+
+// SurfaceAware is an optional interface for a movable to be told about
+// the surface under it each tick, before planning its movement. The
+// surface is nil when there is no surface under it.
+type SurfaceAware interface {
+	SetSurface(s *Surface)
+}
+
+// SurfaceSchemaStr is the JSON schema for a surface.
+const SurfaceSchemaStr = `{
+  "$id": "https://github.com/jamestunnell/topdown/surface.json",
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "Surface",
+  "description": "Terrain surface properties.",
+  "type": "object",
+  "required": ["name"],
+  "properties": {
+    "name": {"type": "string", "minLength": 1},
+    "speedScale": {"type": "number", "minimum": 0},
+    "frictionScale": {"type": "number", "minimum": 0},
+    "swim": {"type": "boolean"},
+    "damage": {"type": "number", "minimum": 0}
+  }
+}`
+
+// SpeedFactor gets the speed scale, or one if it is not positive.
+func (s *Surface) SpeedFactor() float64 {
+	if s == nil || s.SpeedScale <= 0 {
+		return 1
+	}
+
+	return s.SpeedScale
+}
+
+// FrictionFactor gets the friction scale, or one if it is not positive.
+func (s *Surface) FrictionFactor() float64 {
+	if s == nil || s.FrictionScale <= 0 {
+		return 1
+	}
+
+	return s.FrictionScale
+}
+
+func (s *system) SurfaceAt(pos topdown.Point[float64]) (*Surface, bool) {
+	for _, id := range s.objects.Keys() {
+		src, ok := s.object(id).(SurfaceSource)
+		if !ok {
+			continue
+		}
+
+		if surface, found := src.SurfaceAt(pos); found {
+			return surface, true
+		}
+	}
+
+	return nil, false
+}
+
+// updateSurfaces tells each surface aware movable about the surface at
+// the center of its collider, or its trigger shape if it has no collider.
+func (s *system) updateSurfaces() {
+	for _, id := range s.objects.Keys() {
+		sa, ok := s.object(id).(SurfaceAware)
+		if !ok {
+			continue
+		}
+
+		shape, found := s.colliderShapes[id]
+		if !found {
+			if shape, found = s.triggerShapes[id]; !found {
+				continue
+			}
+		}
+
+		center := shape.Center()
+		surface, _ := s.SurfaceAt(topdown.Pt(center.X, center.Y))
+
+		sa.SetSurface(surface)
+	}
+}
@@ -0,0 +1,87 @@
+package movecollide_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/movecollide"
+)
+
+type testTerrain struct {
+	Area    topdown.Rectangle[float64]
+	Surface *movecollide.Surface
+}
+
+type surfaceMover struct {
+	*testMover
+	Surfaces []*movecollide.Surface
+}
+
+func TestSurfaces(t *testing.T) {
+	s, err := movecollide.NewSystem(200, 200)
+
+	require.NoError(t, err)
+
+	mud := &movecollide.Surface{Name: "mud", SpeedScale: 0.5}
+	ice := &movecollide.Surface{Name: "ice", FrictionScale: 0.1}
+	mover := &surfaceMover{testMover: newTestMover(t, 50, 50, 10, 10)}
+
+	s.Add("mover", mover)
+	s.Add("mud", &testTerrain{Area: topdown.Rect(0.0, 0.0, 100.0, 100.0), Surface: mud})
+	// overlaps the mud, which was added first
+	s.Add("ice", &testTerrain{Area: topdown.Rect(0.0, 0.0, 200.0, 200.0), Surface: ice})
+
+	surface, found := s.SurfaceAt(topdown.Pt(50.0, 50.0))
+
+	assert.True(t, found)
+	assert.Equal(t, mud, surface)
+
+	surface, found = s.SurfaceAt(topdown.Pt(150.0, 50.0))
+
+	assert.True(t, found)
+	assert.Equal(t, ice, surface)
+
+	_, found = s.SurfaceAt(topdown.Pt(250.0, 50.0))
+
+	assert.False(t, found)
+
+	mover.Velocity = topdown.Vec(100, 0)
+
+	s.MoveCollide(1)
+	s.MoveCollide(1)
+
+	// the surface is found before moving
+	assert.Equal(t, []*movecollide.Surface{mud, ice}, mover.Surfaces)
+
+	s.Remove("ice")
+	s.MoveCollide(0)
+
+	assert.Nil(t, mover.Surfaces[2])
+}
+
+func TestSurfaceFactors(t *testing.T) {
+	var none *movecollide.Surface
+
+	assert.Equal(t, 1.0, none.SpeedFactor())
+	assert.Equal(t, 1.0, none.FrictionFactor())
+
+	s := &movecollide.Surface{Name: "slush", SpeedScale: 0.5, FrictionScale: 0.25}
+
+	assert.Equal(t, 0.5, s.SpeedFactor())
+	assert.Equal(t, 0.25, s.FrictionFactor())
+}
+
+func (tt *testTerrain) SurfaceAt(pos topdown.Point[float64]) (*movecollide.Surface, bool) {
+	if !pos.In(tt.Area) {
+		return nil, false
+	}
+
+	return tt.Surface, true
+}
+
+func (m *surfaceMover) SetSurface(s *movecollide.Surface) {
+	m.Surfaces = append(m.Surfaces, s)
+}
@@ -2,117 +2,192 @@ package movecollide
 
 import (
 	"fmt"
+	"math"
 
 	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/ordered"
 	"github.com/rs/zerolog/log"
 	"github.com/zergon321/cirno"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 )
 
 //go:generate mockgen -destination=mock_movecollide/mocksystem.go . System
 
+// System moves entities in order of priority (see ordered.Prioritized),
+// then in the order they were added. Trigger callbacks are made in the
+// same order, and each trigger is told about other entities in ID order.
 type System interface {
 	Add(id string, resource interface{})
-	// Remove(id string)
-	// Clear()
-
+	Remove(id string)
+	Clear()
+
+	// Teleport moves an entity so its collider (or trigger shape, if it has no
+	// collider) is centered at the given position, without checking for collisions.
+	Teleport(id string, pos topdown.Point[float64]) error
+	// UpdateShapes replaces the shapes of an entity in the collision backend with
+	// those currently given by its ColliderShape and TriggerShape methods.
+	// Call this after an entity resizes or otherwise replaces one of its shapes.
+	UpdateShapes(id string) error
+
+	// Raycast finds the nearest entity hit by a ray.
 	Raycast(r *Ray) (*RayHit, bool)
+	// RaycastAll finds all the entities hit by a ray, sorted by distance.
+	RaycastAll(r *Ray) []*RayHit
+	// Circlecast finds the first entity hit by a circle moving along a ray.
+	Circlecast(c *CircleCast) (*RayHit, bool)
+	// Boxcast finds the first entity hit by a box moving along a ray.
+	Boxcast(b *BoxCast) (*RayHit, bool)
+	// OverlapRect finds all the entities overlapping an area, sorted by ID.
+	OverlapRect(area topdown.Rectangle[float64], mask Layer) []*Overlap
+	// OverlapCircle finds all the entities overlapping a circle, sorted by ID.
+	OverlapCircle(center topdown.Point[float64], radius float64, mask Layer) []*Overlap
+	// Nearest finds the entity in the given layers that is closest to a
+	// position, within the given radius. Distances are measured to the
+	// nearest point of any of the entity's shapes.
+	Nearest(pos topdown.Point[float64], radius float64, layers Layer) (*Overlap, bool)
+	// SurfaceAt finds the surface at a position, from the first surface
+	// source that has one.
+	SurfaceAt(pos topdown.Point[float64]) (*Surface, bool)
+
+	// AddCollisionListener adds a listener to be told about all the
+	// collisions resolved by MoveCollide, once all the movement is done.
+	AddCollisionListener(id string, l CollisionListener)
+	RemoveCollisionListener(id string)
+
+	// SetMaxStepFraction sets the largest movement made in a single step,
+	// as a fraction of the collider thickness. Larger movements are split
+	// into sub-steps, up to MaxSubsteps. Sub-stepping is turned off by a
+	// fraction that is not positive.
+	SetMaxStepFraction(f float64)
+	// MoveCollide moves all the movables, sweeping colliders along their
+	// movement so that they can't pass through anything. Surface aware
+	// movables are told about the surface under them first.
 	MoveCollide(deltaSec float64)
 }
 
-type Ray struct {
-	Origin, Direction cirno.Vector
-	Distance          float64
-}
-
-type RayHit struct {
-	ID       string
-	Position cirno.Vector
-	Object   any
-}
-
 type system struct {
-	space        *cirno.Space
-	movables     map[string]Movable
-	collidables  map[string]Collidable
-	triggerables map[string]Triggerable
+	backend        Backend
+	objects        *ordered.Map[string, any]
+	movables       map[string]Movable
+	collidables    map[string]Collidable
+	triggerables   map[string]Triggerable
+	colliderShapes map[string]cirno.Shape
+	triggerShapes  map[string]cirno.Shape
+	staticShapes   map[string][]cirno.Shape
+	triggers       map[string]map[string]*Trigger
+
+	listeners       map[string]CollisionListener
+	velocities      map[string]cirno.Vector
+	collided        map[collisionPair]struct{}
+	events          []*CollisionEvent
+	maxStepFraction float64
 }
 
 const (
-	CollisionSpaceCapacity    = 1024
-	CollisionSpaceSubdivision = 10
-
-	TriggerShapeID  = 1
-	ColliderShapeID = 2
+	// DefaultMaxStepFraction is the largest movement made in a single
+	// step by default, as a fraction of the collider thickness.
+	DefaultMaxStepFraction = 0.5
+	// MaxSubsteps is the most steps a movement is split into.
+	MaxSubsteps = 16
 )
 
+// NewSystem makes a system for a world of the given size, using a bounded
+// spatial hash backend with the default cell size.
 func NewSystem(worldWidth, worldHeight float64) (System, error) {
-	spaceMin := cirno.Zero()
-	spaceMax := cirno.NewVector(worldWidth, worldHeight)
+	backend, err := NewBoundedSpatialHash(worldWidth, worldHeight, DefaultCellSize)
+	if err != nil {
+		return nil, err
+	}
 
-	log.Debug().
-		Float64("w", worldWidth).
-		Float64("h", worldHeight).
-		Msg("creating collision space")
+	return NewSystemWithBackend(backend)
+}
 
-	space, err := cirno.NewSpace(
-		CollisionSpaceSubdivision, CollisionSpaceCapacity,
-		2*worldWidth, 2*worldHeight, spaceMin, spaceMax, true)
+// NewUnboundedSystem makes a system for a world without bounds, like a
+// streamed world, using a spatial hash backend with the given cell size.
+func NewUnboundedSystem(cellSize float64) (System, error) {
+	backend, err := NewSpatialHash(cellSize)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make collision space: %w", err)
+		return nil, err
 	}
 
-	// add boundaries to the collision space
-	nw := topdown.Pt[float64](0, 0)
-	ne := topdown.Pt(worldWidth, 0)
-	se := topdown.Pt(worldWidth, worldHeight)
-	sw := topdown.Pt(0, worldHeight)
+	return NewSystemWithBackend(backend)
+}
 
-	var addLineErr error
+// NewSystemWithBackend makes a system using the given backend. If the
+// backend world is bounded, boundary lines are added around it.
+func NewSystemWithBackend(backend Backend) (System, error) {
+	s := &system{
+		backend:        backend,
+		objects:        ordered.NewMap[string, any](),
+		movables:       map[string]Movable{},
+		collidables:    map[string]Collidable{},
+		triggerables:   map[string]Triggerable{},
+		colliderShapes: map[string]cirno.Shape{},
+		triggerShapes:  map[string]cirno.Shape{},
+		staticShapes:   map[string][]cirno.Shape{},
+		triggers:       map[string]map[string]*Trigger{},
+
+		listeners:       map[string]CollisionListener{},
+		velocities:      map[string]cirno.Vector{},
+		collided:        map[collisionPair]struct{}{},
+		events:          []*CollisionEvent{},
+		maxStepFraction: DefaultMaxStepFraction,
+	}
 
-	addLine := func(a, b topdown.Point[float64], id string) bool {
-		v1 := cirno.NewVector(a.X, a.Y)
-		v2 := cirno.NewVector(b.X, b.Y)
-		line, err := cirno.NewLine(v1, v2)
+	if min, max, bounded := backend.Bounds(); bounded {
+		if err := s.addBoundaries(min, max); err != nil {
+			return nil, err
+		}
+	}
 
-		line.SetIdentity(ColliderShapeID)
+	return s, nil
+}
 
-		// collides with anything
-		line.SetMask(^0)
+// addBoundaries adds lines around the world, which collide with anything.
+func (s *system) addBoundaries(min, max cirno.Vector) error {
+	corners := map[string][2]cirno.Vector{
+		"north": {min, cirno.NewVector(max.X, min.Y)},
+		"east":  {cirno.NewVector(max.X, min.Y), max},
+		"south": {max, cirno.NewVector(min.X, max.Y)},
+		"west":  {cirno.NewVector(min.X, max.Y), min},
+	}
 
-		line.SetData(id)
+	for _, id := range []string{"north", "east", "south", "west"} {
+		a, b := corners[id][0], corners[id][1]
 
+		line, err := cirno.NewLine(a, b)
 		if err != nil {
-			addLineErr = fmt.Errorf("failed to make space boundary: %w", err)
-
-			return false
+			return fmt.Errorf("failed to make world boundary: %w", err)
 		}
 
-		if err = space.Add(line); err != nil {
-			addLineErr = fmt.Errorf("failed to add space boundary: %w", err)
+		line.SetIdentity(int32(LayerWall))
+		line.SetMask(int32(LayerAll))
+		line.SetData(id)
 
-			return false
+		if err = s.backend.Add(line); err != nil {
+			return fmt.Errorf("failed to add world boundary: %w", err)
 		}
 
-		log.Debug().Stringer("a", a).Stringer("b", b).Msg("added boundary line")
-
-		return true
+		log.Debug().Str("id", id).Msg("added boundary line")
 	}
 
-	if !addLine(nw, ne, "north") || !addLine(ne, se, "east") || !addLine(se, sw, "south") || !addLine(sw, nw, "west") {
-		return nil, addLineErr
-	}
+	return nil
+}
 
-	s := &system{
-		space:        space,
-		movables:     map[string]Movable{},
-		collidables:  map[string]Collidable{},
-		triggerables: map[string]Triggerable{},
+func (s *system) Add(id string, x interface{}) {
+	// adding an existing ID replaces the entity, so its old shapes must go
+	if s.objects.Has(id) {
+		s.removeShapes(id)
+
+		delete(s.movables, id)
+		delete(s.collidables, id)
+		delete(s.triggerables, id)
+		delete(s.triggers, id)
 	}
 
-	return s, nil
-}
+	s.objects.SetWithPriority(id, x, ordered.PriorityOf(x))
 
-func (s *system) Add(id string, x interface{}) {
 	if m, ok := x.(Movable); ok {
 		s.movables[id] = m
 
@@ -120,105 +195,481 @@ func (s *system) Add(id string, x interface{}) {
 	}
 
 	if c, ok := x.(Collidable); ok {
-		shape := c.ColliderShape()
+		s.collidables[id] = c
 
-		shape.SetIdentity(ColliderShapeID)
+		log.Debug().Str("id", id).Msg("added collidable")
+	}
 
-		// collides with anything
-		shape.SetMask(^0)
+	if t, ok := x.(Triggerable); ok {
+		s.triggerables[id] = t
+		s.triggers[id] = map[string]*Trigger{}
 
-		shape.SetData(id)
+		log.Debug().Str("id", id).Msg("added triggerable")
+	}
 
-		s.space.Add(shape)
+	if _, ok := x.(StaticCollidable); ok {
+		log.Debug().Str("id", id).Msg("added static collidable")
+	}
 
-		s.collidables[id] = c
+	// an entity missing some of its shapes would behave oddly, so it is left out
+	if err := s.addShapes(id); err != nil {
+		log.Error().Err(err).Str("id", id).Msg("failed to add entity")
 
-		log.Debug().Str("id", id).Msg("added collidable")
+		s.Remove(id)
 	}
+}
 
-	if t, ok := x.(Triggerable); ok {
-		shape := t.TriggerShape()
+func (s *system) Remove(id string) {
+	if !s.objects.Has(id) {
+		return
+	}
 
-		shape.SetIdentity(TriggerShapeID)
+	s.removeShapes(id)
 
-		// don't collide with other triggers
-		shape.SetMask(^TriggerShapeID)
+	// let triggers know the removed entity is gone
+	for _, triggerID := range s.objects.Keys() {
+		triggers := s.triggers[triggerID]
 
-		s.space.Add(shape)
+		if trigger, found := triggers[id]; found {
+			delete(triggers, id)
 
-		s.triggerables[id] = t
+			s.triggerables[triggerID].TriggerExit(trigger)
+		}
+	}
 
-		log.Debug().Str("id", id).Msg("added triggerable")
+	s.objects.Delete(id)
+	delete(s.movables, id)
+	delete(s.collidables, id)
+	delete(s.triggerables, id)
+	delete(s.triggers, id)
+
+	log.Debug().Str("id", id).Msg("removed")
+}
+
+func (s *system) Clear() {
+	for _, id := range s.objects.Keys() {
+		s.removeShapes(id)
 	}
+
+	s.objects.Clear()
+	maps.Clear(s.movables)
+	maps.Clear(s.collidables)
+	maps.Clear(s.triggerables)
+	maps.Clear(s.triggers)
 }
 
-func (s *system) Raycast(r *Ray) (*RayHit, bool) {
-	hitShape, hitPos, err := s.space.Raycast(r.Origin, r.Direction, r.Distance, ColliderShapeID)
-	if err != nil {
-		log.Warn().Err(err).Msg("raycast failed")
+func (s *system) Teleport(id string, pos topdown.Point[float64]) error {
+	if !s.objects.Has(id) {
+		return fmt.Errorf("entity '%s' not found", id)
+	}
 
-		return nil, false
+	shape, found := s.colliderShapes[id]
+	if !found {
+		if shape, found = s.triggerShapes[id]; !found {
+			return fmt.Errorf("entity '%s' has no shapes", id)
+		}
 	}
 
-	if hitShape == nil {
-		return nil, false
+	moveDiff := cirno.NewVector(pos.X, pos.Y).Subtract(shape.Center())
+
+	if m, found := s.movables[id]; found {
+		m.Move(topdown.Vec(moveDiff.X, moveDiff.Y))
 	}
 
-	hitID, ok := hitShape.Data().(string)
-	if !ok {
-		log.Debug().Msg("ray hit, but hit shape data is not ID string")
+	for _, shape := range s.entityShapes(id) {
+		shape.Move(moveDiff)
+		if err := s.backend.Update(shape); err != nil {
+			return fmt.Errorf("failed to update collision backend: %w", err)
+		}
+	}
+
+	return nil
+}
 
-		return nil, false
+func (s *system) UpdateShapes(id string) error {
+	if !s.objects.Has(id) {
+		return fmt.Errorf("entity '%s' not found", id)
 	}
 
-	c, found := s.collidables[hitID]
-	if !found {
-		log.Debug().Str("collidable ID", hitID).Msg("ray hit, but collidable not found")
+	s.removeShapes(id)
+
+	return s.addShapes(id)
+}
+
+// addShapes adds the shapes an entity currently has to the collision backend.
+func (s *system) addShapes(id string) error {
+	if c, found := s.collidables[id]; found {
+		if err := s.addColliderShape(id, c.ColliderShape()); err != nil {
+			return err
+		}
+	}
+
+	if t, found := s.triggerables[id]; found {
+		if err := s.addTriggerShape(id, t.TriggerShape()); err != nil {
+			return err
+		}
+	}
+
+	if sc, ok := s.object(id).(StaticCollidable); ok {
+		if err := s.addStaticShapes(id, sc.StaticColliderShapes()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *system) addColliderShape(id string, shape cirno.Shape) error {
+	layer, mask := colliderTags(s.object(id))
+
+	shape.SetIdentity(int32(layer))
+	shape.SetMask(int32(mask))
+
+	shape.SetData(id)
+
+	if err := s.backend.Add(shape); err != nil {
+		log.Warn().Err(err).Str("id", id).Msg("failed to add collider shape")
+
+		return fmt.Errorf("failed to add collider shape: %w", err)
+	}
+
+	s.colliderShapes[id] = shape
+
+	return nil
+}
+
+func (s *system) addTriggerShape(id string, shape cirno.Shape) error {
+	// a shape that is already the collider also serves as the trigger shape
+	if shape == s.colliderShapes[id] {
+		s.triggerShapes[id] = shape
+
+		return nil
+	}
+
+	layer, mask := triggerTags(s.object(id))
+
+	shape.SetIdentity(int32(layer))
+	shape.SetMask(int32(mask))
+
+	shape.SetData(id)
+
+	if err := s.backend.Add(shape); err != nil {
+		log.Warn().Err(err).Str("id", id).Msg("failed to add trigger shape")
+
+		return fmt.Errorf("failed to add trigger shape: %w", err)
+	}
+
+	s.triggerShapes[id] = shape
+
+	return nil
+}
+
+func (s *system) addStaticShapes(id string, shapes []cirno.Shape) error {
+	layer, mask := colliderTags(s.object(id))
+	added := []cirno.Shape{}
+
+	// recorded even if adding fails part way, so the added shapes can be removed
+	defer func() {
+		s.staticShapes[id] = added
+	}()
+
+	for _, shape := range shapes {
+		shape.SetIdentity(int32(layer))
+		shape.SetMask(int32(mask))
+
+		shape.SetData(id)
+
+		if err := s.backend.Add(shape); err != nil {
+			log.Warn().Err(err).Str("id", id).Msg("failed to add static collider shape")
 
-		return nil, false
+			return fmt.Errorf("failed to add static collider shape: %w", err)
+		}
+
+		added = append(added, shape)
+	}
+
+	return nil
+}
+
+// entityShapes returns the distinct shapes an entity has in the collision backend.
+func (s *system) entityShapes(id string) []cirno.Shape {
+	shapes := []cirno.Shape{}
+
+	collider, hasCollider := s.colliderShapes[id]
+	if hasCollider {
+		shapes = append(shapes, collider)
+	}
+
+	if trigger, found := s.triggerShapes[id]; found && (!hasCollider || trigger != collider) {
+		shapes = append(shapes, trigger)
+	}
+
+	return append(shapes, s.staticShapes[id]...)
+}
+
+func (s *system) removeShapes(id string) {
+	for _, shape := range s.entityShapes(id) {
+		if err := s.backend.Remove(shape); err != nil {
+			log.Warn().Err(err).Str("id", id).Msg("failed to remove shape")
+		}
 	}
 
-	hit := &RayHit{ID: hitID, Position: hitPos, Object: c}
+	delete(s.colliderShapes, id)
+	delete(s.triggerShapes, id)
+	delete(s.staticShapes, id)
+}
+
+// object gets the entity with the given ID, or nil.
+func (s *system) object(id string) any {
+	obj, _ := s.objects.Get(id)
 
-	return hit, false
+	return obj
+}
+
+func (s *system) SetMaxStepFraction(f float64) {
+	s.maxStepFraction = f
 }
 
 func (s *system) MoveCollide(deltaSec float64) {
-	for id, m := range s.movables {
+	ids := []string{}
+	moves := map[string]cirno.Vector{}
+	steps := map[string]int{}
+	maxSteps := 1
+
+	maps.Clear(s.velocities)
+
+	s.updateSurfaces()
+
+	for _, id := range s.objects.Keys() {
+		m, found := s.movables[id]
+		if !found {
+			continue
+		}
+
 		move := m.PlanMovement(deltaSec)
 		if move.Zero() {
 			continue
 		}
 
-		c, found := s.collidables[id]
-		if !found {
-			m.Move(move)
+		moveDiff := cirno.NewVector(move.X, move.Y)
+		n := s.substeps(id, moveDiff)
 
-			continue
+		ids = append(ids, id)
+		moves[id] = moveDiff
+		steps[id] = n
+
+		if deltaSec > 0 {
+			s.velocities[id] = moveDiff.MultiplyByScalar(1 / deltaSec)
 		}
 
-		moveDiff := cirno.NewVector(move.X, move.Y)
-		shape := c.ColliderShape()
+		if n > maxSteps {
+			maxSteps = n
+		}
+	}
 
-		shapes, err := s.space.WouldBeCollidedBy(shape, moveDiff, 0)
-		if err != nil {
-			log.Warn().Err(err).Msg("failed to figure shape move collision")
+	// spread the steps of each movable evenly over the steps of the
+	// fastest, so fast movers take turns with each other
+	for k := 1; k <= maxSteps; k++ {
+		for _, id := range ids {
+			n := steps[id]
+			if k*n/maxSteps == (k-1)*n/maxSteps {
+				continue
+			}
 
-			continue
+			s.step(id, moves[id].MultiplyByScalar(1/float64(n)))
+		}
+	}
+
+	s.dispatchCollisions()
+	s.checkTriggers()
+}
+
+// substeps gets the number of steps needed to keep each step of a
+// movement within the max step fraction of the entity's thickness.
+func (s *system) substeps(id string, moveDiff cirno.Vector) int {
+	if s.maxStepFraction <= 0 {
+		return 1
+	}
+
+	shape, found := s.colliderShapes[id]
+	if !found {
+		return 1
+	}
+
+	thickness := shapeThickness(shape)
+	if thickness <= 0 {
+		return 1
+	}
+
+	n := int(math.Ceil(moveDiff.Magnitude() / (s.maxStepFraction * thickness)))
+
+	switch {
+	case n < 1:
+		return 1
+	case n > MaxSubsteps:
+		return MaxSubsteps
+	}
+
+	return n
+}
+
+// step moves an entity, sweeping its collider along the movement to resolve
+// the first collision so that it can't pass through anything.
+func (s *system) step(id string, moveDiff cirno.Vector) {
+	m := s.movables[id]
+
+	c, found := s.collidables[id]
+	if !found {
+		s.moveEntity(id, m, moveDiff)
+
+		return
+	}
+
+	shape := s.colliderShapes[id]
+
+	contact, err := s.sweep(shape, cirno.Zero(), moveDiff)
+	if err != nil {
+		log.Warn().Err(err).Str("id", id).Msg("failed to sweep collider")
+
+		return
+	}
+
+	if contact != nil {
+		s.queueCollision(id, contact, s.velocities[id])
+
+		col := &Collision{
+			ID:      id,
+			Object:  s.object(id),
+			Shape:   shape,
+			Move:    moveDiff,
+			Contact: contact,
+			system:  s,
 		}
 
-		if len(shapes) > 0 {
-			moveDiff = c.ResolveCollision(moveDiff, shapes)
+		r := c.CollisionResolver()
+		if r == nil {
+			r = NewStopResolver()
 		}
 
-		m.Move(topdown.Vec(moveDiff.X, moveDiff.Y))
+		moveDiff = r.Resolve(col)
+	}
 
+	s.moveEntity(id, m, moveDiff)
+}
+
+// moveEntity moves a movable along with its shapes.
+func (s *system) moveEntity(id string, m Movable, moveDiff cirno.Vector) {
+	m.Move(topdown.Vec(moveDiff.X, moveDiff.Y))
+
+	if shape, found := s.colliderShapes[id]; found {
 		shape.Move(moveDiff)
-		s.space.AdjustShapePosition(shape)
-		_, err = s.space.Update(shape)
+		if err := s.backend.Update(shape); err != nil {
+			log.Warn().Err(err).Msg("failed to update collision backend")
+		}
+	}
+
+	s.moveTriggerShape(id, moveDiff)
+}
 
+// moveTriggerShape keeps the trigger shape of a triggerable in step with the
+// entity movement. Nothing is done if the trigger shape is also the collider.
+func (s *system) moveTriggerShape(id string, moveDiff cirno.Vector) {
+	shape, found := s.triggerShapes[id]
+	if !found || shape == s.colliderShapes[id] {
+		return
+	}
+
+	shape.Move(moveDiff)
+	if err := s.backend.Update(shape); err != nil {
+		log.Warn().Err(err).Msg("failed to update collision backend")
+	}
+}
+
+// checkTriggers finds the colliders overlapping each trigger shape and
+// compares them to those found in the last check, so that each
+// triggerable can be notified of parties entering, remaining in, and
+// exiting the trigger area.
+func (s *system) checkTriggers() {
+	for _, id := range s.objects.Keys() {
+		t, found := s.triggerables[id]
+		if !found {
+			continue
+		}
+
+		shapes, err := collidedBy(s.backend, s.triggerShapes[id])
 		if err != nil {
-			log.Warn().Err(err).Msg("failed to update collision space")
+			log.Warn().Err(err).Str("id", id).Msg("failed to check trigger overlaps")
+
+			continue
+		}
+
+		current := map[string]*Trigger{}
+		currentIDs := []string{}
+
+		for _, shape := range sortShapes(shapes) {
+			otherID, ok := shape.Data().(string)
+			if !ok || otherID == id {
+				continue
+			}
+
+			// boundaries and other shapes not added by ID are ignored
+			obj, found := s.objects.Get(otherID)
+			if !found {
+				continue
+			}
+
+			if _, found := current[otherID]; found {
+				continue
+			}
+
+			current[otherID] = &Trigger{ID: otherID, Object: obj, Shape: shape}
+			currentIDs = append(currentIDs, otherID)
+		}
+
+		prev := s.triggers[id]
+		inside := maps.Clone(prev)
+
+		// callbacks can remove entities. Remove lets the trigger know that
+		// the parties inside it have exited, so they are kept up to date
+		// while the callbacks run, and each party exits once.
+		s.triggers[id] = inside
+
+		for _, otherID := range currentIDs {
+			if !s.objects.Has(id) {
+				break
+			}
+
+			if !s.objects.Has(otherID) {
+				continue
+			}
+
+			inside[otherID] = current[otherID]
+
+			if _, found := prev[otherID]; found {
+				t.TriggerRemain(current[otherID])
+			} else {
+				t.TriggerEnter(current[otherID])
+			}
+		}
+
+		prevIDs := maps.Keys(prev)
+
+		slices.Sort(prevIDs)
+
+		for _, otherID := range prevIDs {
+			if !s.objects.Has(id) {
+				break
+			}
+
+			if _, found := current[otherID]; found {
+				continue
+			}
+
+			if trigger, found := inside[otherID]; found {
+				delete(inside, otherID)
+
+				t.TriggerExit(trigger)
+			}
 		}
 	}
 }
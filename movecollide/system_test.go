@@ -0,0 +1,571 @@
+package movecollide_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zergon321/cirno"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/movecollide"
+)
+
+type testMover struct {
+	Position topdown.Vector
+	Velocity topdown.Vector
+	Collider cirno.Shape
+	Resolver movecollide.Resolver
+}
+
+type testZone struct {
+	Shape                 cirno.Shape
+	Entered, Remained     []string
+	Exited                []string
+	EnteredObjs, ExitObjs []any
+}
+
+func TestTriggerEnterRemainExit(t *testing.T) {
+	s, err := movecollide.NewSystem(200, 200)
+
+	require.NoError(t, err)
+
+	zone := newTestZone(t, 100, 50, 20, 20)
+	mover := newTestMover(t, 60, 50, 10, 10)
+
+	s.Add("zone", zone)
+	s.Add("mover", mover)
+
+	s.MoveCollide(1)
+
+	assert.Empty(t, zone.Entered)
+
+	// move into the zone
+	mover.Velocity = topdown.Vec(35, 0)
+
+	s.MoveCollide(1)
+
+	assert.Equal(t, []string{"mover"}, zone.Entered)
+	assert.Equal(t, []any{mover}, zone.EnteredObjs)
+	assert.Empty(t, zone.Remained)
+
+	// stay in the zone
+	mover.Velocity = topdown.Vec(0, 0)
+
+	s.MoveCollide(1)
+
+	assert.Equal(t, []string{"mover"}, zone.Remained)
+	assert.Empty(t, zone.Exited)
+
+	// leave the zone
+	mover.Velocity = topdown.Vec(40, 0)
+
+	s.MoveCollide(1)
+
+	assert.Equal(t, []string{"mover"}, zone.Exited)
+	assert.Equal(t, []any{mover}, zone.ExitObjs)
+	assert.Len(t, zone.Entered, 1)
+}
+
+func TestTriggersDoNotBlockMovement(t *testing.T) {
+	s, err := movecollide.NewSystem(200, 200)
+
+	require.NoError(t, err)
+
+	zone := newTestZone(t, 100, 50, 20, 20)
+	mover := newTestMover(t, 80, 50, 10, 10)
+
+	s.Add("zone", zone)
+	s.Add("mover", mover)
+
+	mover.Velocity = topdown.Vec(20, 0)
+
+	s.MoveCollide(1)
+
+	assert.Equal(t, topdown.Vec(100, 50), mover.Position)
+}
+
+func TestUnboundedSystem(t *testing.T) {
+	s, err := movecollide.NewUnboundedSystem(movecollide.DefaultCellSize)
+
+	require.NoError(t, err)
+
+	// far from the origin, and in negative space
+	zone := newTestZone(t, -10000, 50000, 20, 20)
+	mover := newTestMover(t, -10040, 50000, 10, 10)
+
+	s.Add("zone", zone)
+	s.Add("mover", mover)
+
+	mover.Velocity = topdown.Vec(40, 0)
+
+	s.MoveCollide(1)
+
+	assert.Equal(t, []string{"mover"}, zone.Entered)
+
+	_, err = movecollide.NewUnboundedSystem(0)
+
+	assert.Error(t, err)
+}
+
+func newTestMover(t *testing.T, x, y, w, h float64) *testMover {
+	rect, err := cirno.NewRectangle(cirno.NewVector(x, y), w, h, 0)
+
+	require.NoError(t, err)
+
+	return &testMover{Position: topdown.Vec(x, y), Collider: rect}
+}
+
+func newTestZone(t *testing.T, x, y, w, h float64) *testZone {
+	rect, err := cirno.NewRectangle(cirno.NewVector(x, y), w, h, 0)
+
+	require.NoError(t, err)
+
+	return &testZone{Shape: rect}
+}
+
+func (m *testMover) PlanMovement(deltaSec float64) topdown.Vector {
+	return m.Velocity.Multiply(deltaSec)
+}
+
+func (m *testMover) Move(moveDiff topdown.Vector) {
+	m.Position = m.Position.Add(moveDiff)
+}
+
+func (m *testMover) ColliderShape() cirno.Shape {
+	return m.Collider
+}
+
+func (m *testMover) CollisionResolver() movecollide.Resolver {
+	return m.Resolver
+}
+
+func (z *testZone) TriggerShape() cirno.Shape {
+	return z.Shape
+}
+
+func (z *testZone) TriggerEnter(trigger *movecollide.Trigger) {
+	z.Entered = append(z.Entered, trigger.ID)
+	z.EnteredObjs = append(z.EnteredObjs, trigger.Object)
+}
+
+func (z *testZone) TriggerRemain(trigger *movecollide.Trigger) {
+	z.Remained = append(z.Remained, trigger.ID)
+}
+
+func (z *testZone) TriggerExit(trigger *movecollide.Trigger) {
+	z.Exited = append(z.Exited, trigger.ID)
+	z.ExitObjs = append(z.ExitObjs, trigger.Object)
+}
+
+// removingZone removes the parties that enter or remain in it.
+type removingZone struct {
+	*testZone
+
+	System        movecollide.System
+	RemoveOnEnter bool
+}
+
+func (z *removingZone) TriggerEnter(trigger *movecollide.Trigger) {
+	z.testZone.TriggerEnter(trigger)
+
+	if z.RemoveOnEnter {
+		z.System.Remove(trigger.ID)
+	}
+}
+
+func (z *removingZone) TriggerRemain(trigger *movecollide.Trigger) {
+	z.testZone.TriggerRemain(trigger)
+
+	if !z.RemoveOnEnter {
+		z.System.Remove(trigger.ID)
+	}
+}
+
+func TestRemoveFromTriggerCallback(t *testing.T) {
+	for name, removeOnEnter := range map[string]bool{"enter": true, "remain": false} {
+		t.Run(name, func(t *testing.T) {
+			s, err := movecollide.NewSystem(200, 200)
+
+			require.NoError(t, err)
+
+			zone := &removingZone{
+				testZone:      newTestZone(t, 100, 50, 40, 20),
+				System:        s,
+				RemoveOnEnter: removeOnEnter,
+			}
+
+			s.Add("zone", zone)
+			s.Add("mover", newTestMover(t, 95, 50, 10, 10))
+			s.Add("other", newTestMover(t, 110, 50, 10, 10))
+
+			for i := 0; i < 3; i++ {
+				s.MoveCollide(1)
+			}
+
+			// each party is removed in its first callback and exits once
+			assert.Equal(t, []string{"mover", "other"}, zone.Entered)
+			assert.Equal(t, []string{"mover", "other"}, zone.Exited)
+		})
+	}
+}
+
+func TestRemoveFiresTriggerExitAndFreesSpace(t *testing.T) {
+	s, err := movecollide.NewSystem(200, 200)
+
+	require.NoError(t, err)
+
+	zone := newTestZone(t, 100, 50, 20, 20)
+	mover := newTestMover(t, 100, 50, 10, 10)
+	blocker := newTestMover(t, 130, 50, 10, 10)
+
+	s.Add("zone", zone)
+	s.Add("mover", mover)
+	s.Add("blocker", blocker)
+
+	s.MoveCollide(1)
+
+	require.Equal(t, []string{"mover"}, zone.Entered)
+
+	s.Remove("mover")
+
+	assert.Equal(t, []string{"mover"}, zone.Exited)
+
+	// removed movables are no longer moved
+	mover.Velocity = topdown.Vec(10, 0)
+
+	s.MoveCollide(1)
+
+	assert.Equal(t, topdown.Vec(100, 50), mover.Position)
+
+	// the removed blocker no longer gets in the way
+	s.Remove("blocker")
+
+	s.Add("mover", mover)
+
+	mover.Velocity = topdown.Vec(30, 0)
+
+	s.MoveCollide(1)
+
+	assert.Equal(t, topdown.Vec(130, 50), mover.Position)
+}
+
+func TestClear(t *testing.T) {
+	s, err := movecollide.NewSystem(200, 200)
+
+	require.NoError(t, err)
+
+	zone := newTestZone(t, 100, 50, 20, 20)
+	mover := newTestMover(t, 100, 50, 10, 10)
+
+	s.Add("zone", zone)
+	s.Add("mover", mover)
+
+	s.Clear()
+
+	mover.Velocity = topdown.Vec(10, 0)
+
+	s.MoveCollide(1)
+
+	assert.Empty(t, zone.Entered)
+	assert.Equal(t, topdown.Vec(100, 50), mover.Position)
+
+	_, hit := s.Raycast(&movecollide.Ray{
+		Origin:    cirno.NewVector(50, 50),
+		Direction: cirno.NewVector(1, 0),
+		Distance:  100,
+	})
+
+	assert.False(t, hit)
+}
+
+func TestAddReplacesEntity(t *testing.T) {
+	s, err := movecollide.NewSystem(200, 200)
+
+	require.NoError(t, err)
+
+	mover := newTestMover(t, 50, 50, 10, 10)
+
+	s.Add("mover", newTestMover(t, 100, 50, 10, 10))
+	s.Add("mover", mover)
+
+	// the old collider is gone, so it doesn't block the way
+	mover.Velocity = topdown.Vec(60, 0)
+
+	s.MoveCollide(1)
+
+	assert.Equal(t, topdown.Vec(110, 50), mover.Position)
+
+	hits := s.OverlapRect(topdown.Rect(0.0, 0.0, 200.0, 200.0), movecollide.LayerAll)
+
+	require.Len(t, hits, 1)
+	assert.Equal(t, mover, hits[0].Object)
+}
+
+type failingBackend struct {
+	movecollide.Backend
+
+	fail cirno.Shape
+}
+
+func (b *failingBackend) Add(shape cirno.Shape) error {
+	if shape == b.fail {
+		return errors.New("failed on purpose")
+	}
+
+	return b.Backend.Add(shape)
+}
+
+func TestAddLeavesOutEntityWhenShapesFail(t *testing.T) {
+	backend, err := movecollide.NewSpatialHash(movecollide.DefaultCellSize)
+
+	require.NoError(t, err)
+
+	wallA := newTaggedRect(t, 100, 40, 10, 20)
+	wallB := newTaggedRect(t, 100, 60, 10, 20)
+	fb := &failingBackend{Backend: backend, fail: wallB}
+
+	s, err := movecollide.NewSystemWithBackend(fb)
+
+	require.NoError(t, err)
+
+	s.Add("walls", &testStatic{Shapes: []cirno.Shape{wallA, wallB}})
+
+	// the shape that was added before the failure is removed
+	assert.Empty(t, s.OverlapRect(topdown.Rect(0.0, 0.0, 200.0, 200.0), movecollide.LayerAll))
+	assert.Error(t, s.UpdateShapes("walls"))
+}
+
+func TestTeleport(t *testing.T) {
+	s, err := movecollide.NewSystem(200, 200)
+
+	require.NoError(t, err)
+
+	zone := newTestZone(t, 150, 150, 20, 20)
+	mover := newTestMover(t, 20, 20, 10, 10)
+
+	s.Add("zone", zone)
+	s.Add("mover", mover)
+
+	assert.Error(t, s.Teleport("unknown", topdown.Pt[float64](150, 150)))
+
+	require.NoError(t, s.Teleport("mover", topdown.Pt[float64](150, 150)))
+
+	assert.Equal(t, topdown.Vec(150, 150), mover.Position)
+	assert.Equal(t, cirno.NewVector(150, 150), mover.Collider.Center())
+
+	s.MoveCollide(1)
+
+	assert.Equal(t, []string{"mover"}, zone.Entered)
+}
+
+func TestUpdateShapes(t *testing.T) {
+	s, err := movecollide.NewSystem(200, 200)
+
+	require.NoError(t, err)
+
+	zone := newTestZone(t, 100, 50, 20, 20)
+	mover := newTestMover(t, 70, 50, 10, 10)
+
+	s.Add("zone", zone)
+	s.Add("mover", mover)
+
+	s.MoveCollide(1)
+
+	assert.Empty(t, zone.Entered)
+
+	// grow the collider enough to reach the zone
+	bigger, err := cirno.NewRectangle(mover.Collider.Center(), 40, 40, 0)
+
+	require.NoError(t, err)
+
+	mover.Collider = bigger
+
+	assert.Error(t, s.UpdateShapes("unknown"))
+	require.NoError(t, s.UpdateShapes("mover"))
+
+	s.MoveCollide(1)
+
+	assert.Equal(t, []string{"mover"}, zone.Entered)
+}
+
+func TestFastMoverDoesNotTunnel(t *testing.T) {
+	for _, fraction := range []float64{0, movecollide.DefaultMaxStepFraction} {
+		s, err := movecollide.NewSystem(200, 200)
+
+		require.NoError(t, err)
+
+		s.SetMaxStepFraction(fraction)
+
+		mover := newTestMover(t, 20, 50, 10, 10)
+		wall := newTestMover(t, 100, 50, 2, 50)
+
+		s.Add("mover", mover)
+		s.Add("wall", wall)
+
+		// far enough in one tick to clear the wall
+		mover.Velocity = topdown.Vec(150, 0)
+
+		s.MoveCollide(1)
+
+		assert.InDelta(t, 94, mover.Position.X, 0.01)
+	}
+}
+
+func TestFastMoversMeet(t *testing.T) {
+	s, err := movecollide.NewSystem(200, 200)
+
+	require.NoError(t, err)
+
+	a := newTestMover(t, 30, 50, 10, 10)
+	b := newTestMover(t, 170, 50, 10, 10)
+
+	a.Velocity = topdown.Vec(120, 0)
+	b.Velocity = topdown.Vec(-120, 0)
+
+	s.Add("a", a)
+	s.Add("b", b)
+
+	s.MoveCollide(1)
+
+	// sub-steps let them meet near the middle, no matter which moves first
+	assert.InDelta(t, 100, (a.Position.X+b.Position.X)/2, 5)
+	assert.InDelta(t, 10, b.Position.X-a.Position.X, 0.01)
+}
+
+type prioritizedMover struct {
+	*testMover
+	priority int
+}
+
+func TestMoveOrder(t *testing.T) {
+	testCases := map[string]struct {
+		priority int
+		expectA  float64
+		expectB  float64
+	}{
+		"insertion order": {priority: 0, expectA: 70, expectB: 80},
+		"priority first":  {priority: -1, expectA: 60, expectB: 70},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			s := newWholeMoveSystem(t)
+
+			a := newTestMover(t, 50, 50, 10, 10)
+			b := &prioritizedMover{testMover: newTestMover(t, 90, 50, 10, 10), priority: tc.priority}
+
+			a.Velocity = topdown.Vec(20, 0)
+			b.Velocity = topdown.Vec(-20, 0)
+
+			s.Add("a", a)
+			s.Add("b", b)
+
+			s.MoveCollide(1)
+
+			// whoever moves first gets the contested space
+			assert.InDelta(t, tc.expectA, a.Position.X, 0.01)
+			assert.InDelta(t, tc.expectB, b.Position.X, 0.01)
+		})
+	}
+}
+
+func TestTriggerOrder(t *testing.T) {
+	s, err := movecollide.NewSystem(200, 200)
+
+	require.NoError(t, err)
+
+	zone := newTestZone(t, 100, 100, 100, 100)
+
+	s.Add("zone", zone)
+
+	for _, id := range []string{"c", "a", "b"} {
+		s.Add(id, newTestMover(t, 100, 100, 10, 10))
+	}
+
+	s.MoveCollide(1)
+
+	assert.Equal(t, []string{"a", "b", "c"}, zone.Entered)
+
+	s.Remove("b")
+
+	assert.Equal(t, []string{"b"}, zone.Exited)
+}
+
+func (m *prioritizedMover) Priority() int {
+	return m.priority
+}
+
+type testStatic struct {
+	Shapes []cirno.Shape
+}
+
+func TestStaticCollidable(t *testing.T) {
+	s := newWholeMoveSystem(t)
+
+	wallA := newTaggedRect(t, 100, 40, 10, 20)
+	wallB := newTaggedRect(t, 100, 60, 10, 20)
+	walls := &testStatic{Shapes: []cirno.Shape{wallA, wallB}}
+	mover := newTestMover(t, 50, 60, 10, 10)
+
+	s.Add("walls", walls)
+	s.Add("mover", mover)
+
+	mover.Velocity = topdown.Vec(60, 0)
+
+	s.MoveCollide(1)
+
+	// stopped by the lower wall shape, which stays put
+	assert.InDelta(t, 90, mover.Position.X, 0.01)
+	assert.Equal(t, cirno.NewVector(100, 60), wallB.Center())
+
+	hit, found := s.Raycast(&movecollide.Ray{
+		Origin:    cirno.NewVector(50, 40),
+		Direction: cirno.NewVector(1, 0),
+	})
+
+	require.True(t, found)
+	assert.Equal(t, "walls", hit.ID)
+	assert.Equal(t, walls, hit.Object)
+
+	s.Remove("walls")
+
+	s.MoveCollide(1)
+
+	assert.InDelta(t, 150, mover.Position.X, 0.01)
+}
+
+func TestStaticCornerContactIsDeterministic(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		s := newWholeMoveSystem(t)
+
+		// an L-shaped corner of two shapes, both reached at the same time
+		side := newTaggedRect(t, 70, 50, 10, 40)
+		floor := newTaggedRect(t, 50, 70, 40, 10)
+		mover := newTestMover(t, 50, 50, 10, 10)
+
+		var contact *movecollide.Contact
+
+		mover.Resolver = movecollide.ResolverFunc(func(c *movecollide.Collision) cirno.Vector {
+			contact = c.Contact
+
+			return cirno.Zero()
+		})
+		mover.Velocity = topdown.Vec(20, 20)
+
+		s.Add("walls", &testStatic{Shapes: []cirno.Shape{floor, side}})
+		s.Add("mover", mover)
+
+		s.MoveCollide(1)
+
+		// the top-most shape wins the tie
+		require.NotNil(t, contact)
+		require.Equal(t, side, contact.Shape)
+		require.InDelta(t, -1, contact.Normal.X, 1e-6)
+	}
+}
+
+func (s *testStatic) StaticColliderShapes() []cirno.Shape {
+	return s.Shapes
+}
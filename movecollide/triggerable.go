@@ -7,7 +7,14 @@ import "github.com/zergon321/cirno"
 // Triggerable is a component used in the move-collide system.
 type Triggerable interface {
 	TriggerShape() cirno.Shape
-	TriggerEnter(cirno.Shape)
-	TriggerRemain(cirno.Shape)
-	TriggerExit(cirno.Shape)
+	TriggerEnter(*Trigger)
+	TriggerRemain(*Trigger)
+	TriggerExit(*Trigger)
+}
+
+// Trigger describes the other party overlapping a trigger shape.
+type Trigger struct {
+	ID     string
+	Object any
+	Shape  cirno.Shape
 }
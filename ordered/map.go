@@ -0,0 +1,180 @@
+package ordered
+
+import "golang.org/x/exp/slices"
+
+// Map is a map that keeps its entries in a stable order: by priority
+// (lowest first), then in the order the keys were first added.
+type Map[K comparable, V any] struct {
+	entries []*entry[K, V]
+	index   map[K]*entry[K, V]
+	nextSeq uint64
+	sorted  bool
+}
+
+// Prioritized is an optional interface for an entity to pick its priority
+// in the systems that process entities in order. Otherwise, priority is zero.
+type Prioritized interface {
+	Priority() int
+}
+
+type entry[K comparable, V any] struct {
+	key      K
+	val      V
+	priority int
+	seq      uint64
+}
+
+// NewMap makes an empty map.
+func NewMap[K comparable, V any]() *Map[K, V] {
+	return &Map[K, V]{
+		entries: []*entry[K, V]{},
+		index:   map[K]*entry[K, V]{},
+		sorted:  true,
+	}
+}
+
+// PriorityOf gets the priority of a Prioritized value, or zero.
+func PriorityOf(x any) int {
+	if p, ok := x.(Prioritized); ok {
+		return p.Priority()
+	}
+
+	return 0
+}
+
+// Set adds a value with zero priority, or replaces the value of an existing
+// key without changing its order.
+func (m *Map[K, V]) Set(key K, val V) {
+	if e, found := m.index[key]; found {
+		e.val = val
+
+		return
+	}
+
+	m.SetWithPriority(key, val, 0)
+}
+
+// SetWithPriority adds or replaces a value, placing it by the given priority.
+// A key that already exists keeps its original place among equal priorities.
+func (m *Map[K, V]) SetWithPriority(key K, val V, priority int) {
+	if e, found := m.index[key]; found {
+		e.val = val
+
+		if e.priority != priority {
+			e.priority = priority
+			m.sorted = false
+		}
+
+		return
+	}
+
+	e := &entry[K, V]{key: key, val: val, priority: priority, seq: m.nextSeq}
+
+	m.nextSeq++
+
+	m.entries = append(m.entries, e)
+	m.index[key] = e
+
+	if priority != 0 {
+		m.sorted = false
+	}
+}
+
+// Get gets the value for a key.
+func (m *Map[K, V]) Get(key K) (V, bool) {
+	e, found := m.index[key]
+	if !found {
+		var zero V
+
+		return zero, false
+	}
+
+	return e.val, true
+}
+
+// Has checks if the key is present.
+func (m *Map[K, V]) Has(key K) bool {
+	_, found := m.index[key]
+
+	return found
+}
+
+// Delete removes a key. Returns true if the key was found.
+func (m *Map[K, V]) Delete(key K) bool {
+	e, found := m.index[key]
+	if !found {
+		return false
+	}
+
+	delete(m.index, key)
+
+	idx := slices.Index(m.entries, e)
+
+	m.entries = slices.Delete(m.entries, idx, idx+1)
+
+	return true
+}
+
+// Clear removes all the keys.
+func (m *Map[K, V]) Clear() {
+	m.entries = []*entry[K, V]{}
+	m.index = map[K]*entry[K, V]{}
+	m.sorted = true
+}
+
+// Len gets the number of keys.
+func (m *Map[K, V]) Len() int {
+	return len(m.entries)
+}
+
+// Keys gets the keys in order.
+func (m *Map[K, V]) Keys() []K {
+	m.sort()
+
+	keys := make([]K, len(m.entries))
+
+	for i, e := range m.entries {
+		keys[i] = e.key
+	}
+
+	return keys
+}
+
+// Values gets the values in order.
+func (m *Map[K, V]) Values() []V {
+	m.sort()
+
+	vals := make([]V, len(m.entries))
+
+	for i, e := range m.entries {
+		vals[i] = e.val
+	}
+
+	return vals
+}
+
+// Each calls the function for each key and value in order. The map may be
+// changed by the function, which only affects later calls to Each.
+func (m *Map[K, V]) Each(f func(key K, val V)) {
+	m.sort()
+
+	for _, e := range slices.Clone(m.entries) {
+		f(e.key, e.val)
+	}
+}
+
+func (m *Map[K, V]) sort() {
+	if m.sorted {
+		return
+	}
+
+	slices.SortStableFunc(m.entries, func(a, b *entry[K, V]) bool {
+		if a.priority != b.priority {
+			return a.priority < b.priority
+		}
+
+		return a.seq < b.seq
+	})
+
+	m.sorted = true
+}
@@ -0,0 +1,95 @@
+package ordered_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jamestunnell/topdown/ordered"
+)
+
+type prioritized int
+
+func TestMapInsertionOrder(t *testing.T) {
+	m := ordered.NewMap[string, int]()
+
+	for i, key := range []string{"c", "a", "d", "b"} {
+		m.Set(key, i)
+	}
+
+	assert.Equal(t, []string{"c", "a", "d", "b"}, m.Keys())
+	assert.Equal(t, []int{0, 1, 2, 3}, m.Values())
+	assert.Equal(t, 4, m.Len())
+
+	// replacing keeps the original place
+	m.Set("c", 10)
+
+	assert.Equal(t, []string{"c", "a", "d", "b"}, m.Keys())
+
+	val, found := m.Get("c")
+
+	assert.True(t, found)
+	assert.Equal(t, 10, val)
+
+	assert.True(t, m.Delete("a"))
+	assert.False(t, m.Delete("a"))
+	assert.False(t, m.Has("a"))
+
+	// re-adding goes to the end
+	m.Set("a", 1)
+
+	assert.Equal(t, []string{"c", "d", "b", "a"}, m.Keys())
+
+	m.Clear()
+
+	assert.Empty(t, m.Keys())
+
+	_, found = m.Get("c")
+
+	assert.False(t, found)
+}
+
+func TestMapPriority(t *testing.T) {
+	m := ordered.NewMap[string, int]()
+
+	m.SetWithPriority("late", 0, 10)
+	m.Set("a", 0)
+	m.SetWithPriority("early", 0, -5)
+	m.Set("b", 0)
+	m.SetWithPriority("alsoEarly", 0, -5)
+
+	assert.Equal(t, []string{"early", "alsoEarly", "a", "b", "late"}, m.Keys())
+
+	m.SetWithPriority("a", 0, 20)
+
+	assert.Equal(t, []string{"early", "alsoEarly", "b", "late", "a"}, m.Keys())
+}
+
+func TestMapEach(t *testing.T) {
+	m := ordered.NewMap[string, int]()
+
+	m.Set("a", 1)
+	m.Set("b", 2)
+	m.Set("c", 3)
+
+	keys := []string{}
+
+	m.Each(func(key string, val int) {
+		keys = append(keys, key)
+
+		// changes made during iteration don't affect it
+		m.Delete("c")
+	})
+
+	assert.Equal(t, []string{"a", "b", "c"}, keys)
+	assert.Equal(t, []string{"a", "b"}, m.Keys())
+}
+
+func TestPriorityOf(t *testing.T) {
+	assert.Equal(t, 0, ordered.PriorityOf("x"))
+	assert.Equal(t, 7, ordered.PriorityOf(prioritized(7)))
+}
+
+func (p prioritized) Priority() int {
+	return int(p)
+}
@@ -0,0 +1,158 @@
+package physics
+
+import (
+	"math"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/movecollide"
+)
+
+// Body is a simple rigid body, moved by the move-collide system using its
+// velocity. Embed a body to make an entity movable, then add a collider and
+// pick a collision resolver (like the physics resolver) to make it collidable.
+type Body struct {
+	Position topdown.Vector `json:"position"`
+	Velocity topdown.Vector `json:"velocity"`
+	// Mass resists impulses and forces. A body without positive mass is static.
+	Mass float64 `json:"mass"`
+	// Friction is the speed lost per second, as on rough ground.
+	Friction float64 `json:"friction,omitempty"`
+	// Drag is the fraction of velocity lost per second, as in water.
+	Drag float64 `json:"drag,omitempty"`
+	// Restitution is the fraction of speed kept after bouncing off something.
+	Restitution float64 `json:"restitution,omitempty"`
+	// MaxSpeed limits the speed, unless it is not positive.
+	MaxSpeed float64 `json:"maxSpeed,omitempty"`
+
+	force   topdown.Vector
+	surface *movecollide.Surface
+}
+
+// Physical is implemented by anything that embeds a body.
+type Physical interface {
+	PhysicsBody() *Body
+}
+
+// BodySchemaStr is the JSON schema for a body.
+const BodySchemaStr = `{
+  "$id": "https://github.com/jamestunnell/topdown/body.json",
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "Body",
+  "description": "Simple rigid body.",
+  "type": "object",
+  "required": ["mass"],
+  "properties": {
+    "position": { "$ref": "https://github.com/jamestunnell/topdown/vector.json" },
+    "velocity": { "$ref": "https://github.com/jamestunnell/topdown/vector.json" },
+    "mass": { "type": "number", "minimum": 0 },
+    "friction": { "type": "number", "minimum": 0 },
+    "drag": { "type": "number", "minimum": 0 },
+    "restitution": { "type": "number", "minimum": 0, "maximum": 1 },
+    "maxSpeed": { "type": "number", "minimum": 0 }
+  }
+}`
+
+// PhysicsBody gets the body.
+func (b *Body) PhysicsBody() *Body {
+	return b
+}
+
+// Static checks if the body has no mass, so that it never moves.
+func (b *Body) Static() bool {
+	return b.Mass <= 0
+}
+
+// InverseMass is zero for a static body.
+func (b *Body) InverseMass() float64 {
+	if b.Static() {
+		return 0
+	}
+
+	return 1 / b.Mass
+}
+
+// ApplyImpulse changes the velocity right away, as from a hit or knockback.
+func (b *Body) ApplyImpulse(impulse topdown.Vector) {
+	b.Velocity = b.Velocity.Add(impulse.Multiply(b.InverseMass()))
+}
+
+// ApplyForce accelerates the body during the next movement.
+func (b *Body) ApplyForce(force topdown.Vector) {
+	b.force = b.force.Add(force)
+}
+
+// SetSurface sets the surface under the body, which scales its friction
+// and movement.
+func (b *Body) SetSurface(s *movecollide.Surface) {
+	b.surface = s
+}
+
+// Surface gets the surface under the body, which is nil if there is none.
+func (b *Body) Surface() *movecollide.Surface {
+	return b.surface
+}
+
+// PlanMovement integrates the velocity, using the forces applied since
+// the last movement, drag and friction. The movement is scaled by the
+// surface speed factor, without changing the velocity.
+func (b *Body) PlanMovement(deltaSec float64) topdown.Vector {
+	force := b.force
+
+	b.force = topdown.Vector{}
+
+	if b.Static() {
+		b.Velocity = topdown.Vector{}
+
+		return topdown.Vector{}
+	}
+
+	b.Velocity = b.Velocity.Add(force.Multiply(deltaSec / b.Mass))
+
+	if b.Drag > 0 {
+		b.Velocity = b.Velocity.Multiply(math.Max(0, 1-b.Drag*deltaSec))
+	}
+
+	speed := b.Velocity.Magnitude()
+
+	if friction := b.Friction * b.surface.FrictionFactor(); friction > 0 && speed > 0 {
+		speed = math.Max(0, speed-friction*deltaSec)
+	}
+
+	if b.MaxSpeed > 0 {
+		speed = math.Min(speed, b.MaxSpeed)
+	}
+
+	if speed == 0 {
+		b.Velocity = topdown.Vector{}
+	} else if speed != b.Velocity.Magnitude() {
+		b.Velocity = b.Velocity.Resize(speed)
+	}
+
+	return b.Velocity.Multiply(deltaSec * b.surface.SpeedFactor())
+}
+
+// Move changes the position.
+func (b *Body) Move(moveDiff topdown.Vector) {
+	b.Position = b.Position.Add(moveDiff)
+}
+
+// CollisionMass is infinite for a static body, so it can't be pushed.
+func (b *Body) CollisionMass() float64 {
+	if b.Static() {
+		return math.Inf(1)
+	}
+
+	return b.Mass
+}
+
+// Bounce reflects the part of the velocity going into the surface with
+// the given normal.
+func (b *Body) Bounce(normal topdown.Vector, restitution float64) {
+	if into := dot(b.Velocity, normal); into < 0 {
+		b.Velocity = b.Velocity.Add(normal.Multiply(-(1 + restitution) * into))
+	}
+}
+
+func dot(a, b topdown.Vector) float64 {
+	return a.X*b.X + a.Y*b.Y
+}
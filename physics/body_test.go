@@ -0,0 +1,106 @@
+package physics_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/movecollide"
+	"github.com/jamestunnell/topdown/physics"
+)
+
+func TestBodyImpulseAndForce(t *testing.T) {
+	b := &physics.Body{Mass: 2}
+
+	b.ApplyImpulse(topdown.Vec(10, 0))
+
+	assert.Equal(t, topdown.Vec(5, 0), b.Velocity)
+
+	b.ApplyForce(topdown.Vec(0, 4))
+
+	move := b.PlanMovement(0.5)
+
+	assert.Equal(t, topdown.Vec(5, 1), b.Velocity)
+	assert.Equal(t, topdown.Vec(2.5, 0.5), move)
+
+	// forces only last for one movement
+	b.PlanMovement(0.5)
+
+	assert.Equal(t, topdown.Vec(5, 1), b.Velocity)
+
+	b.Move(move)
+
+	assert.Equal(t, topdown.Vec(2.5, 0.5), b.Position)
+}
+
+func TestBodyDragAndFriction(t *testing.T) {
+	b := &physics.Body{Mass: 1, Drag: 0.5, Velocity: topdown.Vec(10, 0)}
+
+	b.PlanMovement(1)
+
+	assert.InDelta(t, 5, b.Velocity.X, 1e-9)
+
+	b = &physics.Body{Mass: 1, Friction: 4, Velocity: topdown.Vec(0, 10)}
+
+	b.PlanMovement(1)
+
+	assert.InDelta(t, 6, b.Velocity.Y, 1e-9)
+
+	// friction stops, but never reverses
+	b.PlanMovement(2)
+
+	assert.Equal(t, topdown.Vector{}, b.Velocity)
+}
+
+func TestBodySurface(t *testing.T) {
+	b := &physics.Body{Mass: 1, Friction: 4, Velocity: topdown.Vec(10, 0)}
+
+	b.SetSurface(&movecollide.Surface{Name: "ice", FrictionScale: 0.25, SpeedScale: 0.5})
+
+	move := b.PlanMovement(1)
+
+	// slowed movement, but the velocity only loses the reduced friction
+	assert.InDelta(t, 9, b.Velocity.X, 1e-9)
+	assert.InDelta(t, 4.5, move.X, 1e-9)
+
+	b.SetSurface(nil)
+
+	move = b.PlanMovement(1)
+
+	assert.InDelta(t, 5, b.Velocity.X, 1e-9)
+	assert.InDelta(t, 5, move.X, 1e-9)
+	assert.Nil(t, b.Surface())
+}
+
+func TestBodyMaxSpeed(t *testing.T) {
+	b := &physics.Body{Mass: 1, MaxSpeed: 3, Velocity: topdown.Vec(3, 4)}
+
+	b.PlanMovement(1)
+
+	assert.InDelta(t, 3, b.Velocity.Magnitude(), 1e-9)
+}
+
+func TestStaticBody(t *testing.T) {
+	b := &physics.Body{Velocity: topdown.Vec(3, 4)}
+
+	b.ApplyImpulse(topdown.Vec(10, 0))
+
+	assert.True(t, b.Static())
+	assert.True(t, math.IsInf(b.CollisionMass(), 1))
+	assert.Equal(t, topdown.Vector{}, b.PlanMovement(1))
+}
+
+func TestBodyBounce(t *testing.T) {
+	b := &physics.Body{Mass: 1, Velocity: topdown.Vec(10, 5)}
+
+	b.Bounce(topdown.Vec(-1, 0), 0.5)
+
+	assert.Equal(t, topdown.Vec(-5, 5), b.Velocity)
+
+	// moving away from the surface already
+	b.Bounce(topdown.Vec(-1, 0), 0.5)
+
+	assert.Equal(t, topdown.Vec(-5, 5), b.Velocity)
+}
@@ -0,0 +1,74 @@
+package physics
+
+import (
+	"math"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/movecollide"
+	"github.com/zergon321/cirno"
+)
+
+// ResolverType is the name the physics resolver is registered under, so
+// that collidables can pick it by name.
+const ResolverType = "physics"
+
+type resolver struct {
+	slide movecollide.Resolver
+}
+
+func init() {
+	movecollide.RegisterResolver(ResolverType, func(*movecollide.ResolverSpec) (movecollide.Resolver, error) {
+		return NewResolver(), nil
+	})
+}
+
+// NewResolver makes a resolver for bodies. On contact, an impulse is
+// exchanged with the other body (or a static surface) to change both
+// velocities, and the movement slides along the surface.
+func NewResolver() movecollide.Resolver {
+	return &resolver{slide: movecollide.NewSlideResolver()}
+}
+
+func (r *resolver) Resolve(c *movecollide.Collision) cirno.Vector {
+	if p, ok := c.Object.(Physical); ok {
+		var other *Body
+
+		if op, ok := c.Contact.Object.(Physical); ok {
+			other = op.PhysicsBody()
+		}
+
+		n := c.Contact.Normal
+
+		Collide(p.PhysicsBody(), other, topdown.Vec(n.X, n.Y))
+	}
+
+	return r.slide.Resolve(c)
+}
+
+// Collide exchanges an impulse between two bodies in contact, where the
+// normal points from the other body toward the first. A nil other body is
+// treated as a static surface. Nothing is done if the bodies are separating.
+func Collide(b, other *Body, normal topdown.Vector) {
+	relVel := b.Velocity
+	invMass := b.InverseMass()
+	restitution := b.Restitution
+
+	if other != nil {
+		relVel = relVel.Add(other.Velocity.Multiply(-1))
+		invMass += other.InverseMass()
+		restitution = math.Max(restitution, other.Restitution)
+	}
+
+	into := dot(relVel, normal)
+	if into >= 0 || invMass == 0 {
+		return
+	}
+
+	impulse := normal.Multiply(-(1 + restitution) * into / invMass)
+
+	b.ApplyImpulse(impulse)
+
+	if other != nil {
+		other.ApplyImpulse(impulse.Multiply(-1))
+	}
+}
@@ -0,0 +1,107 @@
+package physics_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zergon321/cirno"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/movecollide"
+	"github.com/jamestunnell/topdown/physics"
+)
+
+type testObject struct {
+	*physics.Body
+	Collider cirno.Shape
+	Resolver movecollide.Resolver
+}
+
+func TestCollide(t *testing.T) {
+	testCases := map[string]struct {
+		restitution float64
+		a, b        float64
+	}{
+		"inelastic": {restitution: 0, a: 10, b: 10},
+		"elastic":   {restitution: 1, a: 0, b: 20},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			a := &physics.Body{Mass: 1, Velocity: topdown.Vec(20, 0), Restitution: tc.restitution}
+			b := &physics.Body{Mass: 1}
+
+			physics.Collide(a, b, topdown.Vec(-1, 0))
+
+			assert.InDelta(t, tc.a, a.Velocity.X, 1e-9)
+			assert.InDelta(t, tc.b, b.Velocity.X, 1e-9)
+		})
+	}
+}
+
+func TestCollideWithStatic(t *testing.T) {
+	a := &physics.Body{Mass: 1, Velocity: topdown.Vec(20, 0), Restitution: 1}
+
+	physics.Collide(a, nil, topdown.Vec(-1, 0))
+
+	assert.Equal(t, topdown.Vec(-20, 0), a.Velocity)
+
+	wall := &physics.Body{}
+
+	physics.Collide(a, wall, topdown.Vec(1, 0))
+
+	assert.Equal(t, topdown.Vec(20, 0), a.Velocity)
+	assert.Equal(t, topdown.Vector{}, wall.Velocity)
+}
+
+func TestResolverInSystem(t *testing.T) {
+	s, err := movecollide.NewSystem(200, 200)
+
+	require.NoError(t, err)
+
+	r, err := movecollide.NewResolver(&movecollide.ResolverSpec{Type: physics.ResolverType})
+
+	require.NoError(t, err)
+
+	player := newTestObject(t, 50, 50, 1, r)
+	crate := newTestObject(t, 80, 50, 1, r)
+
+	player.Velocity = topdown.Vec(40, 0)
+
+	s.Add("player", player)
+	s.Add("crate", crate)
+
+	s.MoveCollide(1)
+
+	// the player stops at the crate, sharing its momentum
+	assert.InDelta(t, 70, player.Position.X, 0.01)
+	assert.InDelta(t, 20, player.Velocity.X, 1e-9)
+	assert.InDelta(t, 20, crate.Velocity.X, 1e-9)
+
+	s.MoveCollide(1)
+
+	// the player follows, moving first since it was added first
+	assert.InDelta(t, 100, crate.Position.X, 0.01)
+	assert.InDelta(t, 85, player.Position.X, 0.01)
+}
+
+func newTestObject(t *testing.T, x, y, mass float64, r movecollide.Resolver) *testObject {
+	rect, err := cirno.NewRectangle(cirno.NewVector(x, y), 10, 10, 0)
+
+	require.NoError(t, err)
+
+	return &testObject{
+		Body:     &physics.Body{Position: topdown.Vec(x, y), Mass: mass},
+		Collider: rect,
+		Resolver: r,
+	}
+}
+
+func (o *testObject) ColliderShape() cirno.Shape {
+	return o.Collider
+}
+
+func (o *testObject) CollisionResolver() movecollide.Resolver {
+	return o.Resolver
+}
@@ -0,0 +1,96 @@
+package procgen
+
+import (
+	"math/rand"
+
+	"github.com/jamestunnell/topdown"
+)
+
+// Caves makes caves with cellular automata. Cells start as wall or floor
+// at random, then are smoothed: a cell becomes wall when at least 5 of
+// its 8 neighbors are walls, and floor when fewer than 4 are. Only the
+// largest cave is kept, and the border is wall.
+type Caves struct {
+	// Fill is the chance of a cell starting as wall, 0.45 by default.
+	Fill float64 `json:"fill,omitempty"`
+	// Steps is the number of smoothing steps, 4 by default.
+	Steps int `json:"steps,omitempty"`
+}
+
+const (
+	defaultCaveFill  = 0.45
+	defaultCaveSteps = 4
+)
+
+func (c *Caves) Generate(rng *rand.Rand, size topdown.Size[int]) [][]int {
+	fill, steps := c.Fill, c.Steps
+	if fill == 0 {
+		fill = defaultCaveFill
+	}
+
+	if steps == 0 {
+		steps = defaultCaveSteps
+	}
+
+	cells := newCells(size, Wall)
+
+	for row := range cells {
+		for col := range cells[row] {
+			if !onBorder(size, col, row) && rng.Float64() >= fill {
+				cells[row][col] = Floor
+			}
+		}
+	}
+
+	for i := 0; i < steps; i++ {
+		cells = smooth(cells, size)
+	}
+
+	keepLargestRegion(cells)
+
+	return cells
+}
+
+func smooth(cells [][]int, size topdown.Size[int]) [][]int {
+	next := newCells(size, Wall)
+
+	for row := range cells {
+		for col := range cells[row] {
+			if onBorder(size, col, row) {
+				continue
+			}
+
+			switch walls := countWalls(cells, col, row); {
+			case walls >= 5:
+				next[row][col] = Wall
+			case walls < 4:
+				next[row][col] = Floor
+			default:
+				next[row][col] = cells[row][col]
+			}
+		}
+	}
+
+	return next
+}
+
+// countWalls counts the walls around a cell. Cells beyond the edge count
+// as walls.
+func countWalls(cells [][]int, col, row int) int {
+	walls := 0
+
+	for r := row - 1; r <= row+1; r++ {
+		for c := col - 1; c <= col+1; c++ {
+			switch {
+			case r == row && c == col:
+				continue
+			case r < 0 || r >= len(cells) || c < 0 || c >= len(cells[r]):
+				walls++
+			case cells[r][c] == Wall:
+				walls++
+			}
+		}
+	}
+
+	return walls
+}
@@ -0,0 +1,157 @@
+package procgen
+
+import (
+	"math/rand"
+
+	"github.com/jamestunnell/topdown"
+)
+
+// Dungeon makes rooms joined by corridors, with binary space partitioning.
+// The map is split in two again and again until the parts are too small
+// to split, then a room is placed in each part. The rooms of each pair of
+// parts are joined by a corridor, so all the rooms can be reached.
+type Dungeon struct {
+	// MinLeafSize is the smallest width and height of the parts, 8 by default.
+	MinLeafSize int `json:"minLeafSize,omitempty"`
+	// MinRoomSize is the smallest width and height of the rooms, 3 by default.
+	MinRoomSize int `json:"minRoomSize,omitempty"`
+}
+
+// area is a rectangle of cells.
+type area struct {
+	col, row, cols, rows int
+}
+
+const (
+	defaultMinLeafSize = 8
+	defaultMinRoomSize = 3
+)
+
+func (d *Dungeon) Generate(rng *rand.Rand, size topdown.Size[int]) [][]int {
+	minLeaf, minRoom := d.MinLeafSize, d.MinRoomSize
+	if minLeaf == 0 {
+		minLeaf = defaultMinLeafSize
+	}
+
+	if minRoom == 0 {
+		minRoom = defaultMinRoomSize
+	}
+
+	g := &dungeonGen{rng: rng, minLeaf: minLeaf, minRoom: minRoom, cells: newCells(size, Wall)}
+
+	g.split(area{cols: size.Width, rows: size.Height})
+
+	return g.cells
+}
+
+type dungeonGen struct {
+	rng              *rand.Rand
+	minLeaf, minRoom int
+	cells            [][]int
+}
+
+// split splits an area, or places a room in it, and returns the center of
+// a room in the area.
+func (g *dungeonGen) split(a area) (topdown.Point[int], bool) {
+	canSplitCols := a.cols >= 2*g.minLeaf
+	canSplitRows := a.rows >= 2*g.minLeaf
+
+	if !canSplitCols && !canSplitRows {
+		return g.placeRoom(a)
+	}
+
+	var first, second area
+
+	splitCols := canSplitCols && (!canSplitRows || a.cols > a.rows || (a.cols == a.rows && g.rng.Intn(2) == 0))
+
+	if splitCols {
+		n := g.minLeaf + g.rng.Intn(a.cols-2*g.minLeaf+1)
+		first = area{col: a.col, row: a.row, cols: n, rows: a.rows}
+		second = area{col: a.col + n, row: a.row, cols: a.cols - n, rows: a.rows}
+	} else {
+		n := g.minLeaf + g.rng.Intn(a.rows-2*g.minLeaf+1)
+		first = area{col: a.col, row: a.row, cols: a.cols, rows: n}
+		second = area{col: a.col, row: a.row + n, cols: a.cols, rows: a.rows - n}
+	}
+
+	center1, found1 := g.split(first)
+	center2, found2 := g.split(second)
+
+	switch {
+	case found1 && found2:
+		g.carveCorridor(center1, center2)
+
+		return center1, true
+	case found1:
+		return center1, true
+	}
+
+	return center2, found2
+}
+
+// placeRoom carves a room of random size and place in an area, leaving
+// walls around it.
+func (g *dungeonGen) placeRoom(a area) (topdown.Point[int], bool) {
+	maxCols, maxRows := a.cols-2, a.rows-2
+	if maxCols < 1 || maxRows < 1 {
+		return topdown.Point[int]{}, false
+	}
+
+	cols := g.randomSize(maxCols)
+	rows := g.randomSize(maxRows)
+	col := a.col + 1 + g.rng.Intn(maxCols-cols+1)
+	row := a.row + 1 + g.rng.Intn(maxRows-rows+1)
+
+	for r := row; r < row+rows; r++ {
+		for c := col; c < col+cols; c++ {
+			g.cells[r][c] = Floor
+		}
+	}
+
+	return topdown.Pt(col+cols/2, row+rows/2), true
+}
+
+// randomSize gets a room size between the min room size and the max.
+func (g *dungeonGen) randomSize(max int) int {
+	if max <= g.minRoom {
+		return max
+	}
+
+	return g.minRoom + g.rng.Intn(max-g.minRoom+1)
+}
+
+// carveCorridor carves an L-shaped corridor between two cells, turning
+// at one of the two corners.
+func (g *dungeonGen) carveCorridor(from, to topdown.Point[int]) {
+	corner := topdown.Pt(to.X, from.Y)
+	if g.rng.Intn(2) == 0 {
+		corner = topdown.Pt(from.X, to.Y)
+	}
+
+	g.carveLine(from, corner)
+	g.carveLine(corner, to)
+}
+
+func (g *dungeonGen) carveLine(from, to topdown.Point[int]) {
+	for c := minInt(from.X, to.X); c <= maxInt(from.X, to.X); c++ {
+		for r := minInt(from.Y, to.Y); r <= maxInt(from.Y, to.Y); r++ {
+			g.cells[r][c] = Floor
+		}
+	}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
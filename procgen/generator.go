@@ -0,0 +1,134 @@
+package procgen
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/tilegrid"
+)
+
+// Terrains made by the cave, dungeon and walk generators. The noise
+// generator makes a terrain for each band between its thresholds.
+const (
+	Floor = iota
+	Wall
+)
+
+// Generator makes a grid of terrains, by row. All the randomness comes
+// from the given source, so the same seed makes the same grid.
+type Generator interface {
+	Generate(rng *rand.Rand, size topdown.Size[int]) [][]int
+}
+
+// Generate makes a grid of terrains with a seeded generator.
+func Generate(g Generator, seed int64, size topdown.Size[int]) [][]int {
+	return g.Generate(rand.New(rand.NewSource(seed)), size)
+}
+
+// TileRows makes tile rows from a grid of terrains, with the tile ID for
+// each terrain.
+func TileRows(cells [][]int, tiles []string) ([]string, error) {
+	tileRows := make([]string, len(cells))
+
+	for row, terrains := range cells {
+		tileIDs := make([]string, len(terrains))
+
+		for col, terrain := range terrains {
+			if terrain < 0 || terrain >= len(tiles) {
+				return nil, fmt.Errorf("terrain %d at %d,%d has no tile", terrain, col, row)
+			}
+
+			tileIDs[col] = tiles[terrain]
+		}
+
+		tileRows[row] = strings.Join(tileIDs, tilegrid.RefIDSeparator)
+	}
+
+	return tileRows, nil
+}
+
+func newCells(size topdown.Size[int], terrain int) [][]int {
+	cells := make([][]int, size.Height)
+
+	for row := range cells {
+		cells[row] = make([]int, size.Width)
+
+		for col := range cells[row] {
+			cells[row][col] = terrain
+		}
+	}
+
+	return cells
+}
+
+func onBorder(size topdown.Size[int], col, row int) bool {
+	return col == 0 || row == 0 || col == size.Width-1 || row == size.Height-1
+}
+
+// regions finds the regions of connected cells with a terrain, where
+// cells connect to the 4 cells next to them.
+func regions(cells [][]int, terrain int) [][]topdown.Point[int] {
+	seen := map[topdown.Point[int]]bool{}
+	found := [][]topdown.Point[int]{}
+
+	for row := range cells {
+		for col := range cells[row] {
+			start := topdown.Pt(col, row)
+			if cells[row][col] != terrain || seen[start] {
+				continue
+			}
+
+			region := []topdown.Point[int]{}
+			queue := []topdown.Point[int]{start}
+
+			seen[start] = true
+
+			for len(queue) > 0 {
+				cell := queue[0]
+				queue = queue[1:]
+				region = append(region, cell)
+
+				for _, d := range []topdown.Point[int]{{X: 1}, {X: -1}, {Y: 1}, {Y: -1}} {
+					next := cell.Add(d)
+					if next.Y < 0 || next.Y >= len(cells) || next.X < 0 || next.X >= len(cells[next.Y]) {
+						continue
+					}
+
+					if cells[next.Y][next.X] == terrain && !seen[next] {
+						seen[next] = true
+						queue = append(queue, next)
+					}
+				}
+			}
+
+			found = append(found, region)
+		}
+	}
+
+	return found
+}
+
+// keepLargestRegion fills all but the largest region of floor with wall,
+// so every floor cell can be reached.
+func keepLargestRegion(cells [][]int) {
+	floors := regions(cells, Floor)
+	largest := 0
+
+	for i, region := range floors {
+		if len(region) > len(floors[largest]) {
+			largest = i
+		}
+	}
+
+	for i, region := range floors {
+		if i == largest {
+			continue
+		}
+
+		for _, cell := range region {
+			cells[cell.Y][cell.X] = Wall
+		}
+	}
+}
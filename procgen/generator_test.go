@@ -0,0 +1,154 @@
+package procgen_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/procgen"
+)
+
+var testSize = topdown.Size[int]{Width: 48, Height: 32}
+
+func TestGeneratorsAreSeeded(t *testing.T) {
+	generators := map[string]procgen.Generator{
+		"caves":   &procgen.Caves{},
+		"dungeon": &procgen.Dungeon{},
+		"noise":   &procgen.Noise{Thresholds: []float64{0.5}},
+		"walk":    &procgen.DrunkardsWalk{},
+	}
+
+	for name, g := range generators {
+		t.Run(name, func(t *testing.T) {
+			cells := procgen.Generate(g, 1, testSize)
+
+			require.Len(t, cells, testSize.Height)
+			assert.Len(t, cells[0], testSize.Width)
+			assert.Equal(t, cells, procgen.Generate(g, 1, testSize))
+			assert.NotEqual(t, cells, procgen.Generate(g, 2, testSize))
+		})
+	}
+}
+
+func TestWallsAroundFloor(t *testing.T) {
+	generators := map[string]procgen.Generator{
+		"caves":   &procgen.Caves{Fill: 0.4, Steps: 5},
+		"dungeon": &procgen.Dungeon{MinLeafSize: 6, MinRoomSize: 2},
+		"walk":    &procgen.DrunkardsWalk{Floor: 0.3},
+	}
+
+	for name, g := range generators {
+		t.Run(name, func(t *testing.T) {
+			cells := procgen.Generate(g, 7, testSize)
+			floor := countTerrain(cells, procgen.Floor)
+
+			assert.Greater(t, floor, 0)
+
+			for row := range cells {
+				for col := range cells[row] {
+					if row == 0 || col == 0 || row == testSize.Height-1 || col == testSize.Width-1 {
+						assert.Equal(t, procgen.Wall, cells[row][col])
+					}
+				}
+			}
+
+			// all the floor can be reached from any floor cell
+			assert.Equal(t, floor, reachable(cells))
+		})
+	}
+}
+
+func TestDrunkardsWalkFloor(t *testing.T) {
+	cells := procgen.Generate(&procgen.DrunkardsWalk{Floor: 0.25}, 3, testSize)
+
+	assert.Equal(t, testSize.Width*testSize.Height/4, countTerrain(cells, procgen.Floor))
+}
+
+func TestNoiseBands(t *testing.T) {
+	n := &procgen.Noise{Scale: 6, Octaves: 3, Thresholds: []float64{0.3, 0.5, 0.7}}
+	cells := procgen.Generate(n, 5, testSize)
+
+	for terrain := 0; terrain < 4; terrain++ {
+		assert.Greater(t, countTerrain(cells, terrain), 0, "terrain %d", terrain)
+	}
+
+	assert.Equal(t, testSize.Width*testSize.Height, countTerrain(cells, 0)+countTerrain(cells, 1)+
+		countTerrain(cells, 2)+countTerrain(cells, 3))
+}
+
+func TestNoiseOctavesStopAtCellSize(t *testing.T) {
+	// octaves at scales of 8, 4, 2 and 1 cells, and none finer
+	want := (&procgen.Noise{Scale: 8, Octaves: 4}).Values(rand.New(rand.NewSource(7)), testSize)
+	got := (&procgen.Noise{Scale: 8, Octaves: 12}).Values(rand.New(rand.NewSource(7)), testSize)
+
+	assert.Equal(t, want, got)
+
+	// scales below a cell are raised to one cell
+	want = (&procgen.Noise{Scale: 1}).Values(rand.New(rand.NewSource(7)), testSize)
+	got = (&procgen.Noise{Scale: 0.001}).Values(rand.New(rand.NewSource(7)), testSize)
+
+	assert.Equal(t, want, got)
+}
+
+func TestTileRows(t *testing.T) {
+	tileRows, err := procgen.TileRows([][]int{{0, 1}, {1, 1}}, []string{"F", "W"})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"F W", "W W"}, tileRows)
+
+	_, err = procgen.TileRows([][]int{{0, 2}}, []string{"F", "W"})
+
+	assert.Error(t, err)
+}
+
+func countTerrain(cells [][]int, terrain int) int {
+	n := 0
+
+	for row := range cells {
+		for col := range cells[row] {
+			if cells[row][col] == terrain {
+				n++
+			}
+		}
+	}
+
+	return n
+}
+
+// reachable counts the floor cells that can be reached from the first one.
+func reachable(cells [][]int) int {
+	var start *topdown.Point[int]
+
+	for row := range cells {
+		for col := range cells[row] {
+			if start == nil && cells[row][col] == procgen.Floor {
+				start = &topdown.Point[int]{X: col, Y: row}
+			}
+		}
+	}
+
+	if start == nil {
+		return 0
+	}
+
+	seen := map[topdown.Point[int]]bool{*start: true}
+	queue := []topdown.Point[int]{*start}
+
+	for len(queue) > 0 {
+		cell := queue[0]
+		queue = queue[1:]
+
+		for _, d := range []topdown.Point[int]{{X: 1}, {X: -1}, {Y: 1}, {Y: -1}} {
+			next := cell.Add(d)
+			if !seen[next] && cells[next.Y][next.X] == procgen.Floor {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	return len(seen)
+}
@@ -0,0 +1,147 @@
+package procgen
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/resource"
+	"github.com/jamestunnell/topdown/tilegrid"
+)
+
+// Map is a tile grid made by a generator when it is initialized, with
+// spawn points placed on it. The grid settings, like the tile size and
+// tile links, are set as for a background. Exactly one generator is set.
+type Map struct {
+	*tilegrid.TileGrid
+
+	Seed int64 `json:"seed"`
+	// Size is the number of columns and rows.
+	Size    topdown.Size[int] `json:"size"`
+	Caves   *Caves            `json:"caves,omitempty"`
+	Dungeon *Dungeon          `json:"dungeon,omitempty"`
+	Noise   *Noise            `json:"noise,omitempty"`
+	Walk    *DrunkardsWalk    `json:"walk,omitempty"`
+	// Tiles has the tile ID for each terrain, like floor then wall.
+	Tiles      []string     `json:"tiles"`
+	SpawnRules []*SpawnRule `json:"spawns,omitempty"`
+
+	// Spawns are the spawn points placed by the spawn rules.
+	Spawns []*tilegrid.SpawnPoint `json:"-"`
+}
+
+// SpawnRule places spawn points on random cells with a terrain. No two
+// spawn points share a cell.
+type SpawnRule struct {
+	Name  string `json:"name,omitempty"`
+	Type  string `json:"type,omitempty"`
+	Count int    `json:"count"`
+	// Terrain is the terrain of the cells, which is floor by default.
+	Terrain int `json:"terrain,omitempty"`
+}
+
+// Generator gets the generator that is set.
+func (m *Map) Generator() (Generator, error) {
+	generators := []Generator{}
+
+	if m.Caves != nil {
+		generators = append(generators, m.Caves)
+	}
+
+	if m.Dungeon != nil {
+		generators = append(generators, m.Dungeon)
+	}
+
+	if m.Noise != nil {
+		generators = append(generators, m.Noise)
+	}
+
+	if m.Walk != nil {
+		generators = append(generators, m.Walk)
+	}
+
+	if len(generators) != 1 {
+		return nil, fmt.Errorf("map has %d generators instead of 1", len(generators))
+	}
+
+	return generators[0], nil
+}
+
+// Initialize generates the tile rows and initializes the grid, then
+// places the spawn points.
+func (m *Map) Initialize(mgr resource.Manager) error {
+	if m.Size.Width <= 0 || m.Size.Height <= 0 {
+		return fmt.Errorf("size %v is not positive", m.Size)
+	}
+
+	g, err := m.Generator()
+	if err != nil {
+		return err
+	}
+
+	if m.TileGrid == nil {
+		m.TileGrid = &tilegrid.TileGrid{}
+	}
+
+	rng := rand.New(rand.NewSource(m.Seed))
+	cells := g.Generate(rng, m.Size)
+
+	tileRows, err := TileRows(cells, m.Tiles)
+	if err != nil {
+		return fmt.Errorf("failed to make tile rows: %w", err)
+	}
+
+	m.TileRows = tileRows
+	m.TileData = nil
+
+	if err := m.TileGrid.Initialize(mgr); err != nil {
+		return fmt.Errorf("failed to initialize grid: %w", err)
+	}
+
+	spawns, err := m.placeSpawns(rng, cells)
+	if err != nil {
+		return fmt.Errorf("failed to place spawns: %w", err)
+	}
+
+	m.Spawns = spawns
+
+	return nil
+}
+
+func (m *Map) placeSpawns(rng *rand.Rand, cells [][]int) ([]*tilegrid.SpawnPoint, error) {
+	taken := map[topdown.Point[int]]bool{}
+	spawns := []*tilegrid.SpawnPoint{}
+
+	for i, rule := range m.SpawnRules {
+		free := []topdown.Point[int]{}
+
+		for row := range cells {
+			for col, terrain := range cells[row] {
+				if cell := topdown.Pt(col, row); terrain == rule.Terrain && !taken[cell] {
+					free = append(free, cell)
+				}
+			}
+		}
+
+		if rule.Count > len(free) {
+			return nil, fmt.Errorf("rule %d needs %d cells, but only %d are free", i, rule.Count, len(free))
+		}
+
+		for j := 0; j < rule.Count; j++ {
+			k := j + rng.Intn(len(free)-j)
+			free[j], free[k] = free[k], free[j]
+
+			taken[free[j]] = true
+
+			center := m.TileCenter(free[j])
+
+			spawns = append(spawns, &tilegrid.SpawnPoint{
+				Name:     rule.Name,
+				Type:     rule.Type,
+				Position: topdown.Vec(center.X, center.Y),
+			})
+		}
+	}
+
+	return spawns, nil
+}
@@ -0,0 +1,134 @@
+package procgen_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/procgen"
+	"github.com/jamestunnell/topdown/resource/restest"
+	"github.com/jamestunnell/topdown/tilegrid"
+)
+
+const cavesJSON = `{
+	"origin": {"x": 0, "y": 0},
+	"tileSize": {"w": 16, "h": 16},
+	"tileLinks": {"F": {}, "W": {"solid": true}},
+	"seed": 42,
+	"size": {"w": 40, "h": 30},
+	"caves": {"fill": 0.45},
+	"tiles": ["F", "W"],
+	"spawns": [
+		{"name": "start", "type": "player", "count": 1},
+		{"type": "bat", "count": 5}
+	]
+}`
+
+func TestMapType(t *testing.T) {
+	m := loadMap(t, cavesJSON)
+
+	require.Len(t, m.TileRows, 30)
+	assert.NotEmpty(t, m.StaticColliderShapes())
+	require.Len(t, m.Spawns, 6)
+	assert.Equal(t, "start", m.Spawns[0].Name)
+	assert.Equal(t, "player", m.Spawns[0].Type)
+	assert.Equal(t, "bat", m.Spawns[5].Type)
+
+	cells := map[topdown.Point[int]]bool{}
+
+	for _, spawn := range m.Spawns {
+		cell, inside := m.WorldToTile(topdown.Pt(spawn.Position.X, spawn.Position.Y))
+
+		require.True(t, inside)
+
+		tileID, _ := m.TileID(cell)
+
+		assert.Equal(t, "F", tileID)
+		assert.False(t, cells[cell], "spawns share cell %v", cell)
+
+		cells[cell] = true
+	}
+
+	// the same seed makes the same map
+	assert.Equal(t, m.TileRows, loadMap(t, cavesJSON).TileRows)
+}
+
+func TestMapInvalid(t *testing.T) {
+	testCases := map[string]*procgen.Map{
+		"no generator": {Size: testSize, Tiles: []string{"F", "W"}},
+		"two generators": {
+			Size: testSize, Caves: &procgen.Caves{}, Walk: &procgen.DrunkardsWalk{}, Tiles: []string{"F", "W"},
+		},
+		"no size":       {Caves: &procgen.Caves{}, Tiles: []string{"F", "W"}},
+		"missing tile":  {Size: testSize, Caves: &procgen.Caves{}, Tiles: []string{"F"}},
+		"unlinked tile": {Size: testSize, Caves: &procgen.Caves{}, Tiles: []string{"F", "X"}},
+		"too many spawns": {
+			Size:       topdown.Size[int]{Width: 4, Height: 4},
+			Walk:       &procgen.DrunkardsWalk{},
+			Tiles:      []string{"F", "W"},
+			SpawnRules: []*procgen.SpawnRule{{Count: 5}},
+		},
+	}
+
+	for name, m := range testCases {
+		t.Run(name, func(t *testing.T) {
+			m.TileGrid = &tilegrid.TileGrid{
+				TileSize:  topdown.Size[int]{Width: 16, Height: 16},
+				TileLinks: map[string]*tilegrid.TileLink{"F": {}, "W": {Solid: true}},
+			}
+
+			assert.Error(t, m.Initialize(nil))
+		})
+	}
+}
+
+func TestMapTypeInvalid(t *testing.T) {
+	testCases := map[string]string{
+		"no thresholds":    `{"scale": 4}`,
+		"too many octaves": `{"scale": 4, "octaves": 17, "thresholds": [0.5]}`,
+	}
+
+	for name, noise := range testCases {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			d := `{"origin": {"x": 0, "y": 0}, "tileSize": {"w": 16, "h": 16}, "tileLinks": {"F": {}},
+				"seed": 1, "size": {"w": 8, "h": 8}, "tiles": ["F"], "noise": ` + noise + `}`
+
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "bad.procgen"), []byte(d), 0o600))
+
+			mapType, err := procgen.NewMapType()
+
+			require.NoError(t, err)
+
+			_, err = mapType.Load(filepath.Join(dir, "bad.procgen"))
+
+			assert.Error(t, err)
+		})
+	}
+}
+
+func loadMap(t *testing.T, mapJSON string) *procgen.Map {
+	dir := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "caves.procgen"), []byte(mapJSON), 0o600))
+
+	mapType, err := procgen.NewMapType()
+
+	require.NoError(t, err)
+
+	mgr := restest.SetupManager(t, dir, mapType)
+
+	r, err := mgr.Get("caves.procgen")
+
+	require.NoError(t, err)
+
+	m, ok := r.(*procgen.Map)
+
+	require.True(t, ok)
+
+	return m
+}
@@ -0,0 +1,41 @@
+package procgen
+
+import (
+	"fmt"
+
+	"github.com/xeipuuv/gojsonschema"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/jsonfile"
+	"github.com/jamestunnell/topdown/movecollide"
+	"github.com/jamestunnell/topdown/resource"
+	"github.com/jamestunnell/topdown/tilegrid"
+)
+
+type MapType struct {
+	schema *gojsonschema.Schema
+}
+
+func NewMapType() (resource.Type, error) {
+	schema, err := resource.MakeJSONSchema(
+		MapSchemaStr,
+		topdown.VectorSchemaStr,
+		topdown.SizeSchemaStr,
+		topdown.PointSchemaStr,
+		tilegrid.TileLinkSchemaStr,
+		movecollide.ColliderSchemaStr,
+		movecollide.SurfaceSchemaStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make JSON schema: %w", err)
+	}
+
+	return &MapType{schema: schema}, nil
+}
+
+func (mt *MapType) Name() string {
+	return "procgen"
+}
+
+func (mt *MapType) Load(path string) (resource.Resource, error) {
+	return jsonfile.ReadAndValidate[*Map](path, mt.schema)
+}
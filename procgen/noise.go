@@ -0,0 +1,129 @@
+package procgen
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/jamestunnell/topdown"
+)
+
+// Noise makes terrain from value noise, like water, sand, grass and rock.
+// The noise ranges from 0 to 1, and the thresholds split it into bands.
+// A cell gets the number of thresholds at or below its noise value as its
+// terrain, so there is one more terrain than there are thresholds.
+type Noise struct {
+	// Scale is the size of the noise features in cells, 8 by default and
+	// at least 1.
+	Scale float64 `json:"scale,omitempty"`
+	// Octaves add finer detail, each at half the scale and amplitude of
+	// the one before. There is 1 octave by default, and octaves finer than
+	// a cell are left out, as they add nothing.
+	Octaves    int       `json:"octaves,omitempty"`
+	Thresholds []float64 `json:"thresholds"`
+}
+
+const defaultNoiseScale = 8
+
+// MaxNoiseOctaves is the most octaves that noise can have, as in the map
+// schema.
+const MaxNoiseOctaves = 16
+
+func (n *Noise) Generate(rng *rand.Rand, size topdown.Size[int]) [][]int {
+	values := n.Values(rng, size)
+	cells := newCells(size, 0)
+
+	for row := range cells {
+		for col := range cells[row] {
+			for _, threshold := range n.Thresholds {
+				if values[row][col] >= threshold {
+					cells[row][col]++
+				}
+			}
+		}
+	}
+
+	return cells
+}
+
+// Values makes the noise values, by row.
+func (n *Noise) Values(rng *rand.Rand, size topdown.Size[int]) [][]float64 {
+	scale, octaves := n.Scale, n.Octaves
+	if scale <= 0 {
+		scale = defaultNoiseScale
+	}
+
+	// the lattice grows as the scale shrinks
+	scale = math.Max(scale, 1)
+
+	if octaves <= 0 {
+		octaves = 1
+	} else if octaves > MaxNoiseOctaves {
+		octaves = MaxNoiseOctaves
+	}
+
+	values := make([][]float64, size.Height)
+
+	for row := range values {
+		values[row] = make([]float64, size.Width)
+	}
+
+	amplitude, total := 1.0, 0.0
+
+	for i := 0; i < octaves && scale >= 1; i++ {
+		lattice := newLattice(rng, size, scale)
+
+		for row := range values {
+			for col := range values[row] {
+				values[row][col] += amplitude * lattice.sample(float64(col)/scale, float64(row)/scale)
+			}
+		}
+
+		total += amplitude
+		amplitude /= 2
+		scale /= 2
+	}
+
+	for row := range values {
+		for col := range values[row] {
+			values[row][col] /= total
+		}
+	}
+
+	return values
+}
+
+// lattice has random values at points a scale apart, which are smoothly
+// blended between.
+type lattice [][]float64
+
+func newLattice(rng *rand.Rand, size topdown.Size[int], scale float64) lattice {
+	l := make(lattice, int(float64(size.Height)/scale)+2)
+
+	for i := range l {
+		l[i] = make([]float64, int(float64(size.Width)/scale)+2)
+
+		for j := range l[i] {
+			l[i][j] = rng.Float64()
+		}
+	}
+
+	return l
+}
+
+func (l lattice) sample(x, y float64) float64 {
+	col, row := int(x), int(y)
+	tx, ty := smoothstep(x-float64(col)), smoothstep(y-float64(row))
+
+	top := lerp(l[row][col], l[row][col+1], tx)
+	bottom := lerp(l[row+1][col], l[row+1][col+1], tx)
+
+	return lerp(top, bottom, ty)
+}
+
+func smoothstep(t float64) float64 {
+	return t * t * (3 - 2*t)
+}
+
+func lerp(a, b, t float64) float64 {
+	return a + (b-a)*t
+}
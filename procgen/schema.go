@@ -0,0 +1,83 @@
+package procgen
+
+const MapSchemaStr = `{
+  "$id": "https://github.com/jamestunnell/topdown/procgen.json",
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "Generated map",
+  "description": "Tile grid made by a seeded generator, with spawn points.",
+  "type": "object",
+  "required": ["origin", "tileSize", "tileLinks", "seed", "size", "tiles"],
+  "oneOf": [
+	{"required": ["caves"]},
+	{"required": ["dungeon"]},
+	{"required": ["noise"]},
+	{"required": ["walk"]}
+  ],
+  "properties": {
+	"origin": { "$ref": "https://github.com/jamestunnell/topdown/vector.json" },
+	"tileSize": { "$ref": "https://github.com/jamestunnell/topdown/size.json" },
+	"tileLinks": {
+		"type": "object",
+		"patternProperties" :{
+			".*": { "$ref": "https://github.com/jamestunnell/topdown/tilelink.json" }
+		}
+	},
+	"drawLayer": {"type": "integer"},
+	"opacity": {"type": "number", "minimum": 0, "maximum": 1},
+	"hidden": {"type": "boolean"},
+	"uncached": {"type": "boolean"},
+	"projection": {"enum": ["orthogonal", "isometric", "staggered", "hexPointy", "hexFlat"]},
+	"seed": {"type": "integer"},
+	"size": { "$ref": "https://github.com/jamestunnell/topdown/size.json" },
+	"caves": {
+		"type": "object",
+		"properties": {
+			"fill": {"type": "number", "minimum": 0, "maximum": 1},
+			"steps": {"type": "integer", "minimum": 0}
+		}
+	},
+	"dungeon": {
+		"type": "object",
+		"properties": {
+			"minLeafSize": {"type": "integer", "minimum": 3},
+			"minRoomSize": {"type": "integer", "minimum": 1}
+		}
+	},
+	"noise": {
+		"type": "object",
+		"required": ["thresholds"],
+		"properties": {
+			"scale": {"type": "number", "exclusiveMinimum": 0},
+			"octaves": {"type": "integer", "minimum": 1, "maximum": 16},
+			"thresholds": {
+				"type": "array",
+				"items": {"type": "number", "minimum": 0, "maximum": 1}
+			}
+		}
+	},
+	"walk": {
+		"type": "object",
+		"properties": {
+			"floor": {"type": "number", "minimum": 0, "maximum": 1}
+		}
+	},
+	"tiles": {
+		"type": "array",
+		"items": {"type": "string", "minLength": 1},
+		"minItems": 1
+	},
+	"spawns": {
+		"type": "array",
+		"items": {
+			"type": "object",
+			"required": ["count"],
+			"properties": {
+				"name": {"type": "string"},
+				"type": {"type": "string"},
+				"count": {"type": "integer", "minimum": 0},
+				"terrain": {"type": "integer", "minimum": 0}
+			}
+		}
+	}
+  }
+}`
@@ -0,0 +1,56 @@
+package procgen
+
+import (
+	"math/rand"
+
+	"github.com/jamestunnell/topdown"
+)
+
+// DrunkardsWalk carves floor by walking at random from the center of the
+// map, until enough of the map is floor. The border is wall.
+type DrunkardsWalk struct {
+	// Floor is the part of the map to carve into floor, 0.4 by default.
+	Floor float64 `json:"floor,omitempty"`
+}
+
+const (
+	defaultWalkFloor = 0.4
+	// maxStepsPerCell limits the walk on maps where the floor is hard to reach.
+	maxStepsPerCell = 100
+)
+
+func (w *DrunkardsWalk) Generate(rng *rand.Rand, size topdown.Size[int]) [][]int {
+	floor := w.Floor
+	if floor == 0 {
+		floor = defaultWalkFloor
+	}
+
+	cells := newCells(size, Wall)
+
+	if size.Width < 3 || size.Height < 3 {
+		return cells
+	}
+
+	target := int(floor * float64(size.Width*size.Height))
+	if inner := (size.Width - 2) * (size.Height - 2); target > inner {
+		target = inner
+	}
+
+	directions := []topdown.Point[int]{{X: 1}, {X: -1}, {Y: 1}, {Y: -1}}
+	cell := topdown.Pt(size.Width/2, size.Height/2)
+	carved := 0
+
+	for steps := 0; carved < target && steps < maxStepsPerCell*size.Width*size.Height; steps++ {
+		if cells[cell.Y][cell.X] == Wall {
+			cells[cell.Y][cell.X] = Floor
+			carved++
+		}
+
+		next := cell.Add(directions[rng.Intn(len(directions))])
+		if !onBorder(size, next.X, next.Y) {
+			cell = next
+		}
+	}
+
+	return cells
+}
@@ -2,6 +2,7 @@ package sprite
 
 import (
 	"fmt"
+	"strconv"
 
 	mapset "github.com/deckarep/golang-set/v2"
 	"github.com/xeipuuv/gojsonschema"
@@ -64,6 +65,27 @@ func NewSheet(imageRef string, sprites ...*Sprite) *Sheet {
 	}
 }
 
+// GridSprites makes sprites for a grid of equal-size tiles, in rows of
+// the given number of columns, with the tile index as the sprite ID.
+// The grid starts at the margin, and tiles are separated by the spacing.
+func GridSprites(size topdown.Size[int], count, columns, margin, spacing int) []*Sprite {
+	sprites := make([]*Sprite, count)
+
+	for i := range sprites {
+		col, row := i%columns, i/columns
+
+		sprites[i] = &Sprite{
+			ID: strconv.Itoa(i),
+			Origin: topdown.Pt(
+				margin+col*(size.Width+spacing),
+				margin+row*(size.Height+spacing)),
+			Size: size,
+		}
+	}
+
+	return sprites
+}
+
 func NewSheetType() (resource.Type, error) {
 	schema, err := resource.MakeJSONSchema(
 		SheetSchemaStr, topdown.SizeSchemaStr, topdown.PointSchemaStr)
@@ -67,3 +67,17 @@ func TestImageSet(t *testing.T) {
 
 	assert.False(t, found)
 }
+
+func TestGridSprites(t *testing.T) {
+	sprites := sprite.GridSprites(topdown.Sz(16, 8), 5, 2, 1, 2)
+
+	require.Len(t, sprites, 5)
+
+	assert.Equal(t, "0", sprites[0].ID)
+	assert.Equal(t, topdown.Pt(1, 1), sprites[0].Origin)
+	assert.Equal(t, topdown.Pt(19, 1), sprites[1].Origin)
+	assert.Equal(t, topdown.Pt(1, 11), sprites[2].Origin)
+	assert.Equal(t, "4", sprites[4].ID)
+	assert.Equal(t, topdown.Pt(1, 21), sprites[4].Origin)
+	assert.Equal(t, topdown.Sz(16, 8), sprites[4].Size)
+}
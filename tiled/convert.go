@@ -0,0 +1,465 @@
+package tiled
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/exp/slices"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/movecollide"
+	"github.com/jamestunnell/topdown/tilegrid"
+)
+
+// Object types that make areas instead of spawn points.
+const (
+	ObjectTypeTrigger  = "trigger"
+	ObjectTypeCollider = "collider"
+)
+
+// Custom properties with special meaning.
+const (
+	// PropertyDrawLayer is an int layer property that sets the drawing layer.
+	PropertyDrawLayer = "drawLayer"
+	// PropertySolid is a bool tile property that makes the tile solid.
+	PropertySolid = "solid"
+	// PropertySurface is a string tile property with the surface name. The
+	// other surface properties are only used along with it.
+	PropertySurface       = "surface"
+	PropertySpeedScale    = "speedScale"
+	PropertyFrictionScale = "frictionScale"
+	PropertySwim          = "swim"
+	PropertyDamage        = "damage"
+)
+
+const OrientationOrthogonal = "orthogonal"
+
+// converter makes a tile map from a Tiled map.
+type converter struct {
+	data      *mapData
+	tileMap   *tilegrid.TileMap
+	tilesets  []*tilesetData
+	layerName map[string]bool
+}
+
+func newMap(d *mapData) (*Map, error) {
+	if d.Orientation != "" && d.Orientation != OrientationOrthogonal {
+		return nil, fmt.Errorf("%s orientation is not supported", d.Orientation)
+	}
+
+	if d.Infinite {
+		return nil, fmt.Errorf("infinite maps are not supported")
+	}
+
+	if d.TileWidth <= 0 || d.TileHeight <= 0 {
+		return nil, fmt.Errorf("invalid tile size %dx%d", d.TileWidth, d.TileHeight)
+	}
+
+	for _, ts := range d.Tilesets {
+		if ts.TileWidth <= 0 || ts.TileHeight <= 0 || ts.Columns <= 0 {
+			return nil, fmt.Errorf("tileset '%s' has an invalid tile size or column count", ts.Name)
+		}
+	}
+
+	c := &converter{
+		data: d,
+		tileMap: &tilegrid.TileMap{
+			Origin:     topdown.Pt[float64](0, 0),
+			TileSize:   topdown.Sz(d.TileWidth, d.TileHeight),
+			TileLinks:  map[string]*tilegrid.TileLink{},
+			EmptyTile:  tilegrid.DefaultEmptyTile,
+			Layers:     []*tilegrid.TileLayer{},
+			Spawns:     []*tilegrid.SpawnPoint{},
+			Triggers:   []*tilegrid.Area{},
+			Colliders:  []*tilegrid.Area{},
+			Properties: d.Properties,
+		},
+		tilesets:  slices.Clone(d.Tilesets),
+		layerName: map[string]bool{},
+	}
+
+	// latest first, to find the tileset of a GID
+	slices.SortFunc(c.tilesets, func(a, b *tilesetData) bool {
+		return a.FirstGID > b.FirstGID
+	})
+
+	if err := c.addLayers(d.Layers, "", 1, true, topdown.Vector{}); err != nil {
+		return nil, err
+	}
+
+	return &Map{TileMap: c.tileMap, tilesets: d.Tilesets}, nil
+}
+
+// addLayers adds the layers, flattening groups. Layers in a group are
+// named with the group name as a prefix, and get the group opacity,
+// visibility and offset.
+func (c *converter) addLayers(
+	layers []*layerData, prefix string, opacity float64, visible bool, offset topdown.Vector) error {
+	for _, l := range layers {
+		name := c.uniqueName(prefix + l.Name)
+		layerOpacity := opacity * l.Opacity
+		layerVisible := visible && l.Visible
+		layerOffset := offset.Add(topdown.Vec(l.OffsetX, l.OffsetY))
+
+		var err error
+
+		switch l.Type {
+		case layerTypeTiles:
+			err = c.addTileLayer(l, name, layerOpacity, layerVisible)
+		case layerTypeObjects:
+			err = c.addObjects(l.Objects, layerOffset)
+		case layerTypeGroup:
+			err = c.addLayers(l.Layers, name+"/", layerOpacity, layerVisible, layerOffset)
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to add layer '%s': %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func (c *converter) uniqueName(name string) string {
+	unique := name
+
+	for i := 2; c.layerName[unique]; i++ {
+		unique = fmt.Sprintf("%s %d", name, i)
+	}
+
+	c.layerName[unique] = true
+
+	return unique
+}
+
+func (c *converter) addTileLayer(l *layerData, name string, opacity float64, visible bool) error {
+	if len(l.GIDs) != l.Width*l.Height {
+		return fmt.Errorf("layer has %d tiles instead of %dx%d", len(l.GIDs), l.Width, l.Height)
+	}
+
+	tileRows := make([]string, l.Height)
+
+	for row := range tileRows {
+		tileIDs := make([]string, l.Width)
+
+		for col := range tileIDs {
+			tileID, err := c.tileID(l.GIDs[row*l.Width+col])
+			if err != nil {
+				return fmt.Errorf("failed to link tile at row %d, col %d: %w", row, col, err)
+			}
+
+			tileIDs[col] = tileID
+		}
+
+		tileRows[row] = strings.Join(tileIDs, tilegrid.RefIDSeparator)
+	}
+
+	layer := &tilegrid.TileLayer{
+		Name:       name,
+		Hidden:     !visible,
+		TileRows:   tileRows,
+		Properties: l.Properties,
+	}
+
+	if opacity != 1 {
+		layer.Opacity = &opacity
+	}
+
+	if drawLayer, found := l.Properties[PropertyDrawLayer].(int); found {
+		layer.DrawOrder = drawLayer
+	}
+
+	c.tileMap.Layers = append(c.tileMap.Layers, layer)
+
+	return nil
+}
+
+// tileID gets the tile ID for a GID, linking the tile the first time.
+// The tile ID is the GID without flags, followed by h, v and d for the
+// horizontal, vertical and diagonal flips.
+func (c *converter) tileID(gid uint32) (string, error) {
+	if gid == 0 {
+		return tilegrid.DefaultEmptyTile, nil
+	}
+
+	tileGID, flags := splitGID(gid)
+	tileID := strconv.FormatUint(uint64(tileGID), 10)
+
+	if flags&FlagFlipX != 0 {
+		tileID += "h"
+	}
+
+	if flags&FlagFlipY != 0 {
+		tileID += "v"
+	}
+
+	if flags&FlagFlipDiagonal != 0 {
+		tileID += "d"
+	}
+
+	if _, found := c.tileMap.TileLinks[tileID]; found {
+		return tileID, nil
+	}
+
+	link, err := c.tileLink(tileGID, flags)
+	if err != nil {
+		return "", err
+	}
+
+	c.tileMap.TileLinks[tileID] = link
+
+	return tileID, nil
+}
+
+func (c *converter) tileLink(tileGID, flags uint32) (*tilegrid.TileLink, error) {
+	idx := slices.IndexFunc(c.tilesets, func(ts *tilesetData) bool {
+		return ts.FirstGID <= tileGID
+	})
+	if idx < 0 {
+		return nil, fmt.Errorf("no tileset has GID %d", tileGID)
+	}
+
+	ts := c.tilesets[idx]
+	localID := tileGID - ts.FirstGID
+
+	if int(localID) >= ts.TileCount {
+		return nil, fmt.Errorf("tileset '%s' has no tile %d", ts.Name, localID)
+	}
+
+	link := &tilegrid.TileLink{
+		Sprite:       ts.spriteLink(localID),
+		FlipX:        flags&FlagFlipX != 0,
+		FlipY:        flags&FlagFlipY != 0,
+		FlipDiagonal: flags&FlagFlipDiagonal != 0,
+	}
+
+	tile, found := ts.Tiles[localID]
+	if !found {
+		return link, nil
+	}
+
+	link.Properties = tile.Properties
+	link.Solid = boolProperty(tile.Properties, PropertySolid)
+
+	if name, found := tile.Properties[PropertySurface].(string); found {
+		link.Surface = &movecollide.Surface{
+			Name: name,
+			Swim: boolProperty(tile.Properties, PropertySwim),
+		}
+
+		link.Surface.SpeedScale, _ = numberProperty(tile.Properties, PropertySpeedScale)
+		link.Surface.FrictionScale, _ = numberProperty(tile.Properties, PropertyFrictionScale)
+		link.Surface.Damage, _ = numberProperty(tile.Properties, PropertyDamage)
+	}
+
+	if len(tile.Animation) > 0 {
+		link.Animation = &tilegrid.TileAnimation{
+			Frames: make([]*tilegrid.TileFrame, len(tile.Animation)),
+		}
+
+		for i, f := range tile.Animation {
+			if int(f.TileID) >= ts.TileCount {
+				return nil, fmt.Errorf("tileset '%s' has no tile %d for animation frame %d", ts.Name, f.TileID, i)
+			}
+
+			link.Animation.Frames[i] = &tilegrid.TileFrame{
+				Sprite:   ts.spriteLink(f.TileID),
+				Duration: fmt.Sprintf("%dms", f.Duration),
+			}
+		}
+	}
+
+	for _, obj := range tile.Objects {
+		if collider, found := c.tileCollider(obj, ts, flags); found {
+			if collider.Approximated() {
+				log.Warn().Str("tileset", ts.Name).Uint32("tile", localID).
+					Msg("tile collider polygon collides as its enclosing rectangle")
+			}
+
+			link.Collider = collider
+
+			break
+		}
+	}
+
+	return link, nil
+}
+
+// tileCollider makes a tile collider from one of the tile collision
+// objects. It is placed relative to the tile center, flipped along with
+// the tile and scaled to the map tile size like the tile sprite. Rects
+// become polygons so they can be flipped and scaled.
+func (c *converter) tileCollider(
+	obj *objectData, ts *tilesetData, flags uint32) (*movecollide.ColliderSpec, bool) {
+	pos, spec := objectShape(obj)
+	if spec == nil {
+		return nil, false
+	}
+
+	halfW, halfH := float64(ts.TileWidth)/2, float64(ts.TileHeight)/2
+	w, h := float64(ts.TileWidth), float64(ts.TileHeight)
+
+	if flags&FlagFlipDiagonal != 0 {
+		w, h = h, w
+	}
+
+	sx, sy := float64(c.data.TileWidth)/w, float64(c.data.TileHeight)/h
+
+	transform := func(p topdown.Vector) topdown.Vector {
+		x, y := p.X-halfW, p.Y-halfH
+
+		if flags&FlagFlipDiagonal != 0 {
+			x, y = y, x
+		}
+
+		if flags&FlagFlipX != 0 {
+			x = -x
+		}
+
+		if flags&FlagFlipY != 0 {
+			y = -y
+		}
+
+		return topdown.Vec(x*sx, y*sy)
+	}
+
+	center := pos.Add(spec.Offset)
+
+	if spec.Shape == movecollide.ColliderShapeCircle {
+		return &movecollide.ColliderSpec{
+			Shape:  movecollide.ColliderShapeCircle,
+			Radius: spec.Radius * (sx + sy) / 2,
+			Offset: transform(center),
+		}, true
+	}
+
+	points := spec.Points
+
+	if spec.Shape == movecollide.ColliderShapeRect {
+		halfSize := topdown.Vec(spec.Size.Width/2, spec.Size.Height/2)
+		points = []topdown.Vector{
+			topdown.Vec(-halfSize.X, -halfSize.Y),
+			topdown.Vec(halfSize.X, -halfSize.Y),
+			topdown.Vec(halfSize.X, halfSize.Y),
+			topdown.Vec(-halfSize.X, halfSize.Y),
+		}
+	}
+
+	transformed := make([]topdown.Vector, len(points))
+
+	for i, p := range points {
+		transformed[i] = transform(center.Add(rotate(p, spec.Rotation)))
+	}
+
+	return &movecollide.ColliderSpec{
+		Shape:  movecollide.ColliderShapePolygon,
+		Points: transformed,
+	}, true
+}
+
+// addObjects adds trigger and collider objects as areas, and the other
+// objects as spawn points.
+func (c *converter) addObjects(objects []*objectData, offset topdown.Vector) error {
+	for _, obj := range objects {
+		pos, spec := objectShape(obj)
+		pos = pos.Add(offset)
+
+		switch obj.Type {
+		case ObjectTypeTrigger, ObjectTypeCollider:
+			if spec == nil {
+				return fmt.Errorf("%s object %d has no area", obj.Type, obj.ID)
+			}
+
+			if spec.Approximated() {
+				log.Warn().Int("object", obj.ID).
+					Msgf("%s object polygon collides as its enclosing rectangle", obj.Type)
+			}
+
+			area := &tilegrid.Area{
+				Name:       obj.Name,
+				Type:       obj.Type,
+				Position:   pos,
+				Collider:   spec,
+				Properties: obj.Properties,
+			}
+
+			if obj.Type == ObjectTypeTrigger {
+				c.tileMap.Triggers = append(c.tileMap.Triggers, area)
+			} else {
+				c.tileMap.Colliders = append(c.tileMap.Colliders, area)
+			}
+		default:
+			if spec != nil {
+				pos = pos.Add(spec.Offset)
+			}
+
+			c.tileMap.Spawns = append(c.tileMap.Spawns, &tilegrid.SpawnPoint{
+				Name:       obj.Name,
+				Type:       obj.Type,
+				Position:   pos,
+				Properties: obj.Properties,
+			})
+		}
+	}
+
+	return nil
+}
+
+// objectShape gets the position of an object, and its shape relative to
+// the position. Points and empty rects have no shape. Ellipses are made
+// into circles, and polylines into polygons. Tile objects are placed by
+// the bottom-left corner instead of the top-left.
+func objectShape(obj *objectData) (topdown.Vector, *movecollide.ColliderSpec) {
+	pos := topdown.Vec(obj.X, obj.Y)
+
+	switch {
+	case obj.Point:
+		return pos, nil
+	case len(obj.Polygon) > 0 || len(obj.Polyline) > 0:
+		points := obj.Polygon
+		if len(points) == 0 {
+			points = obj.Polyline
+		}
+
+		return pos, &movecollide.ColliderSpec{
+			Shape:    movecollide.ColliderShapePolygon,
+			Points:   points,
+			Rotation: obj.Rotation,
+		}
+	case obj.Width <= 0 || obj.Height <= 0:
+		return pos, nil
+	case obj.Ellipse:
+		return pos, &movecollide.ColliderSpec{
+			Shape:  movecollide.ColliderShapeCircle,
+			Radius: (obj.Width + obj.Height) / 4,
+			Offset: rotate(topdown.Vec(obj.Width/2, obj.Height/2), obj.Rotation),
+		}
+	}
+
+	halfSize := topdown.Vec(obj.Width/2, obj.Height/2)
+	if obj.GID != 0 {
+		halfSize.Y = -halfSize.Y
+	}
+
+	return pos, &movecollide.ColliderSpec{
+		Shape:    movecollide.ColliderShapeRect,
+		Size:     &topdown.Size[float64]{Width: obj.Width, Height: obj.Height},
+		Offset:   rotate(halfSize, obj.Rotation),
+		Rotation: obj.Rotation,
+	}
+}
+
+// rotate rotates a vector by the given degrees, clockwise on screen like
+// the rotation in Tiled.
+func rotate(v topdown.Vector, degrees float64) topdown.Vector {
+	if degrees == 0 {
+		return v
+	}
+
+	sin, cos := math.Sincos(degrees * math.Pi / 180)
+
+	return topdown.Vec(v.X*cos-v.Y*sin, v.X*sin+v.Y*cos)
+}
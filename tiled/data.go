@@ -0,0 +1,115 @@
+package tiled
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	EncodingCSV    = "csv"
+	EncodingBase64 = "base64"
+
+	CompressionZlib = "zlib"
+	CompressionGzip = "gzip"
+)
+
+// Tiled stores tile flips in the top bits of a global tile ID (GID).
+const (
+	FlagFlipX        uint32 = 0x80000000
+	FlagFlipY        uint32 = 0x40000000
+	FlagFlipDiagonal uint32 = 0x20000000
+	// FlagRotateHex is only used by hexagonal maps, and is ignored.
+	FlagRotateHex uint32 = 0x10000000
+
+	flagsMask = FlagFlipX | FlagFlipY | FlagFlipDiagonal | FlagRotateHex
+)
+
+// DecodeData decodes the GIDs of a tile layer, stored as CSV or as base64
+// with optional zlib or gzip compression.
+func DecodeData(encoding, compression, data string) ([]uint32, error) {
+	switch encoding {
+	case EncodingCSV:
+		if compression != "" {
+			return nil, fmt.Errorf("CSV data can not be compressed")
+		}
+
+		return decodeCSV(data)
+	case EncodingBase64:
+		return decodeBase64(compression, data)
+	}
+
+	return nil, fmt.Errorf("unsupported encoding '%s'", encoding)
+}
+
+func decodeCSV(data string) ([]uint32, error) {
+	gids := []uint32{}
+
+	for _, field := range strings.Split(data, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		gid, err := strconv.ParseUint(field, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid GID '%s': %w", field, err)
+		}
+
+		gids = append(gids, uint32(gid))
+	}
+
+	return gids, nil
+}
+
+func decodeBase64(compression, data string) ([]uint32, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode base64: %w", err)
+	}
+
+	var r io.Reader = bytes.NewReader(raw)
+
+	switch compression {
+	case "":
+		// not compressed
+	case CompressionZlib:
+		if r, err = zlib.NewReader(r); err != nil {
+			return nil, fmt.Errorf("failed to decompress zlib: %w", err)
+		}
+	case CompressionGzip:
+		if r, err = gzip.NewReader(r); err != nil {
+			return nil, fmt.Errorf("failed to decompress gzip: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported compression '%s'", compression)
+	}
+
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress %s: %w", compression, err)
+	}
+
+	if len(b)%4 != 0 {
+		return nil, fmt.Errorf("data length %d is not a multiple of 4", len(b))
+	}
+
+	gids := make([]uint32, len(b)/4)
+
+	for i := range gids {
+		gids[i] = binary.LittleEndian.Uint32(b[i*4:])
+	}
+
+	return gids, nil
+}
+
+// splitGID splits a GID into the tile GID and the flip flags.
+func splitGID(gid uint32) (uint32, uint32) {
+	return gid &^ flagsMask, gid & flagsMask
+}
@@ -0,0 +1,74 @@
+package tiled_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jamestunnell/topdown/tiled"
+)
+
+func TestDecodeData(t *testing.T) {
+	gids := []uint32{0, 1, 7 | tiled.FlagFlipX, 2 | tiled.FlagFlipY | tiled.FlagFlipDiagonal}
+	raw := &bytes.Buffer{}
+
+	require.NoError(t, binary.Write(raw, binary.LittleEndian, gids))
+
+	zlibbed := &bytes.Buffer{}
+	zw := zlib.NewWriter(zlibbed)
+
+	_, err := zw.Write(raw.Bytes())
+
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	gzipped := &bytes.Buffer{}
+	gw := gzip.NewWriter(gzipped)
+
+	_, err = gw.Write(raw.Bytes())
+
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+
+	testCases := map[string][3]string{
+		"csv":    {tiled.EncodingCSV, "", "\n0,1,2147483655,\n1610612738\n"},
+		"base64": {tiled.EncodingBase64, "", base64.StdEncoding.EncodeToString(raw.Bytes())},
+		"zlib":   {tiled.EncodingBase64, tiled.CompressionZlib, base64.StdEncoding.EncodeToString(zlibbed.Bytes())},
+		"gzip":   {tiled.EncodingBase64, tiled.CompressionGzip, base64.StdEncoding.EncodeToString(gzipped.Bytes())},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			decoded, err := tiled.DecodeData(tc[0], tc[1], tc[2])
+
+			require.NoError(t, err)
+			assert.Equal(t, gids, decoded)
+		})
+	}
+}
+
+func TestDecodeDataInvalid(t *testing.T) {
+	testCases := map[string][3]string{
+		"unknown encoding":    {"xml", "", ""},
+		"compressed csv":      {tiled.EncodingCSV, tiled.CompressionZlib, "1,2"},
+		"invalid csv":         {tiled.EncodingCSV, "", "1,x"},
+		"invalid base64":      {tiled.EncodingBase64, "", "!!"},
+		"bad length":          {tiled.EncodingBase64, "", base64.StdEncoding.EncodeToString([]byte{1, 2, 3})},
+		"unknown compression": {tiled.EncodingBase64, "zstd", base64.StdEncoding.EncodeToString([]byte{1, 2, 3, 4})},
+		"not zlib":            {tiled.EncodingBase64, tiled.CompressionZlib, base64.StdEncoding.EncodeToString([]byte{1, 2, 3, 4})},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := tiled.DecodeData(tc[0], tc[1], tc[2])
+
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package tiled
+
+import (
+	"fmt"
+
+	"github.com/jamestunnell/topdown/resource"
+	"github.com/jamestunnell/topdown/tilegrid"
+)
+
+// Map is a tile map loaded from a Tiled map. Tile layers become the map
+// layers, and object layers become the spawn points, triggers and
+// colliders. Tilesets become sprite sheets when the map is initialized.
+type Map struct {
+	*tilegrid.TileMap
+
+	tilesets []*tilesetData
+}
+
+// Initialize adds a sprite sheet for each tileset to the resource manager,
+// then initializes the tile map.
+func (m *Map) Initialize(mgr resource.Manager) error {
+	for _, ts := range m.tilesets {
+		if err := ts.addSheet(mgr); err != nil {
+			return fmt.Errorf("failed to add sprite sheet for tileset '%s': %w", ts.Name, err)
+		}
+	}
+
+	return m.TileMap.Initialize(mgr)
+}
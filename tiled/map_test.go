@@ -0,0 +1,162 @@
+package tiled_test
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/movecollide"
+	"github.com/jamestunnell/topdown/resource"
+	"github.com/jamestunnell/topdown/tiled"
+	"github.com/jamestunnell/topdown/tilegrid"
+)
+
+func TestLoadMap(t *testing.T) {
+	types := map[string]resource.Type{
+		"map.tmx": tiled.NewTMXType(),
+		"map.tmj": tiled.NewTMJType(),
+	}
+
+	for file, typ := range types {
+		t.Run(file, func(t *testing.T) {
+			r, err := typ.Load(filepath.Join("testdata", file))
+
+			require.NoError(t, err)
+
+			m, ok := r.(*tiled.Map)
+
+			require.True(t, ok)
+
+			testMap(t, m.TileMap)
+		})
+	}
+}
+
+func testMap(t *testing.T, m *tilegrid.TileMap) {
+	assert.Equal(t, topdown.Sz(16, 16), m.TileSize)
+	assert.Equal(t, "theme.ogg", m.Properties["music"])
+	assert.Contains(t, m.Properties["stats"], "level")
+
+	require.Len(t, m.Layers, 2)
+
+	ground, roof := m.Layers[0], m.Layers[1]
+
+	assert.Equal(t, "ground", ground.Name)
+	assert.Equal(t, []string{"1 2 3", "- 4v 1h"}, ground.TileRows)
+	assert.Nil(t, ground.Opacity)
+
+	// flattened from the group
+	assert.Equal(t, "overhead/roof", roof.Name)
+	assert.Equal(t, []string{"- - -", "5 - -"}, roof.TileRows)
+	assert.Equal(t, 200, roof.DrawOrder)
+	require.NotNil(t, roof.Opacity)
+	assert.Equal(t, 0.5, *roof.Opacity)
+
+	require.Len(t, m.TileLinks, 6)
+
+	// tile IDs are GIDs, with flip suffixes, and sprite IDs are local tile IDs
+	for id, link := range m.TileLinks {
+		gid, err := strconv.Atoi(strings.TrimRight(id, "hvd"))
+
+		require.NoError(t, err)
+		assert.True(t, strings.HasSuffix(link.Sprite, "#"+strconv.Itoa(gid-1)), "sprite link %s", link.Sprite)
+	}
+
+	assert.True(t, m.TileLinks["1"].Solid)
+	assert.True(t, m.TileLinks["1h"].Solid)
+	assert.True(t, m.TileLinks["1h"].FlipX)
+	assert.False(t, m.TileLinks["1h"].FlipY)
+	assert.Equal(t, &movecollide.Surface{Name: "mud", SpeedScale: 0.5}, m.TileLinks["2"].Surface)
+	assert.Nil(t, m.TileLinks["3"].Collider)
+	assert.True(t, m.TileLinks["4v"].FlipY)
+
+	require.NotNil(t, m.TileLinks["5"].Animation)
+	require.Len(t, m.TileLinks["5"].Animation.Frames, 2)
+	assert.Equal(t, "200ms", m.TileLinks["5"].Animation.Frames[0].Duration)
+	assert.Equal(t, "100ms", m.TileLinks["5"].Animation.Frames[1].Duration)
+	assert.True(t, strings.HasSuffix(m.TileLinks["5"].Animation.Frames[1].Sprite, "#5"))
+
+	// the bottom half of the tile, flipped to the top half
+	require.NotNil(t, m.TileLinks["4v"].Collider)
+
+	shape, err := movecollide.NewCollider(m.TileLinks["4v"].Collider, topdown.Vector{})
+
+	require.NoError(t, err)
+
+	min, max := movecollide.ShapeBounds(shape)
+
+	assert.InDelta(t, -8, min.X, 1e-9)
+	assert.InDelta(t, -8, min.Y, 1e-9)
+	assert.InDelta(t, 8, max.X, 1e-9)
+	assert.InDelta(t, 0, max.Y, 1e-9)
+
+	require.Len(t, m.Spawns, 1)
+	assert.Equal(t, &tilegrid.SpawnPoint{
+		Name:       "start",
+		Type:       "player",
+		Position:   topdown.Vec(8, 24),
+		Properties: tilegrid.Properties{"hp": 3},
+	}, m.Spawns[0])
+
+	require.Len(t, m.Triggers, 1)
+
+	exit := m.Triggers[0]
+
+	assert.Equal(t, "exit", exit.Name)
+	assert.Equal(t, topdown.Vec(32, 0), exit.Position)
+
+	shape, err = exit.Shape()
+
+	require.NoError(t, err)
+
+	min, max = movecollide.ShapeBounds(shape)
+
+	assert.InDelta(t, 32, min.X, 1e-9)
+	assert.InDelta(t, 48, max.X, 1e-9)
+	assert.InDelta(t, 0, min.Y, 1e-9)
+	assert.InDelta(t, 16, max.Y, 1e-9)
+
+	require.Len(t, m.Colliders, 1)
+	assert.Equal(t, movecollide.ColliderShapePolygon, m.Colliders[0].Collider.Shape)
+	assert.Len(t, m.Colliders[0].Collider.Points, 3)
+}
+
+func TestLoadMapInvalid(t *testing.T) {
+	dir := t.TempDir()
+	maps := map[string]string{
+		"isometric.tmj": `{"orientation": "isometric", "tilewidth": 16, "tileheight": 16}`,
+		"infinite.tmj":  `{"orientation": "orthogonal", "infinite": true, "tilewidth": 16, "tileheight": 16}`,
+		"short.tmj": `{"orientation": "orthogonal", "tilewidth": 16, "tileheight": 16,
+			"layers": [{"type": "tilelayer", "name": "a", "width": 2, "height": 2, "data": [0, 0]}]}`,
+		"unknown gid.tmj": `{"orientation": "orthogonal", "tilewidth": 16, "tileheight": 16,
+			"layers": [{"type": "tilelayer", "name": "a", "width": 1, "height": 1, "data": [3]}]}`,
+		"image collection.tmx": `<map orientation="orthogonal" tilewidth="16" tileheight="16">
+			<tileset firstgid="1" name="things" tilewidth="16" tileheight="16" tilecount="1" columns="0">
+				<tile id="0"><image source="thing.png"/></tile>
+			</tileset>
+		</map>`,
+	}
+
+	for file, content := range maps {
+		t.Run(file, func(t *testing.T) {
+			path := filepath.Join(dir, file)
+
+			require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+			typ := tiled.NewTMJType()
+			if filepath.Ext(file) == ".tmx" {
+				typ = tiled.NewTMXType()
+			}
+
+			_, err := typ.Load(path)
+
+			assert.Error(t, err)
+		})
+	}
+}
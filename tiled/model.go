@@ -0,0 +1,83 @@
+package tiled
+
+import (
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/tilegrid"
+)
+
+// mapData is a Tiled map, parsed from either TMX or TMJ.
+type mapData struct {
+	Orientation           string
+	Infinite              bool
+	Width, Height         int
+	TileWidth, TileHeight int
+	Tilesets              []*tilesetData
+	Layers                []*layerData
+	Properties            tilegrid.Properties
+}
+
+// tilesetData is a tileset, with its image path made absolute.
+type tilesetData struct {
+	FirstGID              uint32
+	Name                  string
+	TileWidth, TileHeight int
+	TileCount, Columns    int
+	Margin, Spacing       int
+	Image                 string
+	Tiles                 map[uint32]*tileData
+	Properties            tilegrid.Properties
+
+	// ref is unique to the tileset, for naming its sprite sheet
+	ref string
+}
+
+// tileData has the extra info for a tile in a tileset.
+type tileData struct {
+	ID         uint32
+	Properties tilegrid.Properties
+	Objects    []*objectData
+	Animation  []*frameData
+}
+
+// frameData is an animation frame, showing a tile for a duration in
+// milliseconds.
+type frameData struct {
+	TileID   uint32
+	Duration int
+}
+
+const (
+	layerTypeTiles   = "tilelayer"
+	layerTypeObjects = "objectgroup"
+	layerTypeImage   = "imagelayer"
+	layerTypeGroup   = "group"
+)
+
+// layerData is a layer, which has tiles, objects or other layers
+// depending on the type.
+type layerData struct {
+	Type             string
+	Name             string
+	Width, Height    int
+	Opacity          float64
+	Visible          bool
+	OffsetX, OffsetY float64
+	GIDs             []uint32
+	Objects          []*objectData
+	Layers           []*layerData
+	Properties       tilegrid.Properties
+}
+
+// objectData is an object. It is a rect unless it is an ellipse, point,
+// polygon or polyline.
+type objectData struct {
+	ID                int
+	Name, Type        string
+	X, Y              float64
+	Width, Height     float64
+	Rotation          float64
+	GID               uint32
+	Ellipse, Point    bool
+	Polygon, Polyline []topdown.Vector
+	Properties        tilegrid.Properties
+}
@@ -0,0 +1,138 @@
+package tiled
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/jamestunnell/topdown/tilegrid"
+)
+
+const (
+	PropertyTypeString = "string"
+	PropertyTypeInt    = "int"
+	PropertyTypeFloat  = "float"
+	PropertyTypeBool   = "bool"
+	PropertyTypeColor  = "color"
+	PropertyTypeFile   = "file"
+	PropertyTypeObject = "object"
+	PropertyTypeClass  = "class"
+)
+
+// jsonProperty is a custom property in TMJ.
+type jsonProperty struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value any    `json:"value"`
+}
+
+// xmlProperty is a custom property in TMX. Multi-line strings are kept in
+// the text, and class members in the nested properties.
+type xmlProperty struct {
+	Name       string         `xml:"name,attr"`
+	Type       string         `xml:"type,attr"`
+	Value      *string        `xml:"value,attr"`
+	Text       string         `xml:",chardata"`
+	Properties []*xmlProperty `xml:"properties>property"`
+}
+
+// convertJSONProperties makes properties from TMJ properties. Ints and
+// object IDs become int instead of float64, and class members are kept
+// as they are.
+func convertJSONProperties(props []*jsonProperty) (tilegrid.Properties, error) {
+	if len(props) == 0 {
+		return nil, nil
+	}
+
+	converted := tilegrid.Properties{}
+
+	for _, prop := range props {
+		val := prop.Value
+
+		switch prop.Type {
+		case PropertyTypeInt, PropertyTypeObject:
+			f, ok := val.(float64)
+			if !ok {
+				return nil, fmt.Errorf("property '%s' is not a number", prop.Name)
+			}
+
+			val = int(f)
+		}
+
+		converted[prop.Name] = val
+	}
+
+	return converted, nil
+}
+
+// convertXMLProperties makes properties from TMX properties, parsing the
+// values by type.
+func convertXMLProperties(props []*xmlProperty) (tilegrid.Properties, error) {
+	if len(props) == 0 {
+		return nil, nil
+	}
+
+	converted := tilegrid.Properties{}
+
+	for _, prop := range props {
+		if prop.Type == PropertyTypeClass {
+			members, err := convertXMLProperties(prop.Properties)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert members of property '%s': %w", prop.Name, err)
+			}
+
+			if members == nil {
+				members = tilegrid.Properties{}
+			}
+
+			converted[prop.Name] = map[string]any(members)
+
+			continue
+		}
+
+		s := prop.Text
+		if prop.Value != nil {
+			s = *prop.Value
+		}
+
+		val, err := parseValue(prop.Type, s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for property '%s': %w", prop.Name, err)
+		}
+
+		converted[prop.Name] = val
+	}
+
+	return converted, nil
+}
+
+func parseValue(typ, s string) (any, error) {
+	switch typ {
+	case PropertyTypeInt, PropertyTypeObject:
+		return strconv.Atoi(s)
+	case PropertyTypeFloat:
+		return strconv.ParseFloat(s, 64)
+	case PropertyTypeBool:
+		return strconv.ParseBool(s)
+	}
+
+	return s, nil
+}
+
+// boolProperty gets a bool property, which is false if it is missing.
+func boolProperty(props tilegrid.Properties, name string) bool {
+	b, _ := props[name].(bool)
+
+	return b
+}
+
+// numberProperty gets an int or float property as a float.
+func numberProperty(props tilegrid.Properties, name string) (float64, bool) {
+	switch val := props[name].(type) {
+	case int:
+		return float64(val), true
+	case float64:
+		return val, true
+	}
+
+	return 0, false
+}
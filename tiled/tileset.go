@@ -0,0 +1,59 @@
+package tiled
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/resource"
+	"github.com/jamestunnell/topdown/sprite"
+)
+
+var errImageCollection = errors.New("image collection tilesets are not supported")
+
+// readTileset reads an external tileset from a TSX or TSJ file.
+func readTileset(path string, firstGID uint32) (*tilesetData, error) {
+	switch ext := filepath.Ext(path); ext {
+	case ".tsx":
+		return readTSX(path, firstGID)
+	case ".tsj", ".json":
+		return readTSJ(path, firstGID)
+	default:
+		return nil, fmt.Errorf("unknown tileset file type '%s'", ext)
+	}
+}
+
+// spriteLink makes a link to the sprite for a tile.
+func (ts *tilesetData) spriteLink(localID uint32) string {
+	l := &sprite.IDLink{
+		SpriteSheetRef: ts.ref,
+		SpriteID:       strconv.FormatUint(uint64(localID), 10),
+	}
+
+	return l.String()
+}
+
+// addSheet adds the tileset image and a sprite sheet made from it to the
+// resource manager.
+func (ts *tilesetData) addSheet(mgr resource.Manager) error {
+	img, err := sprite.LoadImage(ts.Image)
+	if err != nil {
+		return fmt.Errorf("failed to load image '%s': %w", ts.Image, err)
+	}
+
+	mgr.Add(ts.Image, img)
+
+	sprites := sprite.GridSprites(
+		topdown.Sz(ts.TileWidth, ts.TileHeight), ts.TileCount, ts.Columns, ts.Margin, ts.Spacing)
+	sheet := sprite.NewSheet(ts.Image, sprites...)
+
+	if err = sheet.Initialize(mgr); err != nil {
+		return err
+	}
+
+	mgr.Add(ts.ref, sheet)
+
+	return nil
+}
@@ -0,0 +1,291 @@
+package tiled
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/jsonfile"
+)
+
+type jsonMap struct {
+	Orientation string          `json:"orientation"`
+	Infinite    bool            `json:"infinite"`
+	Width       int             `json:"width"`
+	Height      int             `json:"height"`
+	TileWidth   int             `json:"tilewidth"`
+	TileHeight  int             `json:"tileheight"`
+	Tilesets    []*jsonTileset  `json:"tilesets"`
+	Layers      []*jsonLayer    `json:"layers"`
+	Properties  []*jsonProperty `json:"properties"`
+}
+
+type jsonTileset struct {
+	FirstGID   uint32          `json:"firstgid"`
+	Source     string          `json:"source"`
+	Name       string          `json:"name"`
+	TileWidth  int             `json:"tilewidth"`
+	TileHeight int             `json:"tileheight"`
+	TileCount  int             `json:"tilecount"`
+	Columns    int             `json:"columns"`
+	Margin     int             `json:"margin"`
+	Spacing    int             `json:"spacing"`
+	Image      string          `json:"image"`
+	Tiles      []*jsonTile     `json:"tiles"`
+	Properties []*jsonProperty `json:"properties"`
+}
+
+type jsonTile struct {
+	ID          uint32          `json:"id"`
+	ObjectGroup *jsonLayer      `json:"objectgroup"`
+	Properties  []*jsonProperty `json:"properties"`
+	Animation   []*jsonFrame    `json:"animation"`
+}
+
+type jsonFrame struct {
+	TileID   uint32 `json:"tileid"`
+	Duration int    `json:"duration"`
+}
+
+type jsonLayer struct {
+	Type        string          `json:"type"`
+	Name        string          `json:"name"`
+	Width       int             `json:"width"`
+	Height      int             `json:"height"`
+	Opacity     *float64        `json:"opacity"`
+	Visible     *bool           `json:"visible"`
+	OffsetX     float64         `json:"offsetx"`
+	OffsetY     float64         `json:"offsety"`
+	Data        json.RawMessage `json:"data"`
+	Encoding    string          `json:"encoding"`
+	Compression string          `json:"compression"`
+	Objects     []*jsonObject   `json:"objects"`
+	Layers      []*jsonLayer    `json:"layers"`
+	Properties  []*jsonProperty `json:"properties"`
+}
+
+type jsonObject struct {
+	ID         int              `json:"id"`
+	Name       string           `json:"name"`
+	Type       string           `json:"type"`
+	Class      string           `json:"class"`
+	X          float64          `json:"x"`
+	Y          float64          `json:"y"`
+	Width      float64          `json:"width"`
+	Height     float64          `json:"height"`
+	Rotation   float64          `json:"rotation"`
+	GID        uint32           `json:"gid"`
+	Ellipse    bool             `json:"ellipse"`
+	Point      bool             `json:"point"`
+	Polygon    []topdown.Vector `json:"polygon"`
+	Polyline   []topdown.Vector `json:"polyline"`
+	Properties []*jsonProperty  `json:"properties"`
+}
+
+// readTMJ reads a map from a TMJ file. External tilesets are read from
+// paths relative to the map file.
+func readTMJ(path string) (*mapData, error) {
+	m, err := jsonfile.Read[*jsonMap](path)
+	if err != nil {
+		return nil, err
+	}
+
+	props, err := convertJSONProperties(m.Properties)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert map properties: %w", err)
+	}
+
+	d := &mapData{
+		Orientation: m.Orientation,
+		Infinite:    m.Infinite,
+		Width:       m.Width,
+		Height:      m.Height,
+		TileWidth:   m.TileWidth,
+		TileHeight:  m.TileHeight,
+		Tilesets:    make([]*tilesetData, len(m.Tilesets)),
+		Properties:  props,
+	}
+
+	dir := filepath.Dir(path)
+
+	for i, ts := range m.Tilesets {
+		if ts.Source != "" {
+			d.Tilesets[i], err = readTileset(filepath.Join(dir, ts.Source), ts.FirstGID)
+		} else {
+			d.Tilesets[i], err = ts.convert(dir, fmt.Sprintf("%s.tileset%d", path, ts.FirstGID))
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert tileset %d: %w", i, err)
+		}
+	}
+
+	if d.Layers, err = convertJSONLayers(m.Layers); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// readTSJ reads an external tileset from a TSJ file.
+func readTSJ(path string, firstGID uint32) (*tilesetData, error) {
+	ts, err := jsonfile.Read[*jsonTileset](path)
+	if err != nil {
+		return nil, err
+	}
+
+	ts.FirstGID = firstGID
+
+	return ts.convert(filepath.Dir(path), path)
+}
+
+func (ts *jsonTileset) convert(dir, ref string) (*tilesetData, error) {
+	if ts.Image == "" {
+		return nil, fmt.Errorf("tileset '%s' has no image: %w", ts.Name, errImageCollection)
+	}
+
+	props, err := convertJSONProperties(ts.Properties)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert properties: %w", err)
+	}
+
+	d := &tilesetData{
+		FirstGID:   ts.FirstGID,
+		Name:       ts.Name,
+		TileWidth:  ts.TileWidth,
+		TileHeight: ts.TileHeight,
+		TileCount:  ts.TileCount,
+		Columns:    ts.Columns,
+		Margin:     ts.Margin,
+		Spacing:    ts.Spacing,
+		Image:      filepath.Join(dir, ts.Image),
+		Tiles:      map[uint32]*tileData{},
+		Properties: props,
+		ref:        ref,
+	}
+
+	for _, tile := range ts.Tiles {
+		props, err := convertJSONProperties(tile.Properties)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert properties of tile %d: %w", tile.ID, err)
+		}
+
+		td := &tileData{ID: tile.ID, Properties: props}
+
+		for _, f := range tile.Animation {
+			td.Animation = append(td.Animation, &frameData{TileID: f.TileID, Duration: f.Duration})
+		}
+
+		if tile.ObjectGroup != nil {
+			if td.Objects, err = convertJSONObjects(tile.ObjectGroup.Objects); err != nil {
+				return nil, fmt.Errorf("failed to convert objects of tile %d: %w", tile.ID, err)
+			}
+		}
+
+		d.Tiles[tile.ID] = td
+	}
+
+	return d, nil
+}
+
+func convertJSONLayers(layers []*jsonLayer) ([]*layerData, error) {
+	converted := make([]*layerData, len(layers))
+
+	for i, l := range layers {
+		props, err := convertJSONProperties(l.Properties)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert properties of layer '%s': %w", l.Name, err)
+		}
+
+		d := &layerData{
+			Type:       l.Type,
+			Name:       l.Name,
+			Width:      l.Width,
+			Height:     l.Height,
+			Opacity:    1,
+			Visible:    l.Visible == nil || *l.Visible,
+			OffsetX:    l.OffsetX,
+			OffsetY:    l.OffsetY,
+			Properties: props,
+		}
+
+		if l.Opacity != nil {
+			d.Opacity = *l.Opacity
+		}
+
+		switch l.Type {
+		case layerTypeTiles:
+			d.GIDs, err = l.decodeData()
+		case layerTypeObjects:
+			d.Objects, err = convertJSONObjects(l.Objects)
+		case layerTypeGroup:
+			d.Layers, err = convertJSONLayers(l.Layers)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert layer '%s': %w", l.Name, err)
+		}
+
+		converted[i] = d
+	}
+
+	return converted, nil
+}
+
+// decodeData decodes the layer data, which is either an array of GIDs
+// or a string with the encoded GIDs.
+func (l *jsonLayer) decodeData() ([]uint32, error) {
+	if l.Encoding == "" || l.Encoding == EncodingCSV {
+		gids := []uint32{}
+
+		if err := json.Unmarshal(l.Data, &gids); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal data: %w", err)
+		}
+
+		return gids, nil
+	}
+
+	var s string
+
+	if err := json.Unmarshal(l.Data, &s); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal data: %w", err)
+	}
+
+	return DecodeData(l.Encoding, l.Compression, s)
+}
+
+func convertJSONObjects(objects []*jsonObject) ([]*objectData, error) {
+	converted := make([]*objectData, len(objects))
+
+	for i, obj := range objects {
+		props, err := convertJSONProperties(obj.Properties)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert properties of object %d: %w", obj.ID, err)
+		}
+
+		typ := obj.Type
+		if typ == "" {
+			typ = obj.Class
+		}
+
+		converted[i] = &objectData{
+			ID:         obj.ID,
+			Name:       obj.Name,
+			Type:       typ,
+			X:          obj.X,
+			Y:          obj.Y,
+			Width:      obj.Width,
+			Height:     obj.Height,
+			Rotation:   obj.Rotation,
+			GID:        obj.GID,
+			Ellipse:    obj.Ellipse,
+			Point:      obj.Point,
+			Polygon:    obj.Polygon,
+			Polyline:   obj.Polyline,
+			Properties: props,
+		}
+	}
+
+	return converted, nil
+}
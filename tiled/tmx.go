@@ -0,0 +1,375 @@
+package tiled
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jamestunnell/topdown"
+)
+
+type xmlMap struct {
+	Orientation string         `xml:"orientation,attr"`
+	Infinite    int            `xml:"infinite,attr"`
+	Width       int            `xml:"width,attr"`
+	Height      int            `xml:"height,attr"`
+	TileWidth   int            `xml:"tilewidth,attr"`
+	TileHeight  int            `xml:"tileheight,attr"`
+	Tilesets    []*xmlTileset  `xml:"tileset"`
+	Properties  []*xmlProperty `xml:"properties>property"`
+	// Layers has the layers of all types, to keep them in order
+	Layers []*xmlLayer `xml:",any"`
+}
+
+type xmlTileset struct {
+	FirstGID   uint32         `xml:"firstgid,attr"`
+	Source     string         `xml:"source,attr"`
+	Name       string         `xml:"name,attr"`
+	TileWidth  int            `xml:"tilewidth,attr"`
+	TileHeight int            `xml:"tileheight,attr"`
+	TileCount  int            `xml:"tilecount,attr"`
+	Columns    int            `xml:"columns,attr"`
+	Margin     int            `xml:"margin,attr"`
+	Spacing    int            `xml:"spacing,attr"`
+	Image      *xmlImage      `xml:"image"`
+	Tiles      []*xmlTile     `xml:"tile"`
+	Properties []*xmlProperty `xml:"properties>property"`
+}
+
+type xmlImage struct {
+	Source string `xml:"source,attr"`
+}
+
+type xmlTile struct {
+	ID          uint32         `xml:"id,attr"`
+	ObjectGroup *xmlLayer      `xml:"objectgroup"`
+	Properties  []*xmlProperty `xml:"properties>property"`
+	Animation   []*xmlFrame    `xml:"animation>frame"`
+}
+
+type xmlFrame struct {
+	TileID   uint32 `xml:"tileid,attr"`
+	Duration int    `xml:"duration,attr"`
+}
+
+type xmlLayer struct {
+	XMLName    xml.Name
+	Name       string         `xml:"name,attr"`
+	Width      int            `xml:"width,attr"`
+	Height     int            `xml:"height,attr"`
+	Opacity    *float64       `xml:"opacity,attr"`
+	Visible    *int           `xml:"visible,attr"`
+	OffsetX    float64        `xml:"offsetx,attr"`
+	OffsetY    float64        `xml:"offsety,attr"`
+	Data       *xmlData       `xml:"data"`
+	Objects    []*xmlObject   `xml:"object"`
+	Properties []*xmlProperty `xml:"properties>property"`
+	// Layers has the layers in a group
+	Layers []*xmlLayer `xml:",any"`
+}
+
+type xmlData struct {
+	Encoding    string         `xml:"encoding,attr"`
+	Compression string         `xml:"compression,attr"`
+	Text        string         `xml:",chardata"`
+	Tiles       []*xmlDataTile `xml:"tile"`
+}
+
+type xmlDataTile struct {
+	GID uint32 `xml:"gid,attr"`
+}
+
+type xmlObject struct {
+	ID         int            `xml:"id,attr"`
+	Name       string         `xml:"name,attr"`
+	Type       string         `xml:"type,attr"`
+	Class      string         `xml:"class,attr"`
+	X          float64        `xml:"x,attr"`
+	Y          float64        `xml:"y,attr"`
+	Width      float64        `xml:"width,attr"`
+	Height     float64        `xml:"height,attr"`
+	Rotation   float64        `xml:"rotation,attr"`
+	GID        uint32         `xml:"gid,attr"`
+	Ellipse    *struct{}      `xml:"ellipse"`
+	Point      *struct{}      `xml:"point"`
+	Polygon    *xmlPoints     `xml:"polygon"`
+	Polyline   *xmlPoints     `xml:"polyline"`
+	Properties []*xmlProperty `xml:"properties>property"`
+}
+
+type xmlPoints struct {
+	Points string `xml:"points,attr"`
+}
+
+// readTMX reads a map from a TMX file. External tilesets are read from
+// paths relative to the map file.
+func readTMX(path string) (*mapData, error) {
+	var m xmlMap
+
+	if err := readXML(path, &m); err != nil {
+		return nil, err
+	}
+
+	props, err := convertXMLProperties(m.Properties)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert map properties: %w", err)
+	}
+
+	d := &mapData{
+		Orientation: m.Orientation,
+		Infinite:    m.Infinite != 0,
+		Width:       m.Width,
+		Height:      m.Height,
+		TileWidth:   m.TileWidth,
+		TileHeight:  m.TileHeight,
+		Tilesets:    make([]*tilesetData, len(m.Tilesets)),
+		Properties:  props,
+	}
+
+	dir := filepath.Dir(path)
+
+	for i, ts := range m.Tilesets {
+		if ts.Source != "" {
+			d.Tilesets[i], err = readTileset(filepath.Join(dir, ts.Source), ts.FirstGID)
+		} else {
+			d.Tilesets[i], err = ts.convert(dir, fmt.Sprintf("%s.tileset%d", path, ts.FirstGID))
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert tileset %d: %w", i, err)
+		}
+	}
+
+	if d.Layers, err = convertXMLLayers(m.Layers); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// readTSX reads an external tileset from a TSX file.
+func readTSX(path string, firstGID uint32) (*tilesetData, error) {
+	var ts xmlTileset
+
+	if err := readXML(path, &ts); err != nil {
+		return nil, err
+	}
+
+	ts.FirstGID = firstGID
+
+	return ts.convert(filepath.Dir(path), path)
+}
+
+func readXML(path string, v any) error {
+	d, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	if err = xml.Unmarshal(d, v); err != nil {
+		return fmt.Errorf("failed to unmarshal: %w", err)
+	}
+
+	return nil
+}
+
+func (ts *xmlTileset) convert(dir, ref string) (*tilesetData, error) {
+	if ts.Image == nil {
+		return nil, fmt.Errorf("tileset '%s' has no image: %w", ts.Name, errImageCollection)
+	}
+
+	props, err := convertXMLProperties(ts.Properties)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert properties: %w", err)
+	}
+
+	d := &tilesetData{
+		FirstGID:   ts.FirstGID,
+		Name:       ts.Name,
+		TileWidth:  ts.TileWidth,
+		TileHeight: ts.TileHeight,
+		TileCount:  ts.TileCount,
+		Columns:    ts.Columns,
+		Margin:     ts.Margin,
+		Spacing:    ts.Spacing,
+		Image:      filepath.Join(dir, ts.Image.Source),
+		Tiles:      map[uint32]*tileData{},
+		Properties: props,
+		ref:        ref,
+	}
+
+	for _, tile := range ts.Tiles {
+		props, err := convertXMLProperties(tile.Properties)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert properties of tile %d: %w", tile.ID, err)
+		}
+
+		td := &tileData{ID: tile.ID, Properties: props}
+
+		for _, f := range tile.Animation {
+			td.Animation = append(td.Animation, &frameData{TileID: f.TileID, Duration: f.Duration})
+		}
+
+		if tile.ObjectGroup != nil {
+			if td.Objects, err = convertXMLObjects(tile.ObjectGroup.Objects); err != nil {
+				return nil, fmt.Errorf("failed to convert objects of tile %d: %w", tile.ID, err)
+			}
+		}
+
+		d.Tiles[tile.ID] = td
+	}
+
+	return d, nil
+}
+
+func convertXMLLayers(layers []*xmlLayer) ([]*layerData, error) {
+	converted := []*layerData{}
+
+	for _, l := range layers {
+		typ := ""
+
+		switch l.XMLName.Local {
+		case "layer":
+			typ = layerTypeTiles
+		case layerTypeObjects, layerTypeImage, layerTypeGroup:
+			typ = l.XMLName.Local
+		default:
+			// not a layer
+			continue
+		}
+
+		props, err := convertXMLProperties(l.Properties)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert properties of layer '%s': %w", l.Name, err)
+		}
+
+		d := &layerData{
+			Type:       typ,
+			Name:       l.Name,
+			Width:      l.Width,
+			Height:     l.Height,
+			Opacity:    1,
+			Visible:    l.Visible == nil || *l.Visible != 0,
+			OffsetX:    l.OffsetX,
+			OffsetY:    l.OffsetY,
+			Properties: props,
+		}
+
+		if l.Opacity != nil {
+			d.Opacity = *l.Opacity
+		}
+
+		switch typ {
+		case layerTypeTiles:
+			d.GIDs, err = l.decodeData()
+		case layerTypeObjects:
+			d.Objects, err = convertXMLObjects(l.Objects)
+		case layerTypeGroup:
+			d.Layers, err = convertXMLLayers(l.Layers)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert layer '%s': %w", l.Name, err)
+		}
+
+		converted = append(converted, d)
+	}
+
+	return converted, nil
+}
+
+// decodeData decodes the layer data, which is either encoded text or a
+// tile element for each GID.
+func (l *xmlLayer) decodeData() ([]uint32, error) {
+	if l.Data == nil {
+		return nil, fmt.Errorf("layer has no data")
+	}
+
+	if l.Data.Encoding != "" {
+		return DecodeData(l.Data.Encoding, l.Data.Compression, l.Data.Text)
+	}
+
+	gids := make([]uint32, len(l.Data.Tiles))
+
+	for i, tile := range l.Data.Tiles {
+		gids[i] = tile.GID
+	}
+
+	return gids, nil
+}
+
+func convertXMLObjects(objects []*xmlObject) ([]*objectData, error) {
+	converted := make([]*objectData, len(objects))
+
+	for i, obj := range objects {
+		props, err := convertXMLProperties(obj.Properties)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert properties of object %d: %w", obj.ID, err)
+		}
+
+		typ := obj.Type
+		if typ == "" {
+			typ = obj.Class
+		}
+
+		d := &objectData{
+			ID:         obj.ID,
+			Name:       obj.Name,
+			Type:       typ,
+			X:          obj.X,
+			Y:          obj.Y,
+			Width:      obj.Width,
+			Height:     obj.Height,
+			Rotation:   obj.Rotation,
+			GID:        obj.GID,
+			Ellipse:    obj.Ellipse != nil,
+			Point:      obj.Point != nil,
+			Properties: props,
+		}
+
+		if obj.Polygon != nil {
+			if d.Polygon, err = parsePoints(obj.Polygon.Points); err != nil {
+				return nil, fmt.Errorf("failed to parse polygon of object %d: %w", obj.ID, err)
+			}
+		}
+
+		if obj.Polyline != nil {
+			if d.Polyline, err = parsePoints(obj.Polyline.Points); err != nil {
+				return nil, fmt.Errorf("failed to parse polyline of object %d: %w", obj.ID, err)
+			}
+		}
+
+		converted[i] = d
+	}
+
+	return converted, nil
+}
+
+// parsePoints parses points like "0,0 16,0 16,8".
+func parsePoints(s string) ([]topdown.Vector, error) {
+	points := []topdown.Vector{}
+
+	for _, pair := range strings.Fields(s) {
+		xy := strings.Split(pair, ",")
+		if len(xy) != 2 {
+			return nil, fmt.Errorf("invalid point '%s'", pair)
+		}
+
+		x, err := strconv.ParseFloat(xy[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid point '%s': %w", pair, err)
+		}
+
+		y, err := strconv.ParseFloat(xy[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid point '%s': %w", pair, err)
+		}
+
+		points = append(points, topdown.Vec(x, y))
+	}
+
+	return points, nil
+}
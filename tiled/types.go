@@ -0,0 +1,52 @@
+package tiled
+
+import (
+	"fmt"
+
+	"github.com/jamestunnell/topdown/resource"
+)
+
+// MapType loads Tiled maps, from TMX or TMJ files.
+type MapType struct {
+	name string
+}
+
+const (
+	MapTypeTMX = "tmx"
+	MapTypeTMJ = "tmj"
+)
+
+func NewTMXType() resource.Type {
+	return &MapType{name: MapTypeTMX}
+}
+
+func NewTMJType() resource.Type {
+	return &MapType{name: MapTypeTMJ}
+}
+
+func Types() []resource.Type {
+	return []resource.Type{NewTMXType(), NewTMJType()}
+}
+
+func (t *MapType) Name() string {
+	return t.name
+}
+
+func (t *MapType) Load(path string) (resource.Resource, error) {
+	read := readTMJ
+	if t.name == MapTypeTMX {
+		read = readTMX
+	}
+
+	d, err := read(path)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := newMap(d)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert map: %w", err)
+	}
+
+	return m, nil
+}
@@ -0,0 +1,365 @@
+package tilegrid
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/resource"
+)
+
+// Autotile kinds, which pick the tile for a terrain cell by which of the
+// neighboring cells have the same terrain.
+const (
+	// AutoTileBlob47 matches all 8 neighbors, with a mask made of the Mask
+	// bits. Corners only count when both edges next to them match, which
+	// leaves 47 masks.
+	AutoTileBlob47 = "blob47"
+	// AutoTileWangCorner matches the 4 corners, with a mask made of NE=1,
+	// SE=2, SW=4 and NW=8. A corner matches when the 3 neighbors around it
+	// match.
+	AutoTileWangCorner = "wangCorner"
+	// AutoTileWangEdge matches the 4 edges, with a mask made of N=1, E=2,
+	// S=4 and W=8.
+	AutoTileWangEdge = "wangEdge"
+)
+
+// Neighbor bits for blob masks.
+const (
+	MaskN = 1 << iota
+	MaskNE
+	MaskE
+	MaskSE
+	MaskS
+	MaskSW
+	MaskW
+	MaskNW
+)
+
+// AutoTileRules has rules for picking the tiles for terrain like grass,
+// dirt and water.
+type AutoTileRules struct {
+	Terrains []*TerrainRule `json:"terrains"`
+
+	rules map[string]*TerrainRule
+}
+
+// TerrainRule picks the tiles for a terrain. Without a kind, the terrain
+// always uses the same tile.
+type TerrainRule struct {
+	Name string `json:"name"`
+	Kind string `json:"kind,omitempty"`
+	// Tile is the tile ID for a terrain without a kind.
+	Tile string `json:"tile,omitempty"`
+	// Tiles has the tile IDs by neighbor mask.
+	Tiles map[int]string `json:"tiles,omitempty"`
+	// Matches are other terrains that count as this terrain when matching
+	// neighbors, like flowers in grass.
+	Matches []string `json:"matches,omitempty"`
+}
+
+func (r *AutoTileRules) Initialize(mgr resource.Manager) error {
+	rules := map[string]*TerrainRule{}
+
+	for _, rule := range r.Terrains {
+		if _, found := rules[rule.Name]; found {
+			return fmt.Errorf("terrain '%s' is not unique", rule.Name)
+		}
+
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("invalid rule for terrain '%s': %w", rule.Name, err)
+		}
+
+		rules[rule.Name] = rule
+	}
+
+	r.rules = rules
+
+	return nil
+}
+
+// TileID picks the tile ID for a cell with the given terrain. The match
+// func checks if the neighbor at a column and row offset has a terrain
+// that matches.
+func (r *AutoTileRules) TileID(terrain string, match func(dCol, dRow int) bool) (string, error) {
+	rule, found := r.rules[terrain]
+	if !found {
+		return "", fmt.Errorf("terrain '%s' has no rule", terrain)
+	}
+
+	if rule.Kind == "" {
+		return rule.Tile, nil
+	}
+
+	return rule.Tiles[rule.mask(match)], nil
+}
+
+func (rule *TerrainRule) validate() error {
+	masks := []int{}
+
+	switch rule.Kind {
+	case "":
+		if rule.Tile == "" {
+			return fmt.Errorf("tile is blank")
+		}
+
+		return nil
+	case AutoTileBlob47:
+		masks = BlobMasks()
+	case AutoTileWangCorner, AutoTileWangEdge:
+		for mask := 0; mask < 16; mask++ {
+			masks = append(masks, mask)
+		}
+	default:
+		return fmt.Errorf("unknown kind '%s'", rule.Kind)
+	}
+
+	for _, mask := range masks {
+		if rule.Tiles[mask] == "" {
+			return fmt.Errorf("missing tile for mask %d", mask)
+		}
+	}
+
+	return nil
+}
+
+// tileIDs gets the tile IDs used by the rule.
+func (rule *TerrainRule) tileIDs() []string {
+	if rule.Kind == "" {
+		return []string{rule.Tile}
+	}
+
+	return maps.Values(rule.Tiles)
+}
+
+// matches checks if a neighbor with the given terrain counts as the same terrain.
+func (rule *TerrainRule) matches(terrain string) bool {
+	return terrain == rule.Name || slices.Contains(rule.Matches, terrain)
+}
+
+func (rule *TerrainRule) mask(match func(dCol, dRow int) bool) int {
+	n, e, s, w := match(0, -1), match(1, 0), match(0, 1), match(-1, 0)
+	ne, se, sw, nw := match(1, -1), match(1, 1), match(-1, 1), match(-1, -1)
+
+	switch rule.Kind {
+	case AutoTileWangEdge:
+		return bits(n, e, s, w)
+	case AutoTileWangCorner:
+		return bits(n && e && ne, s && e && se, s && w && sw, n && w && nw)
+	}
+
+	return bits(n, n && e && ne, e, s && e && se, s, s && w && sw, w, n && w && nw)
+}
+
+// BlobMasks gets the 47 blob masks, in increasing order.
+func BlobMasks() []int {
+	masks := []int{}
+
+	for mask := 0; mask < 256; mask++ {
+		if reduceBlobMask(mask) == mask {
+			masks = append(masks, mask)
+		}
+	}
+
+	return masks
+}
+
+// reduceBlobMask clears the corners without both edges next to them.
+func reduceBlobMask(mask int) int {
+	corners := [][3]int{{MaskNE, MaskN, MaskE}, {MaskSE, MaskS, MaskE}, {MaskSW, MaskS, MaskW}, {MaskNW, MaskN, MaskW}}
+
+	for _, c := range corners {
+		if mask&c[1] == 0 || mask&c[2] == 0 {
+			mask &^= c[0]
+		}
+	}
+
+	return mask
+}
+
+func bits(set ...bool) int {
+	mask := 0
+
+	for i, b := range set {
+		if b {
+			mask |= 1 << i
+		}
+	}
+
+	return mask
+}
+
+// TerrainGrid is a grid of terrain cells, with the tile IDs picked for
+// them by autotile rules. Cells beyond the edges of the grid match every
+// terrain, so terrain edges aren't drawn along the grid edges.
+type TerrainGrid struct {
+	rules        *AutoTileRules
+	emptyTerrain string
+	terrain      [][]string
+	tileIDs      [][]string
+}
+
+// NewTerrainGrid makes a terrain grid from rows of terrain names. Rows
+// can be left short, and are padded with the empty terrain, which gets
+// the empty tile.
+func NewTerrainGrid(rules *AutoTileRules, terrainRows []string, emptyTerrain string) (*TerrainGrid, error) {
+	nCols := 0
+	terrain := make([][]string, len(terrainRows))
+
+	for i, terrainRow := range terrainRows {
+		terrain[i] = strings.Split(terrainRow, RefIDSeparator)
+
+		if len(terrain[i]) > nCols {
+			nCols = len(terrain[i])
+		}
+	}
+
+	tileIDs := make([][]string, len(terrain))
+
+	for i := range terrain {
+		for len(terrain[i]) < nCols {
+			terrain[i] = append(terrain[i], emptyTerrain)
+		}
+
+		tileIDs[i] = make([]string, nCols)
+	}
+
+	g := &TerrainGrid{
+		rules:        rules,
+		emptyTerrain: emptyTerrain,
+		terrain:      terrain,
+		tileIDs:      tileIDs,
+	}
+
+	for row := range terrain {
+		for col := range terrain[row] {
+			if err := g.pickTile(col, row); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return g, nil
+}
+
+// Terrain gets the terrain of a cell.
+func (g *TerrainGrid) Terrain(col, row int) (string, bool) {
+	if !g.inside(col, row) {
+		return "", false
+	}
+
+	return g.terrain[row][col], true
+}
+
+// TileID gets the tile ID picked for a cell.
+func (g *TerrainGrid) TileID(col, row int) (string, bool) {
+	if !g.inside(col, row) {
+		return "", false
+	}
+
+	return g.tileIDs[row][col], true
+}
+
+// SetTerrain changes the terrain of a cell, then picks the tiles again for
+// it and its neighbors. The cells with a changed tile ID are returned, as
+// points with the column in X and the row in Y.
+func (g *TerrainGrid) SetTerrain(col, row int, terrain string) ([]topdown.Point[int], error) {
+	if !g.inside(col, row) {
+		return nil, fmt.Errorf("cell %d,%d is outside the grid", col, row)
+	}
+
+	if _, found := g.rules.rules[terrain]; !found && terrain != g.emptyTerrain {
+		return nil, fmt.Errorf("terrain '%s' has no rule", terrain)
+	}
+
+	changed := []topdown.Point[int]{}
+
+	g.terrain[row][col] = terrain
+
+	for r := row - 1; r <= row+1; r++ {
+		for c := col - 1; c <= col+1; c++ {
+			if !g.inside(c, r) {
+				continue
+			}
+
+			tileID := g.tileIDs[r][c]
+
+			if err := g.pickTile(c, r); err != nil {
+				return nil, err
+			}
+
+			if g.tileIDs[r][c] != tileID {
+				changed = append(changed, topdown.Pt(c, r))
+			}
+		}
+	}
+
+	return changed, nil
+}
+
+// TerrainRows gets the terrain as rows of terrain names.
+func (g *TerrainGrid) TerrainRows() []string {
+	return joinRows(g.terrain)
+}
+
+// TileRows gets the tile IDs picked for the terrain, as tile rows with
+// the given empty tile ID for the empty terrain.
+func (g *TerrainGrid) TileRows(emptyTile string) []string {
+	rows := make([][]string, len(g.tileIDs))
+
+	for i, tileIDs := range g.tileIDs {
+		rows[i] = slices.Clone(tileIDs)
+
+		for j, tileID := range rows[i] {
+			if tileID == "" {
+				rows[i][j] = emptyTile
+			}
+		}
+	}
+
+	return joinRows(rows)
+}
+
+func (g *TerrainGrid) inside(col, row int) bool {
+	return row >= 0 && row < len(g.terrain) && col >= 0 && col < len(g.terrain[row])
+}
+
+func (g *TerrainGrid) pickTile(col, row int) error {
+	terrain := g.terrain[row][col]
+	if terrain == g.emptyTerrain {
+		g.tileIDs[row][col] = ""
+
+		return nil
+	}
+
+	rule, found := g.rules.rules[terrain]
+	if !found {
+		return fmt.Errorf("terrain '%s' at %d,%d has no rule", terrain, col, row)
+	}
+
+	tileID, err := g.rules.TileID(terrain, func(dCol, dRow int) bool {
+		neighbor, found := g.Terrain(col+dCol, row+dRow)
+
+		return !found || rule.matches(neighbor)
+	})
+	if err != nil {
+		return err
+	}
+
+	g.tileIDs[row][col] = tileID
+
+	return nil
+}
+
+func joinRows(rows [][]string) []string {
+	joined := make([]string, len(rows))
+
+	for i, row := range rows {
+		joined[i] = strings.Join(row, RefIDSeparator)
+	}
+
+	return joined
+}
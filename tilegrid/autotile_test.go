@@ -0,0 +1,216 @@
+package tilegrid_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/resource/restest"
+	"github.com/jamestunnell/topdown/tilegrid"
+)
+
+func TestBlobMasks(t *testing.T) {
+	masks := tilegrid.BlobMasks()
+
+	assert.Len(t, masks, 47)
+	assert.Contains(t, masks, 0)
+	assert.Contains(t, masks, 255)
+	// a corner without both edges next to it
+	assert.NotContains(t, masks, tilegrid.MaskN|tilegrid.MaskNE)
+	assert.Contains(t, masks, tilegrid.MaskN|tilegrid.MaskNE|tilegrid.MaskE)
+}
+
+func TestAutoTileRulesInvalid(t *testing.T) {
+	testCases := map[string][]*tilegrid.TerrainRule{
+		"duplicate":    {{Name: "grass", Tile: "G"}, {Name: "grass", Tile: "H"}},
+		"blank tile":   {{Name: "grass"}},
+		"unknown kind": {{Name: "water", Kind: "wang3", Tiles: maskTiles("W", 16)}},
+		"missing mask": {{Name: "water", Kind: tilegrid.AutoTileWangEdge, Tiles: maskTiles("W", 15)}},
+		"missing blob": {{Name: "water", Kind: tilegrid.AutoTileBlob47, Tiles: maskTiles("W", 16)}},
+	}
+
+	for name, terrains := range testCases {
+		t.Run(name, func(t *testing.T) {
+			rules := &tilegrid.AutoTileRules{Terrains: terrains}
+
+			assert.Error(t, rules.Initialize(nil))
+		})
+	}
+}
+
+func TestTerrainGridWangEdge(t *testing.T) {
+	g := makeTerrainGrid(t, tilegrid.AutoTileWangEdge, "grass grass grass", "grass water water", "grass water grass")
+
+	// cells beyond the edge match
+	assertTileRows(t, g, "G G G", "G W6 W10", "G W5 G")
+
+	changed, err := g.SetTerrain(2, 2, "water")
+
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []topdown.Point[int]{topdown.Pt(1, 2), topdown.Pt(2, 1), topdown.Pt(2, 2)}, changed)
+	assertTileRows(t, g, "G G G", "G W6 W14", "G W7 W15")
+
+	terrain, found := g.Terrain(2, 2)
+
+	assert.True(t, found)
+	assert.Equal(t, "water", terrain)
+	assert.Equal(t, []string{"grass grass grass", "grass water water", "grass water water"}, g.TerrainRows())
+
+	_, err = g.SetTerrain(3, 0, "water")
+
+	assert.Error(t, err)
+
+	_, err = g.SetTerrain(0, 0, "lava")
+
+	assert.Error(t, err)
+}
+
+func TestTerrainGridWangCorner(t *testing.T) {
+	g := makeTerrainGrid(t, tilegrid.AutoTileWangCorner, "grass water water", "grass water water", "- - -")
+
+	// NE=1, SE=2, SW=4, NW=8, where the cells beyond the edge match
+	assertTileRows(t, g, "G W3 W15", "G W1 W9", "- - -")
+}
+
+func TestTerrainGridBlob47(t *testing.T) {
+	g := makeTerrainGrid(t, tilegrid.AutoTileBlob47, "grass grass grass", "grass water grass", "grass grass grass")
+
+	assertTileRows(t, g, "G G G", "G W0 G", "G G G")
+
+	// the NE corner doesn't count without the E edge
+	for _, col := range []int{1, 2} {
+		_, err := g.SetTerrain(col, 0, "water")
+
+		require.NoError(t, err)
+	}
+
+	assertTileID(t, g, 1, 1, tilegrid.MaskN)
+
+	_, err := g.SetTerrain(2, 1, "water")
+
+	require.NoError(t, err)
+
+	assertTileID(t, g, 1, 1, tilegrid.MaskN|tilegrid.MaskNE|tilegrid.MaskE)
+}
+
+func TestTileMapAutoTile(t *testing.T) {
+	dir := t.TempDir()
+	rules := &tilegrid.AutoTileRules{
+		Terrains: []*tilegrid.TerrainRule{
+			{Name: "grass", Tile: "G"},
+			{Name: "flowers", Tile: "F"},
+			{Name: "wall", Kind: tilegrid.AutoTileWangEdge, Tiles: maskTiles("W", 16), Matches: []string{"door"}},
+			{Name: "door", Tile: "D"},
+		},
+	}
+
+	d, err := json.Marshal(rules)
+
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "terrain.autotile"), d, 0o600))
+
+	autoTileType, err := tilegrid.NewAutoTileType()
+
+	require.NoError(t, err)
+
+	mgr := restest.SetupManager(t, dir, autoTileType)
+	links := map[string]*tilegrid.TileLink{"G": {}, "F": {}, "D": {}}
+
+	for _, tileID := range maskTiles("W", 16) {
+		links[tileID] = &tilegrid.TileLink{Solid: true}
+	}
+
+	m := &tilegrid.TileMap{
+		TileSize:  topdown.Size[int]{Width: 10, Height: 10},
+		TileLinks: links,
+		Layers: []*tilegrid.TileLayer{
+			{
+				Name:        "ground",
+				AutoTile:    "terrain.autotile",
+				TerrainRows: []string{"wall wall door", "grass flowers grass"},
+			},
+		},
+	}
+
+	require.NoError(t, m.Initialize(mgr))
+
+	// the wall matches the door, but not the flowers
+	assert.Equal(t, []string{"W11 W11 D", "G F G"}, m.Layers[0].TileRows)
+	assert.Len(t, m.StaticColliderShapes(), 1)
+
+	require.NoError(t, m.SetTerrain("ground", 1, 1, "wall"))
+
+	assert.Equal(t, []string{"W11 W15 D", "G W5 G"}, m.Layers[0].TileRows)
+	assert.Equal(t, []string{"wall wall door", "grass wall grass"}, m.Layers[0].TerrainRows)
+
+	grid, found := m.Layer("ground")
+
+	require.True(t, found)
+	assert.Equal(t, []string{"W11 W15 D", "G W5 G"}, grid.CurrentTileRows())
+
+	tile, found := grid.TileAt(topdown.Pt(15.0, 15.0))
+
+	require.True(t, found)
+	assert.True(t, tile.Solid)
+	assert.Len(t, m.StaticColliderShapes(), 2)
+
+	assert.Error(t, m.SetTerrain("ground", 0, 0, "lava"))
+	assert.Error(t, m.SetTerrain("roof", 0, 0, "grass"))
+
+	// the rules use tiles that aren't linked
+	delete(links, "W15")
+
+	m.Layers[0].TerrainRows = []string{"grass"}
+
+	assert.Error(t, m.Initialize(mgr))
+}
+
+func makeTerrainGrid(t *testing.T, kind string, terrainRows ...string) *tilegrid.TerrainGrid {
+	nMasks := 16
+	if kind == tilegrid.AutoTileBlob47 {
+		nMasks = 256
+	}
+
+	rules := &tilegrid.AutoTileRules{
+		Terrains: []*tilegrid.TerrainRule{
+			{Name: "grass", Tile: "G"},
+			{Name: "water", Kind: kind, Tiles: maskTiles("W", nMasks)},
+		},
+	}
+
+	require.NoError(t, rules.Initialize(nil))
+
+	g, err := tilegrid.NewTerrainGrid(rules, terrainRows, tilegrid.DefaultEmptyTile)
+
+	require.NoError(t, err)
+
+	return g
+}
+
+func assertTileID(t *testing.T, g *tilegrid.TerrainGrid, col, row, mask int) {
+	tileID, found := g.TileID(col, row)
+
+	assert.True(t, found)
+	assert.Equal(t, fmt.Sprintf("W%d", mask), tileID)
+}
+
+func assertTileRows(t *testing.T, g *tilegrid.TerrainGrid, tileRows ...string) {
+	assert.Equal(t, tileRows, g.TileRows(tilegrid.DefaultEmptyTile))
+}
+
+// maskTiles makes tile IDs for the masks below n, like W0, W1 and so on.
+func maskTiles(prefix string, n int) map[int]string {
+	tiles := map[int]string{}
+
+	for mask := 0; mask < n; mask++ {
+		tiles[mask] = fmt.Sprintf("%s%d", prefix, mask)
+	}
+
+	return tiles
+}
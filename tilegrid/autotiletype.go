@@ -0,0 +1,31 @@
+package tilegrid
+
+import (
+	"fmt"
+
+	"github.com/xeipuuv/gojsonschema"
+
+	"github.com/jamestunnell/topdown/jsonfile"
+	"github.com/jamestunnell/topdown/resource"
+)
+
+type AutoTileType struct {
+	schema *gojsonschema.Schema
+}
+
+func NewAutoTileType() (resource.Type, error) {
+	schema, err := resource.MakeJSONSchema(AutoTileSchemaStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make JSON schema: %w", err)
+	}
+
+	return &AutoTileType{schema: schema}, nil
+}
+
+func (at *AutoTileType) Name() string {
+	return "autotile"
+}
+
+func (at *AutoTileType) Load(path string) (resource.Resource, error) {
+	return jsonfile.ReadAndValidate[*AutoTileRules](path, at.schema)
+}
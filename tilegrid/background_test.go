@@ -3,7 +3,9 @@ package tilegrid_test
 import (
 	"testing"
 
+	"github.com/jamestunnell/topdown/animation"
 	"github.com/jamestunnell/topdown/drawing"
+	"github.com/jamestunnell/topdown/movecollide"
 	"github.com/jamestunnell/topdown/tilegrid"
 	"github.com/stretchr/testify/assert"
 )
@@ -12,6 +14,18 @@ func TestBackgroundIsDrawable(t *testing.T) {
 	testBackgroundIs[drawing.Drawable](t)
 }
 
+func TestBackgroundIsAnimatable(t *testing.T) {
+	testBackgroundIs[animation.Animatable](t)
+}
+
+func TestBackgroundIsStaticCollidable(t *testing.T) {
+	testBackgroundIs[movecollide.StaticCollidable](t)
+}
+
+func TestBackgroundIsSurfaceSource(t *testing.T) {
+	testBackgroundIs[movecollide.SurfaceSource](t)
+}
+
 func testBackgroundIs[T any](t *testing.T) {
 	var x interface{}
 
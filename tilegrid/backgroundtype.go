@@ -7,6 +7,7 @@ import (
 
 	"github.com/jamestunnell/topdown"
 	"github.com/jamestunnell/topdown/jsonfile"
+	"github.com/jamestunnell/topdown/movecollide"
 	"github.com/jamestunnell/topdown/resource"
 )
 
@@ -19,7 +20,10 @@ func NewBackgroundType() (resource.Type, error) {
 		TileGridSchemaStr,
 		topdown.VectorSchemaStr,
 		topdown.SizeSchemaStr,
-		topdown.PointSchemaStr)
+		topdown.PointSchemaStr,
+		TileLinkSchemaStr,
+		movecollide.ColliderSchemaStr,
+		movecollide.SurfaceSchemaStr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make JSON schema: %w", err)
 	}
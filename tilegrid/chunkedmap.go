@@ -0,0 +1,448 @@
+package tilegrid
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/xeipuuv/gojsonschema"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/jsonfile"
+	"github.com/jamestunnell/topdown/movecollide"
+	"github.com/jamestunnell/topdown/resource"
+)
+
+// ChunkedMap is a large or unbounded map split into chunks of a fixed
+// number of tiles. Chunks near the focus area, like the camera world area,
+// are loaded in the background and added to the attached systems, while
+// chunks beyond the keep-alive radius are removed again.
+//
+// Add the chunked map to the animation system, so the tiles of all the
+// chunks animate in sync, and attach the drawing and move-collide systems.
+// The move-collide system should be unbounded (see
+// movecollide.NewUnboundedSystem).
+type ChunkedMap struct {
+	Origin    topdown.Point[float64] `json:"origin"`
+	TileSize  topdown.Size[int]      `json:"tileSize"`
+	ChunkSize topdown.Size[int]      `json:"chunkSize"`
+	TileLinks map[string]*TileLink   `json:"tileLinks"`
+	// EmptyTile is the tile ID used in rows where there is no tile.
+	// DefaultEmptyTile is used if it is blank.
+	EmptyTile string `json:"emptyTile,omitempty"`
+	// ChunkDir is the directory with the chunk files, relative to the map
+	// file (see DirChunkSource).
+	ChunkDir string `json:"chunkDir,omitempty"`
+	// LoadRadius is the number of chunks around the focus area that are
+	// loaded ahead of time.
+	LoadRadius int `json:"loadRadius,omitempty"`
+	// KeepAliveRadius is the number of chunks around the focus area that
+	// stay loaded. The load radius is used if it is smaller.
+	KeepAliveRadius int `json:"keepAliveRadius,omitempty"`
+	// RetryDelay is how long to wait before loading a chunk again after it
+	// failed to load, like "10s". DefaultChunkRetryDelay is used if it is
+	// blank.
+	RetryDelay string `json:"retryDelay,omitempty"`
+
+	dir        string
+	source     ChunkSource
+	tiles      map[string]*Tile
+	id         string
+	systems    []ChunkSystem
+	chunks     map[topdown.Point[int]]*TileMap
+	pending    map[topdown.Point[int]]bool
+	retryAt    map[topdown.Point[int]]time.Time
+	retryDelay time.Duration
+	elapsed    time.Duration
+
+	mutex   sync.Mutex
+	loaded  []*loadedChunk
+	loading sync.WaitGroup
+}
+
+// Chunk has the layers and objects of a chunk. Layers use tile rows, and
+// positions are in world space.
+type Chunk struct {
+	Layers     []*TileLayer  `json:"layers"`
+	Spawns     []*SpawnPoint `json:"spawns,omitempty"`
+	Triggers   []*Area       `json:"triggers,omitempty"`
+	Colliders  []*Area       `json:"colliders,omitempty"`
+	Properties Properties    `json:"properties,omitempty"`
+}
+
+// ChunkSource loads chunks by chunk coordinate, which counts chunks from
+// the map origin. LoadChunk is called from background goroutines, and
+// returns nil where there is no chunk.
+type ChunkSource interface {
+	LoadChunk(coord topdown.Point[int]) (*Chunk, error)
+}
+
+// ChunkSourceFunc makes a chunk source from a func, like a generator.
+type ChunkSourceFunc func(coord topdown.Point[int]) (*Chunk, error)
+
+// ChunkSystem is a system that chunks are added to once they load, like
+// the drawing and move-collide systems. Chunks are added as tile maps.
+type ChunkSystem interface {
+	Add(id string, x interface{})
+	Remove(id string)
+}
+
+// DirChunkSource loads chunks from JSON files in a directory, which are
+// named after the chunk coordinate, like "-1_2.chunk". The files are
+// validated with the chunk schema if the source is made by
+// NewDirChunkSource.
+type DirChunkSource struct {
+	Dir string
+
+	schema *gojsonschema.Schema
+}
+
+type loadedChunk struct {
+	coord   topdown.Point[int]
+	tileMap *TileMap
+	err     error
+}
+
+const (
+	// ChunkFileExt is the extension of chunk files.
+	ChunkFileExt = ".chunk"
+	// DefaultChunkRetryDelay is how long to wait by default before loading
+	// a chunk again after it failed to load.
+	DefaultChunkRetryDelay = 5 * time.Second
+)
+
+func (f ChunkSourceFunc) LoadChunk(coord topdown.Point[int]) (*Chunk, error) {
+	return f(coord)
+}
+
+// NewDirChunkSource makes a source for the chunk files in a directory,
+// which validates the files with the chunk schema.
+func NewDirChunkSource(dir string) (*DirChunkSource, error) {
+	schema, err := resource.MakeJSONSchema(
+		ChunkSchemaStr,
+		TileMapSchemaStr,
+		topdown.SizeSchemaStr,
+		topdown.PointSchemaStr,
+		TileLinkSchemaStr,
+		movecollide.ColliderSchemaStr,
+		movecollide.SurfaceSchemaStr,
+		topdown.VectorSchemaStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make JSON schema: %w", err)
+	}
+
+	return &DirChunkSource{Dir: dir, schema: schema}, nil
+}
+
+func (src *DirChunkSource) LoadChunk(coord topdown.Point[int]) (*Chunk, error) {
+	path := filepath.Join(src.Dir, fmt.Sprintf("%d_%d%s", coord.X, coord.Y, ChunkFileExt))
+
+	chunk, err := jsonfile.ReadAndValidate[*Chunk](path, src.schema)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	return chunk, err
+}
+
+// SetSource sets the chunk source, which is used instead of the chunk dir.
+func (m *ChunkedMap) SetSource(src ChunkSource) {
+	m.source = src
+}
+
+func (m *ChunkedMap) Initialize(mgr resource.Manager) error {
+	if m.TileSize.Width <= 0 || m.TileSize.Height <= 0 {
+		return fmt.Errorf("tile size %v is not positive", m.TileSize)
+	}
+
+	if m.ChunkSize.Width <= 0 || m.ChunkSize.Height <= 0 {
+		return fmt.Errorf("chunk size %v is not positive", m.ChunkSize)
+	}
+
+	m.retryDelay = DefaultChunkRetryDelay
+
+	if m.RetryDelay != "" {
+		delay, err := time.ParseDuration(m.RetryDelay)
+		if err != nil {
+			return fmt.Errorf("failed to parse retry delay: %w", err)
+		}
+
+		if delay < 0 {
+			return fmt.Errorf("retry delay %s is negative", m.RetryDelay)
+		}
+
+		m.retryDelay = delay
+	}
+
+	if m.source == nil {
+		if m.ChunkDir == "" {
+			return fmt.Errorf("map has no chunk source or chunk dir")
+		}
+
+		src, err := NewDirChunkSource(filepath.Join(m.dir, m.ChunkDir))
+		if err != nil {
+			return err
+		}
+
+		m.source = src
+	}
+
+	tiles, err := MakeTiles(mgr, m.TileLinks, m.TileSize)
+	if err != nil {
+		return err
+	}
+
+	m.tiles = tiles
+	m.chunks = map[topdown.Point[int]]*TileMap{}
+	m.pending = map[topdown.Point[int]]bool{}
+	m.retryAt = map[topdown.Point[int]]time.Time{}
+
+	return nil
+}
+
+// Attach sets the systems that loaded chunks are added to. Chunk IDs are
+// made from the given ID and the chunk coordinate, like "world/-1,2".
+func (m *ChunkedMap) Attach(id string, systems ...ChunkSystem) {
+	m.id = id
+	m.systems = systems
+}
+
+// Stream starts loading the chunks within the load radius of the focus
+// area that aren't loaded yet, adds the chunks that finished loading,
+// and removes the chunks beyond the keep-alive radius. Chunks that failed
+// to load are loaded again after the retry delay.
+func (m *ChunkedMap) Stream(focus topdown.Rectangle[float64]) {
+	min, max := m.ChunkAt(focus.Min), m.ChunkAt(focus.Max)
+	now := time.Now()
+
+	m.addLoaded(min, max)
+	m.unloadFar(min, max)
+
+	for y := min.Y - m.LoadRadius; y <= max.Y+m.LoadRadius; y++ {
+		for x := min.X - m.LoadRadius; x <= max.X+m.LoadRadius; x++ {
+			coord := topdown.Pt(x, y)
+
+			if _, found := m.chunks[coord]; found || m.pending[coord] {
+				continue
+			}
+
+			if retryAt, found := m.retryAt[coord]; found && now.Before(retryAt) {
+				continue
+			}
+
+			m.load(coord)
+		}
+	}
+}
+
+// Wait waits for the chunks being loaded, then adds them. This is useful
+// for loading the first chunks before play starts.
+func (m *ChunkedMap) Wait(focus topdown.Rectangle[float64]) {
+	m.loading.Wait()
+
+	m.Stream(focus)
+	m.loading.Wait()
+
+	m.addLoaded(m.ChunkAt(focus.Min), m.ChunkAt(focus.Max))
+}
+
+// ChunkAt gets the coordinate of the chunk at a world position.
+func (m *ChunkedMap) ChunkAt(pos topdown.Point[float64]) topdown.Point[int] {
+	w := float64(m.ChunkSize.Width * m.TileSize.Width)
+	h := float64(m.ChunkSize.Height * m.TileSize.Height)
+
+	return topdown.Pt(
+		int(math.Floor((pos.X-m.Origin.X)/w)),
+		int(math.Floor((pos.Y-m.Origin.Y)/h)))
+}
+
+// Chunk gets the tile map of a loaded chunk.
+func (m *ChunkedMap) Chunk(coord topdown.Point[int]) (*TileMap, bool) {
+	tileMap, found := m.chunks[coord]
+
+	return tileMap, found && tileMap != nil
+}
+
+// LoadedChunks gets the coordinates of the loaded chunks, including those
+// without a tile map, sorted by row and then column.
+func (m *ChunkedMap) LoadedChunks() []topdown.Point[int] {
+	coords := maps.Keys(m.chunks)
+
+	slices.SortFunc(coords, coordLess)
+
+	return coords
+}
+
+// UpdateAnimation advances the tile animations of the loaded chunks.
+// Chunks that load later start at the same point.
+func (m *ChunkedMap) UpdateAnimation(delta time.Duration) {
+	m.elapsed += delta
+
+	for _, tileMap := range m.chunks {
+		if tileMap != nil {
+			tileMap.UpdateAnimation(delta)
+		}
+	}
+}
+
+func (m *ChunkedMap) load(coord topdown.Point[int]) {
+	m.pending[coord] = true
+
+	m.loading.Add(1)
+
+	go func() {
+		defer m.loading.Done()
+
+		tileMap, err := m.loadChunk(coord)
+
+		m.mutex.Lock()
+		defer m.mutex.Unlock()
+
+		m.loaded = append(m.loaded, &loadedChunk{coord: coord, tileMap: tileMap, err: err})
+	}()
+}
+
+// loadChunk loads a chunk and makes its tile map, using the tiles made
+// by Initialize.
+func (m *ChunkedMap) loadChunk(coord topdown.Point[int]) (*TileMap, error) {
+	chunk, err := m.source.LoadChunk(coord)
+	if err != nil || chunk == nil {
+		return nil, err
+	}
+
+	for _, layer := range chunk.Layers {
+		// autotile rules are resources, which can't be got while loading
+		if layer.AutoTile != "" {
+			return nil, fmt.Errorf("layer '%s' is autotiled, which chunks do not support", layer.Name)
+		}
+
+		if len(layer.TileRows) > m.ChunkSize.Height {
+			return nil, fmt.Errorf("layer '%s' has more than %d rows", layer.Name, m.ChunkSize.Height)
+		}
+
+		for _, tileRow := range layer.TileRows {
+			if n := len(strings.Split(tileRow, RefIDSeparator)); n > m.ChunkSize.Width {
+				return nil, fmt.Errorf("layer '%s' has a row with %d tiles instead of %d", layer.Name, n, m.ChunkSize.Width)
+			}
+		}
+	}
+
+	tileMap := &TileMap{
+		Origin: topdown.Pt(
+			m.Origin.X+float64(coord.X*m.ChunkSize.Width*m.TileSize.Width),
+			m.Origin.Y+float64(coord.Y*m.ChunkSize.Height*m.TileSize.Height)),
+		TileSize:   m.TileSize,
+		TileLinks:  m.TileLinks,
+		EmptyTile:  m.EmptyTile,
+		Layers:     chunk.Layers,
+		Spawns:     chunk.Spawns,
+		Triggers:   chunk.Triggers,
+		Colliders:  chunk.Colliders,
+		Properties: chunk.Properties,
+	}
+
+	if err := tileMap.InitializeTiles(m.tiles); err != nil {
+		return nil, err
+	}
+
+	return tileMap, nil
+}
+
+// addLoaded adds the chunks that finished loading, unless they are
+// beyond the keep-alive radius by now. Chunks that failed to load are not
+// added, so they can be loaded again after the retry delay.
+func (m *ChunkedMap) addLoaded(min, max topdown.Point[int]) {
+	m.mutex.Lock()
+	loaded := m.loaded
+	m.loaded = nil
+	m.mutex.Unlock()
+
+	// chunks finish loading in any order, but are added in a fixed order
+	slices.SortFunc(loaded, func(a, b *loadedChunk) bool {
+		return coordLess(a.coord, b.coord)
+	})
+
+	for _, l := range loaded {
+		delete(m.pending, l.coord)
+
+		if l.err != nil {
+			log.Warn().Err(l.err).Int("x", l.coord.X).Int("y", l.coord.Y).Msg("failed to load chunk")
+
+			m.retryAt[l.coord] = time.Now().Add(m.retryDelay)
+
+			continue
+		}
+
+		delete(m.retryAt, l.coord)
+
+		if !m.keepAlive(l.coord, min, max) {
+			continue
+		}
+
+		m.chunks[l.coord] = l.tileMap
+
+		if l.tileMap == nil {
+			continue
+		}
+
+		l.tileMap.UpdateAnimation(m.elapsed)
+
+		for _, s := range m.systems {
+			s.Add(m.chunkID(l.coord), l.tileMap)
+		}
+	}
+}
+
+func (m *ChunkedMap) unloadFar(min, max topdown.Point[int]) {
+	maps.DeleteFunc(m.retryAt, func(coord topdown.Point[int], _ time.Time) bool {
+		return !m.keepAlive(coord, min, max)
+	})
+
+	for _, coord := range m.LoadedChunks() {
+		if m.keepAlive(coord, min, max) {
+			continue
+		}
+
+		tileMap := m.chunks[coord]
+
+		delete(m.chunks, coord)
+
+		if tileMap == nil {
+			continue
+		}
+
+		for _, s := range m.systems {
+			s.Remove(m.chunkID(coord))
+		}
+	}
+}
+
+func (m *ChunkedMap) keepAlive(coord, min, max topdown.Point[int]) bool {
+	r := m.KeepAliveRadius
+	if r < m.LoadRadius {
+		r = m.LoadRadius
+	}
+
+	return coord.X >= min.X-r && coord.X <= max.X+r && coord.Y >= min.Y-r && coord.Y <= max.Y+r
+}
+
+func (m *ChunkedMap) chunkID(coord topdown.Point[int]) string {
+	return fmt.Sprintf("%s/%d,%d", m.id, coord.X, coord.Y)
+}
+
+// coordLess orders chunk coordinates by row, and then by column.
+func coordLess(a, b topdown.Point[int]) bool {
+	if a.Y != b.Y {
+		return a.Y < b.Y
+	}
+
+	return a.X < b.X
+}
@@ -0,0 +1,266 @@
+package tilegrid_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/movecollide"
+	"github.com/jamestunnell/topdown/resource/restest"
+	"github.com/jamestunnell/topdown/tilegrid"
+)
+
+type chunkRecorder struct {
+	added, removed []string
+}
+
+func (r *chunkRecorder) Add(id string, x interface{}) {
+	r.added = append(r.added, id)
+}
+
+func (r *chunkRecorder) Remove(id string) {
+	r.removed = append(r.removed, id)
+}
+
+func TestChunkedMapStream(t *testing.T) {
+	m := &tilegrid.ChunkedMap{
+		TileSize:        topdown.Size[int]{Width: 10, Height: 10},
+		ChunkSize:       topdown.Size[int]{Width: 4, Height: 2},
+		TileLinks:       map[string]*tilegrid.TileLink{"W": {Solid: true}},
+		LoadRadius:      1,
+		KeepAliveRadius: 2,
+	}
+
+	// a row of chunks, with a bad chunk at the end
+	m.SetSource(tilegrid.ChunkSourceFunc(func(coord topdown.Point[int]) (*tilegrid.Chunk, error) {
+		switch {
+		case coord.Y != 0 || coord.X < -1:
+			return nil, nil
+		case coord.X == 3:
+			return &tilegrid.Chunk{
+				Layers: []*tilegrid.TileLayer{{Name: "ground", TileRows: []string{"W W W W W"}}},
+			}, nil
+		}
+
+		return &tilegrid.Chunk{
+			Layers: []*tilegrid.TileLayer{{Name: "ground", TileRows: []string{"W - - W", "W W - -"}}},
+		}, nil
+	}))
+
+	require.NoError(t, m.Initialize(nil))
+
+	r := &chunkRecorder{}
+
+	m.Attach("world", r)
+
+	// the focus is in chunk 0,0
+	m.Wait(focusArea(15, 5))
+
+	// added in order, whichever chunk finished loading first
+	assert.Equal(t, []string{"world/-1,0", "world/0,0", "world/1,0"}, r.added)
+	assert.Equal(t, []topdown.Point[int]{
+		{X: -1, Y: -1}, {X: 0, Y: -1}, {X: 1, Y: -1},
+		{X: -1, Y: 0}, {X: 0, Y: 0}, {X: 1, Y: 0},
+		{X: -1, Y: 1}, {X: 0, Y: 1}, {X: 1, Y: 1},
+	}, m.LoadedChunks())
+
+	chunk, found := m.Chunk(topdown.Pt(1, 0))
+
+	require.True(t, found)
+	assert.Equal(t, topdown.Pt(40.0, 0.0), chunk.Origin)
+	assert.NotEmpty(t, chunk.StaticColliderShapes())
+
+	_, found = m.Chunk(topdown.Pt(0, 1))
+
+	assert.False(t, found)
+
+	// the focus moves to chunk 2,0, so chunk -1,0 is beyond the keep-alive radius
+	r.added = nil
+
+	m.Wait(focusArea(95, 5))
+
+	assert.Equal(t, []string{"world/2,0"}, r.added)
+	assert.Equal(t, []string{"world/-1,0"}, r.removed)
+
+	_, found = m.Chunk(topdown.Pt(0, 0))
+
+	assert.True(t, found)
+
+	// the bad chunk isn't added
+	_, found = m.Chunk(topdown.Pt(3, 0))
+
+	assert.False(t, found)
+
+	// the focus moves far away, so the chunks are removed in order
+	r.removed = nil
+
+	m.Wait(focusArea(1000, 5))
+
+	assert.Equal(t, []string{"world/0,0", "world/1,0", "world/2,0"}, r.removed)
+}
+
+func TestChunkedMapRetry(t *testing.T) {
+	for _, delay := range []string{"0s", "1h"} {
+		t.Run(delay, func(t *testing.T) {
+			var mutex sync.Mutex
+
+			loads := 0
+			m := &tilegrid.ChunkedMap{
+				TileSize:   topdown.Size[int]{Width: 10, Height: 10},
+				ChunkSize:  topdown.Size[int]{Width: 2, Height: 2},
+				TileLinks:  map[string]*tilegrid.TileLink{"W": {Solid: true}},
+				RetryDelay: delay,
+			}
+
+			// the chunk fails to load the first time
+			m.SetSource(tilegrid.ChunkSourceFunc(func(coord topdown.Point[int]) (*tilegrid.Chunk, error) {
+				mutex.Lock()
+				defer mutex.Unlock()
+
+				if loads++; loads == 1 {
+					return nil, errors.New("disk error")
+				}
+
+				return &tilegrid.Chunk{
+					Layers: []*tilegrid.TileLayer{{Name: "ground", TileRows: []string{"W W", "W W"}}},
+				}, nil
+			}))
+
+			require.NoError(t, m.Initialize(nil))
+
+			m.Wait(focusArea(5, 5))
+
+			_, found := m.Chunk(topdown.Pt(0, 0))
+
+			assert.False(t, found)
+			assert.Empty(t, m.LoadedChunks())
+
+			m.Wait(focusArea(5, 5))
+
+			_, found = m.Chunk(topdown.Pt(0, 0))
+
+			// loaded again only once the delay is over
+			assert.Equal(t, delay == "0s", found)
+		})
+	}
+
+	m := &tilegrid.ChunkedMap{
+		TileSize:   topdown.Size[int]{Width: 10, Height: 10},
+		ChunkSize:  topdown.Size[int]{Width: 2, Height: 2},
+		RetryDelay: "soon",
+	}
+
+	m.SetSource(tilegrid.ChunkSourceFunc(func(coord topdown.Point[int]) (*tilegrid.Chunk, error) {
+		return nil, nil
+	}))
+
+	assert.Error(t, m.Initialize(nil))
+}
+
+func TestChunkedMapRejectsAutoTileLayers(t *testing.T) {
+	m := &tilegrid.ChunkedMap{
+		TileSize:  topdown.Size[int]{Width: 10, Height: 10},
+		ChunkSize: topdown.Size[int]{Width: 2, Height: 2},
+		TileLinks: map[string]*tilegrid.TileLink{"W": {Solid: true}},
+	}
+
+	m.SetSource(tilegrid.ChunkSourceFunc(func(coord topdown.Point[int]) (*tilegrid.Chunk, error) {
+		return &tilegrid.Chunk{
+			Layers: []*tilegrid.TileLayer{{Name: "ground", AutoTile: "terrain.autotile", TerrainRows: []string{"wall"}}},
+		}, nil
+	}))
+
+	require.NoError(t, m.Initialize(nil))
+
+	m.Wait(focusArea(5, 5))
+
+	_, found := m.Chunk(topdown.Pt(0, 0))
+
+	assert.False(t, found)
+}
+
+func TestChunkedMapChunkAt(t *testing.T) {
+	m := &tilegrid.ChunkedMap{
+		Origin:    topdown.Pt(-5.0, 0.0),
+		TileSize:  topdown.Size[int]{Width: 10, Height: 10},
+		ChunkSize: topdown.Size[int]{Width: 4, Height: 2},
+	}
+
+	assert.Equal(t, topdown.Pt(0, 0), m.ChunkAt(topdown.Pt(-5.0, 0.0)))
+	assert.Equal(t, topdown.Pt(-1, -1), m.ChunkAt(topdown.Pt(-6.0, -1.0)))
+	assert.Equal(t, topdown.Pt(1, 2), m.ChunkAt(topdown.Pt(35.0, 40.0)))
+}
+
+func TestChunkedMapType(t *testing.T) {
+	dir := t.TempDir()
+	chunk := &tilegrid.Chunk{
+		Layers: []*tilegrid.TileLayer{{Name: "ground", TileRows: []string{"M M", "M M"}}},
+	}
+
+	d, err := json.Marshal(chunk)
+
+	require.NoError(t, err)
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "chunks"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "chunks", "0_-1.chunk"), d, 0o600))
+
+	// a layer without a name is left out by the chunk schema
+	require.NoError(t, os.WriteFile(
+		filepath.Join(dir, "chunks", "1_-1.chunk"), []byte(`{"layers": [{"tileRows": ["M M"]}]}`), 0o600))
+
+	mapJSON := `{
+		"origin": {"x": 0, "y": 0},
+		"tileSize": {"w": 8, "h": 8},
+		"chunkSize": {"w": 2, "h": 2},
+		"tileLinks": {"M": {"surface": {"name": "mud"}}},
+		"chunkDir": "chunks"
+	}`
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "world.chunkedmap"), []byte(mapJSON), 0o600))
+
+	chunkedMapType, err := tilegrid.NewChunkedMapType()
+
+	require.NoError(t, err)
+
+	mgr := restest.SetupManager(t, dir, chunkedMapType)
+
+	r, err := mgr.Get("world.chunkedmap")
+
+	require.NoError(t, err)
+
+	m, ok := r.(*tilegrid.ChunkedMap)
+
+	require.True(t, ok)
+
+	m.Wait(topdown.Rectangle[float64]{Min: topdown.Pt(0.0, -16.0), Max: topdown.Pt(31.0, 15.0)})
+
+	chunk2, found := m.Chunk(topdown.Pt(0, -1))
+
+	require.True(t, found)
+
+	surface, found := chunk2.SurfaceAt(topdown.Pt(4.0, -4.0))
+
+	require.True(t, found)
+	assert.Equal(t, &movecollide.Surface{Name: "mud"}, surface)
+
+	_, found = m.Chunk(topdown.Pt(0, 0))
+
+	assert.False(t, found)
+	assert.Contains(t, m.LoadedChunks(), topdown.Pt(0, 0))
+
+	// the invalid chunk failed to load
+	assert.NotContains(t, m.LoadedChunks(), topdown.Pt(1, -1))
+}
+
+func focusArea(x, y float64) topdown.Rectangle[float64] {
+	return topdown.Rectangle[float64]{
+		Min: topdown.Pt(x-1, y-1),
+		Max: topdown.Pt(x+1, y+1),
+	}
+}
@@ -0,0 +1,49 @@
+package tilegrid
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/xeipuuv/gojsonschema"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/jsonfile"
+	"github.com/jamestunnell/topdown/movecollide"
+	"github.com/jamestunnell/topdown/resource"
+)
+
+type ChunkedMapType struct {
+	schema *gojsonschema.Schema
+}
+
+func NewChunkedMapType() (resource.Type, error) {
+	schema, err := resource.MakeJSONSchema(
+		ChunkedMapSchemaStr,
+		topdown.SizeSchemaStr,
+		topdown.PointSchemaStr,
+		TileLinkSchemaStr,
+		movecollide.ColliderSchemaStr,
+		movecollide.SurfaceSchemaStr,
+		topdown.VectorSchemaStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make JSON schema: %w", err)
+	}
+
+	return &ChunkedMapType{schema: schema}, nil
+}
+
+func (ct *ChunkedMapType) Name() string {
+	return "chunkedmap"
+}
+
+func (ct *ChunkedMapType) Load(path string) (resource.Resource, error) {
+	m, err := jsonfile.ReadAndValidate[*ChunkedMap](path, ct.schema)
+	if err != nil {
+		return nil, err
+	}
+
+	// the chunk dir is relative to the map file
+	m.dir = filepath.Dir(path)
+
+	return m, nil
+}
@@ -0,0 +1,187 @@
+package tilegrid
+
+import (
+	"fmt"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/jsonfile"
+)
+
+// TileChange is a change to the tile in a cell of a grid.
+type TileChange struct {
+	// Cell has the column in X and the row in Y.
+	Cell topdown.Point[int]
+	// OldTileID and TileID are the tile IDs before and after the change.
+	OldTileID, TileID string
+	// Tile is the new tile, which is nil for the empty tile.
+	Tile *Tile
+	// CollidersChanged is set when the static colliders were made again,
+	// so the move-collide system needs to update the shapes of the grid.
+	CollidersChanged bool
+}
+
+// TileChangeListener is told about changes to the tiles of a grid.
+type TileChangeListener interface {
+	OnTileChange(c *TileChange)
+}
+
+// TileChangeListenerFunc adapts a function to be used as a TileChangeListener.
+type TileChangeListenerFunc func(c *TileChange)
+
+// OnTileChange calls the function.
+func (f TileChangeListenerFunc) OnTileChange(c *TileChange) {
+	f(c)
+}
+
+// AddChangeListener adds a listener to be told about tile changes.
+// Listeners are told in order of their IDs.
+func (tg *TileGrid) AddChangeListener(id string, l TileChangeListener) {
+	if tg.listeners == nil {
+		tg.listeners = map[string]TileChangeListener{}
+	}
+
+	tg.listeners[id] = l
+}
+
+func (tg *TileGrid) RemoveChangeListener(id string) {
+	delete(tg.listeners, id)
+}
+
+// WorldToTile gets the cell at a world position, with the column in X and
+// the row in Y, and whether the cell is inside the grid.
+func (tg *TileGrid) WorldToTile(pos topdown.Point[float64]) (topdown.Point[int], bool) {
+	cell := tg.cellAt(pos.Sub(tg.Origin))
+
+	return cell, tg.inside(cell)
+}
+
+// TileToWorld gets the world position of the top-left corner of the
+// bounding box of a cell.
+func (tg *TileGrid) TileToWorld(cell topdown.Point[int]) topdown.Point[float64] {
+	return tg.Origin.Add(tg.cellOffset(cell.X, cell.Y))
+}
+
+// Tile gets the tile in a cell.
+func (tg *TileGrid) Tile(cell topdown.Point[int]) (*Tile, bool) {
+	if !tg.inside(cell) {
+		return nil, false
+	}
+
+	tile := tg.rows[cell.Y].Tiles[cell.X]
+
+	return tile, tile != nil
+}
+
+// TileID gets the tile ID of a cell, which is the empty tile ID where
+// there is no tile.
+func (tg *TileGrid) TileID(cell topdown.Point[int]) (string, bool) {
+	if !tg.inside(cell) {
+		return "", false
+	}
+
+	return tg.tileIDs[cell.Y][cell.X], true
+}
+
+// CurrentTileRows gets the tile rows of the tiles now in the grid, with
+// any edits made since it was initialized.
+func (tg *TileGrid) CurrentTileRows() []string {
+	return joinRows(tg.tileIDs)
+}
+
+// SetTile changes the tile in a cell, keeping the tile ID so the grid can
+// be saved. The empty tile ID removes the tile. Colliders are made again
+// if the change affects them, and the change listeners are told about the
+// change.
+func (tg *TileGrid) SetTile(cell topdown.Point[int], tileID string) error {
+	oldTileID, found := tg.TileID(cell)
+	if !found {
+		return fmt.Errorf("cell %d,%d is outside the grid", cell.X, cell.Y)
+	}
+
+	var tile *Tile
+
+	if tileID != tg.emptyTile() {
+		if tile, found = tg.tiles[tileID]; !found {
+			return fmt.Errorf("tile '%s' not defined", tileID)
+		}
+	}
+
+	oldTile := tg.rows[cell.Y].Tiles[cell.X]
+
+	tg.setTile(cell.X, cell.Y, tileID, tile)
+
+	change := &TileChange{
+		Cell:      cell,
+		OldTileID: oldTileID,
+		TileID:    tileID,
+		Tile:      tile,
+	}
+
+	if collides(oldTile) || collides(tile) {
+		if err := tg.MakeColliders(); err != nil {
+			return fmt.Errorf("failed to make colliders: %w", err)
+		}
+
+		change.CollidersChanged = true
+	}
+
+	ids := maps.Keys(tg.listeners)
+
+	slices.Sort(ids)
+
+	for _, id := range ids {
+		tg.listeners[id].OnTileChange(change)
+	}
+
+	return nil
+}
+
+// Save writes the grid to a JSON file, in the same format it is loaded
+// from, like a background file, with the tiles now in the grid. A grid
+// with tile data is saved with the tiles encoded by its encoding, with
+// short rows padded with the empty tile. A grid that is not initialized
+// is saved as it is.
+func (tg *TileGrid) Save(path string) error {
+	if tg.tileIDs == nil {
+		return jsonfile.Write(path, tg)
+	}
+
+	saved := *tg
+
+	if tg.TileData == nil {
+		saved.TileRows = tg.CurrentTileRows()
+
+		return jsonfile.Write(path, &saved)
+	}
+
+	data, err := encodeTileIDs(tg.tileIDs, tg.TileData.Encoding, tg.emptyTile())
+	if err != nil {
+		return fmt.Errorf("failed to encode tile IDs: %w", err)
+	}
+
+	saved.TileRows = nil
+	saved.TileData = data
+
+	return jsonfile.Write(path, &saved)
+}
+
+// setTile puts a tile in a cell, with its tile ID. The tile is nil for the
+// empty tile.
+func (tg *TileGrid) setTile(col, row int, tileID string, tile *Tile) {
+	tg.rows[row].Tiles[col] = tile
+	tg.tileIDs[row][col] = tileID
+
+	tg.invalidate(col, row)
+}
+
+func (tg *TileGrid) inside(cell topdown.Point[int]) bool {
+	return cell.Y >= 0 && cell.Y < tg.nRows && cell.X >= 0 && cell.X < tg.nCols
+}
+
+// collides checks if a tile gets a static collider.
+func collides(tile *Tile) bool {
+	return tile != nil && (tile.Solid || tile.Collider != nil)
+}
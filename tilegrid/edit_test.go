@@ -0,0 +1,130 @@
+package tilegrid_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/movecollide"
+	"github.com/jamestunnell/topdown/resource/restest"
+	"github.com/jamestunnell/topdown/tilegrid"
+)
+
+func TestTileGridWorldToTile(t *testing.T) {
+	tg := makeEditGrid(t)
+
+	cell, inside := tg.WorldToTile(topdown.Pt(25.0, 15.0))
+
+	assert.True(t, inside)
+	assert.Equal(t, topdown.Pt(1, 0), cell)
+	assert.Equal(t, topdown.Pt(20.0, 10.0), tg.TileToWorld(cell))
+
+	cell, inside = tg.WorldToTile(topdown.Pt(5.0, 15.0))
+
+	assert.False(t, inside)
+	assert.Equal(t, topdown.Pt(-1, 0), cell)
+
+	_, inside = tg.WorldToTile(topdown.Pt(45.0, 15.0))
+
+	assert.False(t, inside)
+}
+
+func TestTileGridSetTile(t *testing.T) {
+	tg := makeEditGrid(t)
+	changes := []*tilegrid.TileChange{}
+
+	tg.AddChangeListener("test", tilegrid.TileChangeListenerFunc(func(c *tilegrid.TileChange) {
+		changes = append(changes, c)
+	}))
+
+	require.NoError(t, tg.SetTile(topdown.Pt(1, 1), "W"))
+
+	tile, found := tg.Tile(topdown.Pt(1, 1))
+
+	require.True(t, found)
+	assert.True(t, tile.Solid)
+	assert.Equal(t, []string{"G G W", "G W W"}, tg.CurrentTileRows())
+
+	// the rows the grid was made from are left as they were
+	assert.Equal(t, []string{"G G W", "G G W"}, tg.TileRows)
+	assert.Len(t, tg.StaticColliderShapes(), 2)
+
+	require.Len(t, changes, 1)
+	assert.Equal(t, topdown.Pt(1, 1), changes[0].Cell)
+	assert.Equal(t, "G", changes[0].OldTileID)
+	assert.Equal(t, "W", changes[0].TileID)
+	assert.True(t, changes[0].CollidersChanged)
+
+	// the empty tile removes the tile
+	require.NoError(t, tg.SetTile(topdown.Pt(0, 0), tilegrid.DefaultEmptyTile))
+
+	_, found = tg.Tile(topdown.Pt(0, 0))
+
+	assert.False(t, found)
+
+	tileID, found := tg.TileID(topdown.Pt(0, 0))
+
+	assert.True(t, found)
+	assert.Equal(t, tilegrid.DefaultEmptyTile, tileID)
+	require.Len(t, changes, 2)
+	assert.False(t, changes[1].CollidersChanged)
+
+	tg.RemoveChangeListener("test")
+
+	require.NoError(t, tg.SetTile(topdown.Pt(0, 1), "M"))
+
+	assert.Len(t, changes, 2)
+
+	surface, found := tg.SurfaceAt(topdown.Pt(15.0, 25.0))
+
+	require.True(t, found)
+	assert.Equal(t, "mud", surface.Name)
+
+	assert.Error(t, tg.SetTile(topdown.Pt(3, 0), "G"))
+	assert.Error(t, tg.SetTile(topdown.Pt(0, 0), "X"))
+}
+
+func TestTileGridSave(t *testing.T) {
+	dir := t.TempDir()
+	tg := makeEditGrid(t)
+
+	require.NoError(t, tg.SetTile(topdown.Pt(0, 1), "M"))
+	require.NoError(t, tg.Save(filepath.Join(dir, "edited.background")))
+
+	backgroundType, err := tilegrid.NewBackgroundType()
+
+	require.NoError(t, err)
+
+	mgr := restest.SetupManager(t, dir, backgroundType)
+
+	r, err := mgr.Get("edited.background")
+
+	require.NoError(t, err)
+
+	bg, ok := r.(*tilegrid.Background)
+
+	require.True(t, ok)
+	assert.Equal(t, tg.Origin, bg.Origin)
+	assert.Equal(t, tg.TileLinks, bg.TileLinks)
+	assert.Equal(t, []string{"G G W", "M G W"}, bg.TileRows)
+}
+
+func makeEditGrid(t *testing.T) *tilegrid.TileGrid {
+	tg := &tilegrid.TileGrid{
+		Origin:   topdown.Pt(10.0, 10.0),
+		TileSize: topdown.Size[int]{Width: 10, Height: 10},
+		TileLinks: map[string]*tilegrid.TileLink{
+			"G": {},
+			"W": {Solid: true},
+			"M": {Surface: &movecollide.Surface{Name: "mud"}},
+		},
+		TileRows: []string{"G G W", "G G W"},
+	}
+
+	require.NoError(t, tg.Initialize(nil))
+
+	return tg
+}
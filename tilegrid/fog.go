@@ -0,0 +1,161 @@
+package tilegrid
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/camera"
+	"github.com/jamestunnell/topdown/drawing"
+)
+
+// Visibility is what is known about a cell in the fog of war.
+type Visibility int
+
+const (
+	// Unseen cells have never been seen.
+	Unseen Visibility = iota
+	// Explored cells have been seen before, but aren't visible now.
+	Explored
+	// Visible cells are in the field of view.
+	Visible
+)
+
+// SightBlocker has cells that block sight, like a tile grid.
+type SightBlocker interface {
+	Opaque(cell topdown.Point[int]) bool
+}
+
+// FogOfWar remembers which cells of a grid have been seen, and darkens
+// the cells that aren't visible. It is drawn on the world overlay layer,
+// over everything else in the layer.
+type FogOfWar struct {
+	// DrawOrder is the drawing layer, which is the world overlay layer by default.
+	DrawOrder int
+	// UnseenOpacity and ExploredOpacity range from 0 (transparent) to 1
+	// (opaque), and are 1 and 0.6 by default.
+	UnseenOpacity   float64
+	ExploredOpacity float64
+
+	grid    *TileGrid
+	blocker SightBlocker
+	cells   [][]Visibility
+	visible []topdown.Point[int]
+	mask    *ebiten.Image
+}
+
+const (
+	DefaultUnseenOpacity   = 1
+	DefaultExploredOpacity = 0.6
+)
+
+// NewFogOfWar makes a fog of war over an initialized grid, where all the
+// cells are unseen. The opaque tiles of the grid block sight.
+func NewFogOfWar(grid *TileGrid) *FogOfWar {
+	cells := make([][]Visibility, grid.nRows)
+
+	for row := range cells {
+		cells[row] = make([]Visibility, grid.nCols)
+	}
+
+	return &FogOfWar{
+		DrawOrder:       drawing.LayerWorldOverlay,
+		UnseenOpacity:   DefaultUnseenOpacity,
+		ExploredOpacity: DefaultExploredOpacity,
+		grid:            grid,
+		blocker:         grid,
+		cells:           cells,
+		visible:         []topdown.Point[int]{},
+	}
+}
+
+// SetBlocker sets what blocks sight instead of the grid, like a tile map
+// with walls in several layers.
+func (f *FogOfWar) SetBlocker(b SightBlocker) {
+	f.blocker = b
+}
+
+// Update makes the cells in the field of view from an origin cell within
+// a radius visible. The cells that were visible before, but aren't now,
+// become explored.
+func (f *FogOfWar) Update(origin topdown.Point[int], radius int) {
+	for _, cell := range f.visible {
+		f.cells[cell.Y][cell.X] = Explored
+	}
+
+	f.visible = f.visible[:0]
+
+	for cell := range FieldOfView(origin, radius, f.blocker.Opaque) {
+		if !f.grid.inside(cell) {
+			continue
+		}
+
+		f.cells[cell.Y][cell.X] = Visible
+		f.visible = append(f.visible, cell)
+	}
+}
+
+// Visibility gets the visibility of a cell. Cells outside the grid are unseen.
+func (f *FogOfWar) Visibility(cell topdown.Point[int]) Visibility {
+	if !f.grid.inside(cell) {
+		return Unseen
+	}
+
+	return f.cells[cell.Y][cell.X]
+}
+
+func (f *FogOfWar) DrawLayer() int {
+	return f.DrawOrder
+}
+
+// DrawSortValue is the largest value, so the fog covers everything else
+// in the layer.
+func (f *FogOfWar) DrawSortValue() float64 {
+	return math.MaxFloat64
+}
+
+// Draw darkens the visible part of the grid that isn't in view, with
+// shapes that fit the cells.
+func (f *FogOfWar) Draw(screen *ebiten.Image, cam camera.Camera) {
+	visible := cam.WorldArea()
+
+	if f.grid.worldArea.Intersect(visible).Empty() {
+		return
+	}
+
+	if f.mask == nil {
+		f.mask = ebiten.NewImageFromImage(f.grid.cellMask())
+	}
+
+	firstColumn, lastColumn := f.grid.VisibleColumns(visible)
+	firstRow, lastRow := f.grid.VisibleRows(visible)
+	origin, _ := cam.ConvertWorldToScreen(f.grid.Origin)
+	zoom := cam.ZoomLevel()
+
+	for row := firstRow; row <= lastRow; row++ {
+		for col := firstColumn; col <= lastColumn; col++ {
+			opacity := 0.0
+
+			switch f.cells[row][col] {
+			case Unseen:
+				opacity = f.UnseenOpacity
+			case Explored:
+				opacity = f.ExploredOpacity
+			}
+
+			if opacity <= 0 {
+				continue
+			}
+
+			offset := f.grid.cellOffset(col, row)
+			opts := &ebiten.DrawImageOptions{}
+
+			opts.ColorM.Scale(0, 0, 0, opacity)
+			opts.GeoM.Scale(zoom, zoom)
+			opts.GeoM.Translate(origin.X+offset.X*zoom, origin.Y+offset.Y*zoom)
+
+			screen.DrawImage(f.mask, opts)
+		}
+	}
+}
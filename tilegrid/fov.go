@@ -0,0 +1,121 @@
+package tilegrid
+
+import (
+	"github.com/jamestunnell/topdown"
+)
+
+// FieldOfView finds the cells that can be seen from an origin cell within
+// a radius, with symmetric shadowcasting: if one cell can see another,
+// the other can see it too. Opaque cells block sight, but can be seen
+// themselves. Cells are treated as squares, which suits orthogonal and
+// isometric grids. The visible cells are returned as a set.
+func FieldOfView(origin topdown.Point[int], radius int, opaque func(cell topdown.Point[int]) bool) map[topdown.Point[int]]bool {
+	visible := map[topdown.Point[int]]bool{origin: true}
+
+	if radius <= 0 {
+		return visible
+	}
+
+	for _, q := range quadrants {
+		s := &shadowcast{origin: origin, radius: radius, quadrant: q, opaque: opaque, visible: visible}
+
+		s.scan(1, fraction{-1, 1}, fraction{1, 1})
+	}
+
+	return visible
+}
+
+// Opaque checks if the tile in a cell blocks sight. Cells outside the
+// grid block sight.
+func (tg *TileGrid) Opaque(cell topdown.Point[int]) bool {
+	if !tg.inside(cell) {
+		return true
+	}
+
+	tile := tg.rows[cell.Y].Tiles[cell.X]
+
+	return tile != nil && tile.Opaque
+}
+
+// FieldOfView finds the cells of the grid that can be seen from an origin
+// cell within a radius, where opaque tiles block sight.
+func (tg *TileGrid) FieldOfView(origin topdown.Point[int], radius int) map[topdown.Point[int]]bool {
+	visible := FieldOfView(origin, radius, tg.Opaque)
+
+	for cell := range visible {
+		if !tg.inside(cell) {
+			delete(visible, cell)
+		}
+	}
+
+	return visible
+}
+
+// quadrants turn a depth and column into a cell offset, looking north,
+// east, south and west.
+var quadrants = []func(depth, col int) topdown.Point[int]{
+	func(depth, col int) topdown.Point[int] { return topdown.Pt(col, -depth) },
+	func(depth, col int) topdown.Point[int] { return topdown.Pt(depth, col) },
+	func(depth, col int) topdown.Point[int] { return topdown.Pt(col, depth) },
+	func(depth, col int) topdown.Point[int] { return topdown.Pt(-depth, col) },
+}
+
+// fraction is an exact slope, so the field of view stays symmetric.
+type fraction struct {
+	num, den int
+}
+
+type shadowcast struct {
+	origin   topdown.Point[int]
+	radius   int
+	quadrant func(depth, col int) topdown.Point[int]
+	opaque   func(cell topdown.Point[int]) bool
+	visible  map[topdown.Point[int]]bool
+}
+
+// scan scans a row of a quadrant between two slopes, then the rows beyond
+// it that aren't in shadow.
+func (s *shadowcast) scan(depth int, start, end fraction) {
+	if depth > s.radius {
+		return
+	}
+
+	// round the first column ties up, and the last column ties down
+	minCol := floorDiv(2*depth*start.num+start.den, 2*start.den)
+	maxCol := -floorDiv(-(2*depth*end.num - end.den), 2*end.den)
+	prevWall, prevFloor := false, false
+
+	for col := minCol; col <= maxCol; col++ {
+		cell := s.origin.Add(s.quadrant(depth, col))
+		wall := s.opaque(cell)
+
+		symmetric := col*start.den >= depth*start.num && col*end.den <= depth*end.num
+
+		if (wall || symmetric) && col*col+depth*depth <= s.radius*s.radius {
+			s.visible[cell] = true
+		}
+
+		if prevWall && !wall {
+			start = fraction{2*col - 1, 2 * depth}
+		}
+
+		if prevFloor && wall {
+			s.scan(depth+1, start, fraction{2*col - 1, 2 * depth})
+		}
+
+		prevWall, prevFloor = wall, !wall
+	}
+
+	if prevFloor {
+		s.scan(depth+1, start, end)
+	}
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+
+	return q
+}
@@ -0,0 +1,150 @@
+package tilegrid_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/drawing"
+	"github.com/jamestunnell/topdown/tilegrid"
+)
+
+func TestFieldOfViewRadius(t *testing.T) {
+	open := func(topdown.Point[int]) bool { return false }
+	origin := topdown.Pt(5, 5)
+
+	visible := tilegrid.FieldOfView(origin, 3, open)
+
+	// the cells within a circle of radius 3
+	assert.Len(t, visible, 29)
+	assert.True(t, visible[topdown.Pt(8, 5)])
+	assert.True(t, visible[topdown.Pt(5, 2)])
+	assert.False(t, visible[topdown.Pt(8, 6)])
+
+	assert.Equal(t, map[topdown.Point[int]]bool{origin: true}, tilegrid.FieldOfView(origin, 0, open))
+}
+
+func TestFieldOfViewSymmetric(t *testing.T) {
+	const size = 20
+
+	rng := rand.New(rand.NewSource(3))
+	walls := map[topdown.Point[int]]bool{}
+
+	for row := 0; row < size; row++ {
+		for col := 0; col < size; col++ {
+			walls[topdown.Pt(col, row)] = rng.Float64() < 0.3
+		}
+	}
+
+	// cells outside the area are walls
+	opaque := func(cell topdown.Point[int]) bool {
+		wall, inside := walls[cell]
+
+		return wall || !inside
+	}
+	views := map[topdown.Point[int]]map[topdown.Point[int]]bool{}
+
+	for cell, wall := range walls {
+		if !wall {
+			views[cell] = tilegrid.FieldOfView(cell, 8, opaque)
+		}
+	}
+
+	for a, view := range views {
+		for b := range view {
+			if !opaque(b) {
+				assert.True(t, views[b][a], "%v sees %v, but not the other way", a, b)
+			}
+		}
+	}
+}
+
+func TestTileGridFieldOfView(t *testing.T) {
+	tg := makeSightGrid(t)
+	visible := tg.FieldOfView(topdown.Pt(3, 2), 10)
+
+	// the wall is seen, but not what is behind it
+	assert.True(t, visible[topdown.Pt(3, 1)])
+	assert.False(t, visible[topdown.Pt(3, 0)])
+	assert.True(t, visible[topdown.Pt(2, 0)])
+	assert.True(t, visible[topdown.Pt(4, 0)])
+	assert.True(t, visible[topdown.Pt(6, 4)])
+
+	for cell := range visible {
+		assert.False(t, tg.Opaque(cell) && cell != topdown.Pt(3, 1), "opaque cell %v", cell)
+	}
+
+	assert.True(t, tg.Opaque(topdown.Pt(-1, 0)))
+	assert.False(t, tg.Opaque(topdown.Pt(0, 0)))
+}
+
+func TestFogOfWar(t *testing.T) {
+	tg := makeSightGrid(t)
+	fog := tilegrid.NewFogOfWar(tg)
+
+	assert.Equal(t, drawing.LayerWorldOverlay, fog.DrawLayer())
+	assert.Equal(t, tilegrid.Unseen, fog.Visibility(topdown.Pt(3, 2)))
+
+	fog.Update(topdown.Pt(1, 2), 1)
+
+	assert.Equal(t, tilegrid.Visible, fog.Visibility(topdown.Pt(1, 2)))
+	assert.Equal(t, tilegrid.Visible, fog.Visibility(topdown.Pt(2, 2)))
+	assert.Equal(t, tilegrid.Unseen, fog.Visibility(topdown.Pt(5, 2)))
+
+	fog.Update(topdown.Pt(5, 2), 1)
+
+	assert.Equal(t, tilegrid.Explored, fog.Visibility(topdown.Pt(1, 2)))
+	assert.Equal(t, tilegrid.Visible, fog.Visibility(topdown.Pt(5, 2)))
+	assert.Equal(t, tilegrid.Unseen, fog.Visibility(topdown.Pt(3, 2)))
+	assert.Equal(t, tilegrid.Unseen, fog.Visibility(topdown.Pt(-1, 2)))
+}
+
+func TestFogOfWarBlocker(t *testing.T) {
+	m := &tilegrid.TileMap{
+		TileSize:  topdown.Size[int]{Width: 10, Height: 10},
+		TileLinks: map[string]*tilegrid.TileLink{"G": {}, "X": {Opaque: true}},
+		Layers: []*tilegrid.TileLayer{
+			{Name: "ground", TileRows: []string{"G G G G G"}},
+			{Name: "walls", TileRows: []string{"- - X - -"}},
+		},
+	}
+
+	require.NoError(t, m.Initialize(nil))
+
+	ground, found := m.Layer("ground")
+
+	require.True(t, found)
+
+	fog := tilegrid.NewFogOfWar(ground)
+
+	fog.Update(topdown.Pt(0, 0), 10)
+
+	assert.Equal(t, tilegrid.Visible, fog.Visibility(topdown.Pt(4, 0)))
+
+	fog.SetBlocker(m)
+	fog.Update(topdown.Pt(0, 0), 10)
+
+	assert.Equal(t, tilegrid.Visible, fog.Visibility(topdown.Pt(2, 0)))
+	assert.Equal(t, tilegrid.Explored, fog.Visibility(topdown.Pt(4, 0)))
+}
+
+func makeSightGrid(t *testing.T) *tilegrid.TileGrid {
+	tg := &tilegrid.TileGrid{
+		TileSize:  topdown.Size[int]{Width: 10, Height: 10},
+		TileLinks: map[string]*tilegrid.TileLink{"F": {}, "X": {Solid: true, Opaque: true}},
+		TileRows: []string{
+			"F F F F F F F",
+			"F F F X F F F",
+			"F F F F F F F",
+			"F F F F F F F",
+			"F F F F F F F",
+		},
+	}
+
+	require.NoError(t, tg.Initialize(nil))
+
+	return tg
+}
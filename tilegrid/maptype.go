@@ -0,0 +1,40 @@
+package tilegrid
+
+import (
+	"fmt"
+
+	"github.com/xeipuuv/gojsonschema"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/jsonfile"
+	"github.com/jamestunnell/topdown/movecollide"
+	"github.com/jamestunnell/topdown/resource"
+)
+
+type MapType struct {
+	schema *gojsonschema.Schema
+}
+
+func NewMapType() (resource.Type, error) {
+	schema, err := resource.MakeJSONSchema(
+		TileMapSchemaStr,
+		topdown.SizeSchemaStr,
+		topdown.PointSchemaStr,
+		TileLinkSchemaStr,
+		movecollide.ColliderSchemaStr,
+		movecollide.SurfaceSchemaStr,
+		topdown.VectorSchemaStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make JSON schema: %w", err)
+	}
+
+	return &MapType{schema: schema}, nil
+}
+
+func (mt *MapType) Name() string {
+	return "tilemap"
+}
+
+func (mt *MapType) Load(path string) (resource.Resource, error) {
+	return jsonfile.ReadAndValidate[*TileMap](path, mt.schema)
+}
@@ -0,0 +1,62 @@
+package tilegrid
+
+import "github.com/jamestunnell/topdown"
+
+// MergeCells merges the cells of a grid that are set into as few
+// rectangles as possible, by greedily growing each rectangle first across
+// then down. Rectangles are in cell coordinates, with exclusive max.
+func MergeCells(cells [][]bool) []topdown.Rectangle[int] {
+	rects := []topdown.Rectangle[int]{}
+	used := make([][]bool, len(cells))
+
+	for row := range cells {
+		used[row] = make([]bool, len(cells[row]))
+	}
+
+	free := func(row, col int) bool {
+		return row < len(cells) && col < len(cells[row]) && cells[row][col] && !used[row][col]
+	}
+
+	for row := range cells {
+		for col := range cells[row] {
+			if !free(row, col) {
+				continue
+			}
+
+			endCol := col + 1
+			for free(row, endCol) {
+				endCol++
+			}
+
+			endRow := row + 1
+
+			for {
+				fullRow := true
+
+				for c := col; c < endCol; c++ {
+					if !free(endRow, c) {
+						fullRow = false
+
+						break
+					}
+				}
+
+				if !fullRow {
+					break
+				}
+
+				endRow++
+			}
+
+			for r := row; r < endRow; r++ {
+				for c := col; c < endCol; c++ {
+					used[r][c] = true
+				}
+			}
+
+			rects = append(rects, topdown.Rect(col, row, endCol, endRow))
+		}
+	}
+
+	return rects
+}
@@ -0,0 +1,31 @@
+package tilegrid_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/tilegrid"
+)
+
+func TestMergeCells(t *testing.T) {
+	x, o := true, false
+	cells := [][]bool{
+		{x, x, x, o},
+		{x, x, x, o},
+		{o, x, o, o},
+		{o, x, o, x},
+	}
+
+	rects := tilegrid.MergeCells(cells)
+
+	assert.Equal(t, []topdown.Rectangle[int]{
+		topdown.Rect(0, 0, 3, 2),
+		topdown.Rect(1, 2, 2, 4),
+		topdown.Rect(3, 3, 4, 4),
+	}, rects)
+
+	assert.Empty(t, tilegrid.MergeCells([][]bool{{o, o}, {o, o}}))
+	assert.Empty(t, tilegrid.MergeCells([][]bool{}))
+}
@@ -0,0 +1,34 @@
+package tilegrid
+
+import (
+	"github.com/zergon321/cirno"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/movecollide"
+)
+
+// Properties are custom properties, like those set in a map editor.
+type Properties map[string]any
+
+// SpawnPoint is a place in the map to spawn an entity.
+type SpawnPoint struct {
+	Name       string         `json:"name,omitempty"`
+	Type       string         `json:"type,omitempty"`
+	Position   topdown.Vector `json:"position"`
+	Properties Properties     `json:"properties,omitempty"`
+}
+
+// Area is a shape in the map, like a trigger or an extra collider.
+type Area struct {
+	Name     string         `json:"name,omitempty"`
+	Type     string         `json:"type,omitempty"`
+	Position topdown.Vector `json:"position"`
+	// Collider is the area shape, placed relative to the position.
+	Collider   *movecollide.ColliderSpec `json:"collider"`
+	Properties Properties                `json:"properties,omitempty"`
+}
+
+// Shape makes the area shape.
+func (a *Area) Shape() (cirno.Shape, error) {
+	return movecollide.NewCollider(a.Collider, a.Position)
+}
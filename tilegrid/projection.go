@@ -0,0 +1,272 @@
+package tilegrid
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/jamestunnell/topdown"
+)
+
+// Projections, which place the tiles of a grid. Tiles are drawn into a
+// bounding box of the tile size, and staggered rows or columns are the
+// odd ones.
+const (
+	// ProjectionOrthogonal places tiles in rows and columns, and is the default.
+	ProjectionOrthogonal = "orthogonal"
+	// ProjectionIsometric places diamond tiles so the grid is a diamond,
+	// with the first tile at the top. Columns go down to the right, and
+	// rows go down to the left.
+	ProjectionIsometric = "isometric"
+	// ProjectionStaggered places diamond tiles in rows half a tile tall,
+	// with the odd rows shifted right by half a tile, so the grid is
+	// roughly a rectangle.
+	ProjectionStaggered = "staggered"
+	// ProjectionHexPointy places pointy-topped hex tiles in rows three
+	// quarters of a tile tall, with the odd rows shifted right by half a tile.
+	ProjectionHexPointy = "hexPointy"
+	// ProjectionHexFlat places flat-topped hex tiles in columns three
+	// quarters of a tile wide, with the odd columns shifted down by half a tile.
+	ProjectionHexFlat = "hexFlat"
+)
+
+func validateProjection(projection string) error {
+	switch projection {
+	case "", ProjectionOrthogonal, ProjectionIsometric, ProjectionStaggered, ProjectionHexPointy, ProjectionHexFlat:
+		return nil
+	}
+
+	return fmt.Errorf("unknown projection '%s'", projection)
+}
+
+func (tg *TileGrid) orthogonal() bool {
+	return tg.Projection == "" || tg.Projection == ProjectionOrthogonal
+}
+
+// TileCenter gets the world position of the center of a cell.
+func (tg *TileGrid) TileCenter(cell topdown.Point[int]) topdown.Point[float64] {
+	pos := tg.TileToWorld(cell)
+
+	return topdown.Pt(pos.X+float64(tg.TileSize.Width)/2, pos.Y+float64(tg.TileSize.Height)/2)
+}
+
+// cellOffset gets the top-left corner of the bounding box of a cell,
+// relative to the grid origin.
+func (tg *TileGrid) cellOffset(col, row int) topdown.Point[float64] {
+	w, h := float64(tg.TileSize.Width), float64(tg.TileSize.Height)
+	c, r := float64(col), float64(row)
+
+	switch tg.Projection {
+	case ProjectionIsometric:
+		return topdown.Pt((c-r+float64(tg.nRows-1))*w/2, (c+r)*h/2)
+	case ProjectionStaggered:
+		return topdown.Pt(c*w+odd(row)*w/2, r*h/2)
+	case ProjectionHexPointy:
+		return topdown.Pt(c*w+odd(row)*w/2, r*h*3/4)
+	case ProjectionHexFlat:
+		return topdown.Pt(c*w*3/4, r*h+odd(col)*h/2)
+	}
+
+	return topdown.Pt(c*w, r*h)
+}
+
+// cellAt gets the cell at a position relative to the grid origin, which
+// can be outside the grid.
+func (tg *TileGrid) cellAt(pos topdown.Point[float64]) topdown.Point[int] {
+	w, h := float64(tg.TileSize.Width), float64(tg.TileSize.Height)
+
+	// the position relative to the center of the first cell, in tiles
+	x := (pos.X - w/2) / w
+	y := (pos.Y - h/2) / h
+
+	switch tg.Projection {
+	case ProjectionIsometric:
+		// relative to the top corner of the first cell
+		x = (pos.X - float64(tg.nRows)*w/2) / w
+		y = pos.Y / h
+
+		return topdown.Pt(int(math.Floor(y+x)), int(math.Floor(y-x)))
+	case ProjectionStaggered:
+		row := int(math.Round(y * 2))
+
+		return tg.nearestCell(pos, int(math.Round(x-odd(row)/2)), row, func(dx, dy float64) float64 {
+			return math.Abs(dx) + math.Abs(dy)
+		})
+	case ProjectionHexPointy:
+		row := int(math.Round(y * 4 / 3))
+
+		return tg.nearestCell(pos, int(math.Round(x-odd(row)/2)), row, func(dx, dy float64) float64 {
+			return dx*dx + dy*dy*4/3
+		})
+	case ProjectionHexFlat:
+		col := int(math.Round(x * 4 / 3))
+
+		return tg.nearestCell(pos, col, int(math.Round(y-odd(col)/2)), func(dx, dy float64) float64 {
+			return dx*dx*4/3 + dy*dy
+		})
+	}
+
+	return topdown.Pt(int(math.Floor(pos.X/w)), int(math.Floor(pos.Y/h)))
+}
+
+// nearestCell finds the cell around an estimated cell with the center
+// nearest to a position relative to the grid origin. The distance func
+// gets the offset from a center in tiles.
+func (tg *TileGrid) nearestCell(pos topdown.Point[float64], col, row int, distance func(dx, dy float64) float64) topdown.Point[int] {
+	w, h := float64(tg.TileSize.Width), float64(tg.TileSize.Height)
+	nearest := topdown.Pt(col, row)
+	nearestDist := math.Inf(1)
+
+	for r := row - 1; r <= row+1; r++ {
+		for c := col - 1; c <= col+1; c++ {
+			offset := tg.cellOffset(c, r)
+			dist := distance((pos.X-offset.X-w/2)/w, (pos.Y-offset.Y-h/2)/h)
+
+			if dist < nearestDist {
+				nearest = topdown.Pt(c, r)
+				nearestDist = dist
+			}
+		}
+	}
+
+	return nearest
+}
+
+// areaSize gets the size of the bounding box of all the cells.
+func (tg *TileGrid) areaSize() topdown.Size[float64] {
+	w, h := float64(tg.TileSize.Width), float64(tg.TileSize.Height)
+	cols, rows := float64(tg.nCols), float64(tg.nRows)
+
+	switch tg.Projection {
+	case ProjectionIsometric:
+		return topdown.Size[float64]{Width: (cols + rows) * w / 2, Height: (cols + rows) * h / 2}
+	case ProjectionStaggered:
+		return topdown.Size[float64]{Width: cols*w + stagger(tg.nRows)*w/2, Height: (rows + 1) * h / 2}
+	case ProjectionHexPointy:
+		return topdown.Size[float64]{Width: cols*w + stagger(tg.nRows)*w/2, Height: (rows-1)*h*3/4 + h}
+	case ProjectionHexFlat:
+		return topdown.Size[float64]{Width: (cols-1)*w*3/4 + w, Height: rows*h + stagger(tg.nCols)*h/2}
+	}
+
+	return topdown.Size[float64]{Width: cols * w, Height: rows * h}
+}
+
+// solidRadius gets the radius of the circle colliders for solid tiles in
+// projections other than orthogonal, which fits in a tile.
+func (tg *TileGrid) solidRadius() float64 {
+	w, h := float64(tg.TileSize.Width), float64(tg.TileSize.Height)
+
+	switch tg.Projection {
+	case ProjectionIsometric, ProjectionStaggered:
+		return w * h / (2 * math.Hypot(w, h))
+	}
+
+	return math.Min(w, h) / 2
+}
+
+// visibleCells gets the range of cells that can be seen in a world area.
+// Other than in orthogonal grids, the range is padded to cover the tiles
+// that are partly visible.
+func (tg *TileGrid) visibleCells(visible topdown.Rectangle[float64]) (int, int, int, int) {
+	if tg.orthogonal() {
+		firstCol := int((visible.Min.X - tg.Origin.X) / float64(tg.TileSize.Width))
+		lastCol := int(math.Ceil((visible.Max.X - tg.Origin.X) / float64(tg.TileSize.Width)))
+		firstRow := int((visible.Min.Y - tg.Origin.Y) / float64(tg.TileSize.Height))
+		lastRow := int(math.Ceil((visible.Max.Y - tg.Origin.Y) / float64(tg.TileSize.Height)))
+
+		return firstCol, lastCol, firstRow, lastRow
+	}
+
+	corners := []topdown.Point[float64]{
+		visible.Min,
+		topdown.Pt(visible.Max.X, visible.Min.Y),
+		topdown.Pt(visible.Min.X, visible.Max.Y),
+		visible.Max,
+	}
+	firstCol, firstRow := math.MaxInt, math.MaxInt
+	lastCol, lastRow := math.MinInt, math.MinInt
+
+	for _, corner := range corners {
+		cell := tg.cellAt(corner.Sub(tg.Origin))
+
+		firstCol, lastCol = minInt(firstCol, cell.X), maxInt(lastCol, cell.X)
+		firstRow, lastRow = minInt(firstRow, cell.Y), maxInt(lastRow, cell.Y)
+	}
+
+	return firstCol - 1, lastCol + 1, firstRow - 1, lastRow + 1
+}
+
+// cellMask makes an image of the shape of a cell, which is white inside
+// the cell and clear outside, so the shapes of neighboring cells fit
+// together.
+func (tg *TileGrid) cellMask() *image.RGBA {
+	mask := image.NewRGBA(image.Rect(0, 0, tg.TileSize.Width, tg.TileSize.Height))
+	cell := topdown.Pt(2, 2)
+	offset := tg.cellOffset(cell.X, cell.Y)
+
+	for y := 0; y < tg.TileSize.Height; y++ {
+		for x := 0; x < tg.TileSize.Width; x++ {
+			pos := topdown.Pt(offset.X+float64(x)+0.5, offset.Y+float64(y)+0.5)
+
+			if tg.orthogonal() || tg.cellAt(pos) == cell {
+				mask.Set(x, y, color.White)
+			}
+		}
+	}
+
+	return mask
+}
+
+// eachCellInDrawOrder calls the func for the cells in a range, so the
+// tiles in front are drawn after the tiles behind them.
+func (tg *TileGrid) eachCellInDrawOrder(firstCol, lastCol, firstRow, lastRow int, f func(col, row int)) {
+	for row := firstRow; row <= lastRow; row++ {
+		if tg.Projection != ProjectionHexFlat {
+			for col := firstCol; col <= lastCol; col++ {
+				f(col, row)
+			}
+
+			continue
+		}
+
+		// the odd columns are lower, so they are drawn after the even ones
+		for _, parity := range []int{0, 1} {
+			for col := firstCol; col <= lastCol; col++ {
+				if col&1 == parity {
+					f(col, row)
+				}
+			}
+		}
+	}
+}
+
+// odd is 1 for odd numbers, and 0 for even numbers.
+func odd(n int) float64 {
+	return float64(n & 1)
+}
+
+// stagger is 1 if there is more than one row or column to stagger.
+func stagger(n int) float64 {
+	if n > 1 {
+		return 1
+	}
+
+	return 0
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,168 @@
+package tilegrid_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/movecollide"
+	"github.com/jamestunnell/topdown/tilegrid"
+)
+
+func TestProjectionWorldToTile(t *testing.T) {
+	projections := []string{
+		tilegrid.ProjectionOrthogonal,
+		tilegrid.ProjectionIsometric,
+		tilegrid.ProjectionStaggered,
+		tilegrid.ProjectionHexPointy,
+		tilegrid.ProjectionHexFlat,
+	}
+
+	for _, projection := range projections {
+		t.Run(projection, func(t *testing.T) {
+			tg := makeProjectedGrid(t, projection, "G G G G G", "G G G G G", "G G G G G", "G G G G G")
+
+			for row := 0; row < 4; row++ {
+				for col := 0; col < 5; col++ {
+					cell := topdown.Pt(col, row)
+					center := tg.TileCenter(cell)
+
+					for _, pos := range []topdown.Point[float64]{center, topdown.Pt(center.X+4, center.Y+1)} {
+						found, inside := tg.WorldToTile(pos)
+
+						assert.True(t, inside)
+						assert.Equal(t, cell, found, "position %v", pos)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestProjectionTileToWorld(t *testing.T) {
+	testCases := map[string][]topdown.Point[float64]{
+		// cells 0,0, 1,0 and 0,1 of a grid with 2 rows
+		tilegrid.ProjectionIsometric: {topdown.Pt(20.0, 10.0), topdown.Pt(30.0, 15.0), topdown.Pt(10.0, 15.0)},
+		tilegrid.ProjectionStaggered: {topdown.Pt(10.0, 10.0), topdown.Pt(30.0, 10.0), topdown.Pt(20.0, 15.0)},
+		tilegrid.ProjectionHexPointy: {topdown.Pt(10.0, 10.0), topdown.Pt(30.0, 10.0), topdown.Pt(20.0, 17.5)},
+		tilegrid.ProjectionHexFlat:   {topdown.Pt(10.0, 10.0), topdown.Pt(25.0, 15.0), topdown.Pt(10.0, 20.0)},
+	}
+
+	for projection, expected := range testCases {
+		t.Run(projection, func(t *testing.T) {
+			tg := makeProjectedGrid(t, projection, "G G", "G G")
+
+			assert.Equal(t, expected[0], tg.TileToWorld(topdown.Pt(0, 0)))
+			assert.Equal(t, expected[1], tg.TileToWorld(topdown.Pt(1, 0)))
+			assert.Equal(t, expected[2], tg.TileToWorld(topdown.Pt(0, 1)))
+		})
+	}
+}
+
+func TestProjectionOutside(t *testing.T) {
+	// the top-left corner of the bounding box is outside the diamond
+	for _, projection := range []string{tilegrid.ProjectionIsometric, tilegrid.ProjectionStaggered} {
+		tg := makeProjectedGrid(t, projection, "G G", "G G")
+		corner := tg.TileToWorld(topdown.Pt(0, 0))
+
+		_, inside := tg.WorldToTile(topdown.Pt(corner.X+1, corner.Y+1))
+
+		assert.False(t, inside, projection)
+	}
+}
+
+func TestProjectionColliders(t *testing.T) {
+	tg := makeProjectedGrid(t, tilegrid.ProjectionIsometric, "W W", "G G")
+
+	// solid tiles aren't merged
+	shapes := tg.StaticColliderShapes()
+
+	require.Len(t, shapes, 2)
+
+	min, max := movecollide.ShapeBounds(shapes[0])
+	center := tg.TileCenter(topdown.Pt(0, 0))
+
+	assert.InDelta(t, center.X, (min.X+max.X)/2, 1e-9)
+	assert.InDelta(t, center.Y, (min.Y+max.Y)/2, 1e-9)
+	// the circle fits in the diamond
+	assert.Less(t, max.Y-min.Y, 10.0)
+}
+
+func TestProjectionVisibleCells(t *testing.T) {
+	tg := makeProjectedGrid(t, tilegrid.ProjectionIsometric, "G G G G", "G G G G", "G G G G", "G G G G")
+	center := tg.TileCenter(topdown.Pt(3, 1))
+	visible := topdown.Rectangle[float64]{
+		Min: topdown.Pt(center.X-1, center.Y-1),
+		Max: topdown.Pt(center.X+1, center.Y+1),
+	}
+
+	firstCol, lastCol := tg.VisibleColumns(visible)
+	firstRow, lastRow := tg.VisibleRows(visible)
+
+	assert.True(t, firstCol <= 3 && lastCol >= 3)
+	assert.True(t, firstRow <= 1 && lastRow >= 1)
+	// cells far from the visible area are left out
+	assert.Greater(t, firstCol, 0)
+}
+
+func TestProjectionInvalid(t *testing.T) {
+	tg := &tilegrid.TileGrid{
+		TileSize:   topdown.Size[int]{Width: 20, Height: 10},
+		TileLinks:  map[string]*tilegrid.TileLink{"G": {}},
+		TileRows:   []string{"G"},
+		Projection: "cylindrical",
+	}
+
+	assert.Error(t, tg.Initialize(nil))
+
+	dir := t.TempDir()
+	d := `{"origin": {"x": 0, "y": 0}, "tileSize": {"w": 8, "h": 8}, "tileLinks": {"G": {}}, "tileRows": ["G"], "projection": "cylindrical"}`
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bad.background"), []byte(d), 0o600))
+
+	backgroundType, err := tilegrid.NewBackgroundType()
+
+	require.NoError(t, err)
+
+	_, err = backgroundType.Load(filepath.Join(dir, "bad.background"))
+
+	assert.Error(t, err)
+}
+
+func TestTileMapProjection(t *testing.T) {
+	m := &tilegrid.TileMap{
+		TileSize:   topdown.Size[int]{Width: 20, Height: 10},
+		TileLinks:  map[string]*tilegrid.TileLink{"G": {}},
+		Projection: tilegrid.ProjectionHexPointy,
+		Layers:     []*tilegrid.TileLayer{{Name: "ground", TileRows: []string{"G G", "G G"}}},
+	}
+
+	require.NoError(t, m.Initialize(nil))
+
+	grid, found := m.Layer("ground")
+
+	require.True(t, found)
+	assert.Equal(t, tilegrid.ProjectionHexPointy, grid.Projection)
+	assert.Equal(t, topdown.Pt(10.0, 7.5), grid.TileToWorld(topdown.Pt(0, 1)))
+}
+
+func makeProjectedGrid(t *testing.T, projection string, tileRows ...string) *tilegrid.TileGrid {
+	tg := &tilegrid.TileGrid{
+		Origin:   topdown.Pt(10.0, 10.0),
+		TileSize: topdown.Size[int]{Width: 20, Height: 10},
+		TileLinks: map[string]*tilegrid.TileLink{
+			"G": {},
+			"W": {Solid: true},
+		},
+		TileRows:   tileRows,
+		Projection: projection,
+	}
+
+	require.NoError(t, tg.Initialize(nil))
+
+	return tg
+}
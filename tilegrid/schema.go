@@ -5,20 +5,280 @@ const TileGridSchemaStr = `{
   "$schema": "https://json-schema.org/draft/2020-12/schema",
   "title": "Image set",
   "type": "object",
-  "required": ["origin", "tileSize", "tileLinks", "tileRows"],
+  "required": ["origin", "tileSize", "tileLinks"],
+  "oneOf": [
+	{"required": ["tileRows"]},
+	{"required": ["tileData"]}
+  ],
   "properties": {
 	"origin": { "$ref": "https://github.com/jamestunnell/topdown/vector.json" },
 	"tileSize": { "$ref": "https://github.com/jamestunnell/topdown/size.json" },
 	"tileLinks": {
 		"type": "object",
 		"patternProperties" :{
-			".*": {"type": "string", "minLength": 1}
+			".*": { "$ref": "https://github.com/jamestunnell/topdown/tilelink.json" }
 		}
 	},
 	"tileRows": {
 		"type": "array",
 		"items": {"type": "string", "minLength": 1},
 		"minLength": 1
+	},
+	"drawLayer": {"type": "integer"},
+	"opacity": {"type": "number", "minimum": 0, "maximum": 1},
+	"hidden": {"type": "boolean"},
+	"tileData": {"$ref": "#/$defs/tileData"},
+	"emptyTile": {"type": "string", "minLength": 1},
+	"uncached": {"type": "boolean"},
+	"projection": {"enum": ["orthogonal", "isometric", "staggered", "hexPointy", "hexFlat"]}
+  },
+  "$defs": {
+	"tileData": {
+		"title": "Tile data",
+		"description": "Cells as indices into a palette of tile IDs, in row order.",
+		"type": "object",
+		"required": ["encoding", "columns", "palette"],
+		"properties": {
+			"encoding": {"enum": ["indices", "rle", "base64zlib"]},
+			"columns": {"type": "integer", "minimum": 1},
+			"palette": {
+				"type": "array",
+				"items": {"type": "string", "minLength": 1},
+				"minItems": 1
+			},
+			"indices": {
+				"type": "array",
+				"items": {"type": "integer", "minimum": 0}
+			},
+			"runs": {
+				"type": "array",
+				"items": {"type": "integer", "minimum": 0}
+			},
+			"data": {"type": "string", "minLength": 1}
+		},
+		"oneOf": [
+			{"properties": {"encoding": {"const": "indices"}}, "required": ["indices"]},
+			{"properties": {"encoding": {"const": "rle"}}, "required": ["runs"]},
+			{"properties": {"encoding": {"const": "base64zlib"}}, "required": ["data"]}
+		]
+	}
+  }
+}`
+
+const TileLinkSchemaStr = `{
+  "$id": "https://github.com/jamestunnell/topdown/tilelink.json",
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "Tile link",
+  "description": "Sprite link, or tile properties with an optional sprite link.",
+  "oneOf": [
+	{"type": "string", "minLength": 1},
+	{
+		"type": "object",
+		"properties": {
+			"sprite": {"type": "string", "minLength": 1},
+			"animation": {"$ref": "#/$defs/animation"},
+			"solid": {"type": "boolean"},
+			"collider": { "$ref": "https://github.com/jamestunnell/topdown/collider.json" },
+			"surface": { "$ref": "https://github.com/jamestunnell/topdown/surface.json" },
+			"opaque": {"type": "boolean"},
+			"flipX": {"type": "boolean"},
+			"flipY": {"type": "boolean"},
+			"flipDiagonal": {"type": "boolean"},
+			"properties": {"type": "object"}
+		}
 	}
+  ],
+  "$defs": {
+	"animation": {
+		"title": "Tile animation",
+		"description": "Frames with a tag in a sprite sheet, or a list of frames.",
+		"type": "object",
+		"properties": {
+			"spriteSheet": {"type": "string", "minLength": 1},
+			"tag": {"type": "string", "minLength": 1},
+			"frameDuration": {"type": "string", "minLength": 1},
+			"frames": {
+				"type": "array",
+				"minItems": 1,
+				"items": {
+					"type": "object",
+					"required": ["sprite", "duration"],
+					"properties": {
+						"sprite": {"type": "string", "minLength": 1},
+						"duration": {"type": "string", "minLength": 1}
+					}
+				}
+			}
+		},
+		"oneOf": [
+			{"required": ["spriteSheet", "tag", "frameDuration"]},
+			{"required": ["frames"]}
+		]
+	}
+  }
+}`
+
+const TileMapSchemaStr = `{
+  "$id": "https://github.com/jamestunnell/topdown/tilemap.json",
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "Tile map",
+  "description": "Map made of named tile layers.",
+  "type": "object",
+  "required": ["origin", "tileSize", "tileLinks", "layers"],
+  "properties": {
+	"origin": { "$ref": "https://github.com/jamestunnell/topdown/vector.json" },
+	"tileSize": { "$ref": "https://github.com/jamestunnell/topdown/size.json" },
+	"tileLinks": {
+		"type": "object",
+		"patternProperties" :{
+			".*": { "$ref": "https://github.com/jamestunnell/topdown/tilelink.json" }
+		}
+	},
+	"emptyTile": {"type": "string", "minLength": 1},
+	"projection": {"enum": ["orthogonal", "isometric", "staggered", "hexPointy", "hexFlat"]},
+	"layers": {
+		"type": "array",
+		"minItems": 1,
+		"items": {
+			"type": "object",
+			"required": ["name"],
+			"anyOf": [
+				{"required": ["tileRows"]},
+				{"required": ["autoTile", "terrainRows"]}
+			],
+			"properties": {
+				"name": {"type": "string", "minLength": 1},
+				"drawLayer": {"type": "integer"},
+				"opacity": {"type": "number", "minimum": 0, "maximum": 1},
+				"hidden": {"type": "boolean"},
+				"tileRows": {
+					"type": "array",
+					"items": {"type": "string"}
+				},
+				"autoTile": {"type": "string", "minLength": 1},
+				"terrainRows": {
+					"type": "array",
+					"items": {"type": "string"}
+				},
+				"properties": {"type": "object"}
+			}
+		}
+	},
+	"spawns": {
+		"type": "array",
+		"items": {
+			"type": "object",
+			"required": ["position"],
+			"properties": {
+				"name": {"type": "string"},
+				"type": {"type": "string"},
+				"position": { "$ref": "https://github.com/jamestunnell/topdown/vector.json" },
+				"properties": {"type": "object"}
+			}
+		}
+	},
+	"triggers": {
+		"type": "array",
+		"items": {"$ref": "#/$defs/area"}
+	},
+	"colliders": {
+		"type": "array",
+		"items": {"$ref": "#/$defs/area"}
+	},
+	"properties": {"type": "object"}
+  },
+  "$defs": {
+	"area": {
+		"title": "Area",
+		"type": "object",
+		"required": ["position", "collider"],
+		"properties": {
+			"name": {"type": "string"},
+			"type": {"type": "string"},
+			"position": { "$ref": "https://github.com/jamestunnell/topdown/vector.json" },
+			"collider": { "$ref": "https://github.com/jamestunnell/topdown/collider.json" },
+			"properties": {"type": "object"}
+		}
+	}
+  }
+}`
+
+const AutoTileSchemaStr = `{
+  "$id": "https://github.com/jamestunnell/topdown/autotile.json",
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "Autotile rules",
+  "description": "Rules for picking the tiles for terrain.",
+  "type": "object",
+  "required": ["terrains"],
+  "properties": {
+	"terrains": {
+		"type": "array",
+		"minItems": 1,
+		"items": {
+			"type": "object",
+			"required": ["name"],
+			"properties": {
+				"name": {"type": "string", "minLength": 1},
+				"kind": {"enum": ["blob47", "wangCorner", "wangEdge"]},
+				"tile": {"type": "string", "minLength": 1},
+				"tiles": {
+					"type": "object",
+					"patternProperties": {
+						"^[0-9]+$": {"type": "string", "minLength": 1}
+					},
+					"additionalProperties": false
+				},
+				"matches": {
+					"type": "array",
+					"items": {"type": "string", "minLength": 1}
+				}
+			},
+			"oneOf": [
+				{"required": ["tile"], "not": {"required": ["kind"]}},
+				{"required": ["kind", "tiles"]}
+			]
+		}
+	}
+  }
+}`
+
+const ChunkedMapSchemaStr = `{
+  "$id": "https://github.com/jamestunnell/topdown/chunkedmap.json",
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "Chunked map",
+  "description": "Map split into chunks, which are streamed in from chunk files.",
+  "type": "object",
+  "required": ["origin", "tileSize", "chunkSize", "tileLinks", "chunkDir"],
+  "properties": {
+	"origin": { "$ref": "https://github.com/jamestunnell/topdown/vector.json" },
+	"tileSize": { "$ref": "https://github.com/jamestunnell/topdown/size.json" },
+	"chunkSize": { "$ref": "https://github.com/jamestunnell/topdown/size.json" },
+	"tileLinks": {
+		"type": "object",
+		"patternProperties" :{
+			".*": { "$ref": "https://github.com/jamestunnell/topdown/tilelink.json" }
+		}
+	},
+	"emptyTile": {"type": "string", "minLength": 1},
+	"chunkDir": {"type": "string", "minLength": 1},
+	"loadRadius": {"type": "integer", "minimum": 0},
+	"keepAliveRadius": {"type": "integer", "minimum": 0},
+	"retryDelay": {"type": "string", "minLength": 1}
+  }
+}`
+
+const ChunkSchemaStr = `{
+  "$id": "https://github.com/jamestunnell/topdown/chunk.json",
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "Map chunk",
+  "description": "Layers and objects of a chunk of a chunked map, like in a tile map.",
+  "type": "object",
+  "required": ["layers"],
+  "properties": {
+	"layers": { "$ref": "https://github.com/jamestunnell/topdown/tilemap.json#/properties/layers" },
+	"spawns": { "$ref": "https://github.com/jamestunnell/topdown/tilemap.json#/properties/spawns" },
+	"triggers": { "$ref": "https://github.com/jamestunnell/topdown/tilemap.json#/properties/triggers" },
+	"colliders": { "$ref": "https://github.com/jamestunnell/topdown/tilemap.json#/properties/colliders" },
+	"properties": {"type": "object"}
   }
 }`
@@ -0,0 +1,89 @@
+package tilegrid
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jamestunnell/topdown/animation"
+	"github.com/jamestunnell/topdown/resource"
+	"github.com/jamestunnell/topdown/sprite"
+)
+
+// TileAnimation animates a tile, using either the frames with a tag in a
+// sprite sheet or a list of frames. The frames should be the same size.
+type TileAnimation struct {
+	// SpriteSheet and Tag pick the tagged frames, which are ordered from
+	// top to bottom, then left to right. Each is shown for the frame duration.
+	SpriteSheet   string `json:"spriteSheet,omitempty"`
+	Tag           string `json:"tag,omitempty"`
+	FrameDuration string `json:"frameDuration,omitempty"`
+	// Frames are used instead of tagged frames, when given.
+	Frames []*TileFrame `json:"frames,omitempty"`
+}
+
+// TileFrame is a sprite shown for a duration, like "100ms".
+type TileFrame struct {
+	Sprite   string `json:"sprite"`
+	Duration string `json:"duration"`
+}
+
+// MakeFrames makes the animation frames.
+func (a *TileAnimation) MakeFrames(mgr resource.Manager) ([]animation.Frame, error) {
+	frames := []animation.Frame{}
+
+	if len(a.Frames) > 0 {
+		for i, f := range a.Frames {
+			dur, err := parseFrameDuration(f.Duration)
+			if err != nil {
+				return nil, fmt.Errorf("invalid duration for frame %d: %w", i, err)
+			}
+
+			img, err := findSpriteImage(mgr, f.Sprite)
+			if err != nil {
+				return nil, fmt.Errorf("failed to find image for frame %d: %w", i, err)
+			}
+
+			frames = append(frames, animation.Frame{Image: img, Duration: dur})
+		}
+
+		return frames, nil
+	}
+
+	dur, err := parseFrameDuration(a.FrameDuration)
+	if err != nil {
+		return nil, fmt.Errorf("invalid frame duration: %w", err)
+	}
+
+	sheet, err := resource.GetAs[*sprite.Sheet](mgr, a.SpriteSheet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sprite sheet '%s': %w", a.SpriteSheet, err)
+	}
+
+	images, err := animation.FrameImages(a.Tag, sheet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get frames with tag '%s': %w", a.Tag, err)
+	}
+
+	if len(images) == 0 {
+		return nil, fmt.Errorf("no frames have tag '%s'", a.Tag)
+	}
+
+	for _, img := range images {
+		frames = append(frames, animation.Frame{Image: img, Duration: dur})
+	}
+
+	return frames, nil
+}
+
+func parseFrameDuration(s string) (time.Duration, error) {
+	dur, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+
+	if dur <= 0 {
+		return 0, fmt.Errorf("duration %s is not positive", s)
+	}
+
+	return dur, nil
+}
@@ -0,0 +1,195 @@
+package tilegrid
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/jamestunnell/topdown"
+)
+
+const (
+	// CacheChunkSize is the number of tiles across the cached images that
+	// the static tiles of a grid are drawn into.
+	CacheChunkSize = 16
+	// CacheKeepFrames is the least number of frames a cached image is kept
+	// after it was last drawn. Unused images are dropped every so many
+	// frames, so panning across a large grid doesn't keep the images of
+	// every chunk passed on the way.
+	CacheKeepFrames = 120
+)
+
+// cachedChunk is an image of the static tiles in a square of cells.
+// Animated tiles are left out, and drawn every frame instead.
+type cachedChunk struct {
+	image     *ebiten.Image
+	animated  []topdown.Point[int]
+	dirty     bool
+	lastFrame int
+}
+
+// drawCached draws the cached chunks with visible tiles, followed by
+// their animated tiles. Chunks are drawn the first time they are seen,
+// and again after they change. Chunks that haven't been seen for a while
+// are dropped.
+func (tg *TileGrid) drawCached(
+	screen *ebiten.Image,
+	origin topdown.Point[float64],
+	zoom, opacity float64,
+	firstColumn, lastColumn, firstRow, lastRow int,
+) {
+	width := float64(tg.TileSize.Width) * zoom
+	height := float64(tg.TileSize.Height) * zoom
+
+	tg.frame++
+
+	for cy := firstRow / CacheChunkSize; cy <= lastRow/CacheChunkSize; cy++ {
+		for cx := firstColumn / CacheChunkSize; cx <= lastColumn/CacheChunkSize; cx++ {
+			chunk := tg.cachedChunk(cx, cy)
+
+			if chunk.image != nil {
+				opts := &ebiten.DrawImageOptions{}
+
+				if opacity < 1 {
+					opts.ColorM.Scale(1, 1, 1, opacity)
+				}
+
+				if zoom != 1 {
+					opts.GeoM.Scale(zoom, zoom)
+				}
+
+				opts.GeoM.Translate(
+					origin.X+float64(cx*CacheChunkSize)*width,
+					origin.Y+float64(cy*CacheChunkSize)*height)
+
+				screen.DrawImage(chunk.image, opts)
+			}
+
+			for _, cell := range chunk.animated {
+				if cell.X < firstColumn || cell.X > lastColumn || cell.Y < firstRow || cell.Y > lastRow {
+					continue
+				}
+
+				tile := tg.rows[cell.Y].Tiles[cell.X]
+				minX := origin.X + float64(cell.X)*width
+				minY := origin.Y + float64(cell.Y)*height
+
+				tile.draw(screen, tile.ImageAt(tg.elapsed), minX, minY, zoom, opacity)
+			}
+		}
+	}
+
+	if tg.frame%CacheKeepFrames == 0 {
+		tg.evictCached()
+	}
+}
+
+// cachedChunk gets a cached chunk, drawing it if it is new or changed.
+func (tg *TileGrid) cachedChunk(cx, cy int) *cachedChunk {
+	if tg.cache == nil {
+		tg.cache = map[topdown.Point[int]]*cachedChunk{}
+	}
+
+	key := topdown.Pt(cx, cy)
+
+	chunk, found := tg.cache[key]
+	if !found {
+		chunk = &cachedChunk{dirty: true}
+
+		tg.cache[key] = chunk
+	}
+
+	chunk.lastFrame = tg.frame
+
+	if chunk.dirty {
+		tg.drawChunk(chunk, cx, cy)
+	}
+
+	return chunk
+}
+
+// evictCached disposes of the cached chunks that haven't been drawn for
+// CacheKeepFrames frames.
+func (tg *TileGrid) evictCached() {
+	for key, chunk := range tg.cache {
+		if tg.frame-chunk.lastFrame < CacheKeepFrames {
+			continue
+		}
+
+		chunk.dispose()
+
+		delete(tg.cache, key)
+	}
+}
+
+// clearCache disposes of all the cached chunks.
+func (tg *TileGrid) clearCache() {
+	for _, chunk := range tg.cache {
+		chunk.dispose()
+	}
+
+	tg.cache = nil
+}
+
+// drawChunk draws the static tiles of a chunk into its image, which is
+// only made if there are any.
+func (tg *TileGrid) drawChunk(chunk *cachedChunk, cx, cy int) {
+	minCol, minRow := cx*CacheChunkSize, cy*CacheChunkSize
+	maxCol, maxRow := minCol+CacheChunkSize, minRow+CacheChunkSize
+
+	if maxCol > tg.nCols {
+		maxCol = tg.nCols
+	}
+
+	if maxRow > tg.nRows {
+		maxRow = tg.nRows
+	}
+
+	if chunk.image != nil {
+		chunk.image.Clear()
+	}
+
+	chunk.animated = []topdown.Point[int]{}
+	chunk.dirty = false
+
+	width, height := float64(tg.TileSize.Width), float64(tg.TileSize.Height)
+
+	for row := minRow; row < maxRow; row++ {
+		for col := minCol; col < maxCol; col++ {
+			tile := tg.rows[row].Tiles[col]
+			if tile == nil || tile.Image == nil {
+				continue
+			}
+
+			if len(tile.Frames) > 0 {
+				chunk.animated = append(chunk.animated, topdown.Pt(col, row))
+
+				continue
+			}
+
+			if chunk.image == nil {
+				chunk.image = ebiten.NewImage(
+					(maxCol-minCol)*tg.TileSize.Width, (maxRow-minRow)*tg.TileSize.Height)
+			}
+
+			x := float64(col-minCol) * width
+			y := float64(row-minRow) * height
+
+			tile.draw(chunk.image, tile.Image, x, y, 1, 1)
+		}
+	}
+}
+
+// invalidate marks the cached chunk with a cell as changed, so it is
+// drawn again.
+func (tg *TileGrid) invalidate(col, row int) {
+	if chunk, found := tg.cache[topdown.Pt(col/CacheChunkSize, row/CacheChunkSize)]; found {
+		chunk.dirty = true
+	}
+}
+
+func (c *cachedChunk) dispose() {
+	if c.image != nil {
+		c.image.Dispose()
+
+		c.image = nil
+	}
+}
@@ -0,0 +1,122 @@
+package tilegrid
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/animation"
+)
+
+func TestCachedChunkRedrawnWhenDirty(t *testing.T) {
+	tg := newCacheGrid(t, "S")
+
+	drawAll(tg)
+
+	require.Len(t, tg.cache, 4)
+
+	chunk := tg.cache[topdown.Pt(0, 0)]
+
+	require.NotNil(t, chunk.image)
+	assert.Empty(t, chunk.animated)
+
+	require.NoError(t, tg.SetTile(topdown.Pt(1, 2), "A"))
+
+	assert.True(t, chunk.dirty)
+	assert.False(t, tg.cache[topdown.Pt(1, 0)].dirty)
+
+	drawAll(tg)
+
+	assert.False(t, chunk.dirty)
+	assert.Equal(t, []topdown.Point[int]{{X: 1, Y: 2}}, chunk.animated)
+}
+
+func TestCachedChunkLeavesOutAnimatedTiles(t *testing.T) {
+	tg := newCacheGrid(t, "A")
+
+	drawAll(tg)
+
+	// the second chunk across only has the last four columns
+	chunk := tg.cache[topdown.Pt(1, 1)]
+
+	require.NotNil(t, chunk)
+	assert.Nil(t, chunk.image)
+	assert.Len(t, chunk.animated, 16)
+	assert.Contains(t, chunk.animated, topdown.Pt(19, 19))
+}
+
+func TestInitializeTilesResetsCache(t *testing.T) {
+	tg := newCacheGrid(t, "S")
+
+	drawAll(tg)
+
+	require.NotEmpty(t, tg.cache)
+
+	image := tg.cache[topdown.Pt(0, 0)].image
+
+	require.NoError(t, tg.InitializeTiles(tg.tiles))
+
+	assert.Empty(t, tg.cache)
+	assert.Panics(t, func() { image.Bounds() }, "image is not disposed")
+}
+
+func TestCachedChunksEvicted(t *testing.T) {
+	tg := newCacheGrid(t, "S")
+	screen := ebiten.NewImage(10, 10)
+
+	drawAll(tg)
+
+	image := tg.cache[topdown.Pt(1, 1)].image
+
+	// only the first chunk stays in view
+	for i := 0; i < 2*CacheKeepFrames; i++ {
+		tg.drawCached(screen, topdown.Pt(0.0, 0.0), 1, 1, 0, 3, 0, 3)
+	}
+
+	require.Len(t, tg.cache, 1)
+	assert.Contains(t, tg.cache, topdown.Pt(0, 0))
+	assert.Panics(t, func() { image.Bounds() }, "image is not disposed")
+
+	// seen again, the chunk is drawn again
+	drawAll(tg)
+
+	require.Len(t, tg.cache, 4)
+	assert.NotNil(t, tg.cache[topdown.Pt(1, 1)].image)
+}
+
+// newCacheGrid makes a 20x20 grid of one tile, which is static (S) or
+// animated (A).
+func newCacheGrid(t *testing.T, tileID string) *TileGrid {
+	static := ebiten.NewImage(8, 8)
+	frame := ebiten.NewImage(8, 8)
+	tiles := map[string]*Tile{
+		"S": {Image: static, XScale: 1, YScale: 1},
+		"A": {
+			Image:  static,
+			XScale: 1,
+			YScale: 1,
+			Frames: []animation.Frame{
+				{Image: static, Duration: time.Second},
+				{Image: frame, Duration: time.Second},
+			},
+		},
+	}
+	tg := New(topdown.Sz(8, 8))
+
+	for row := 0; row < 20; row++ {
+		tg.TileRows = append(tg.TileRows, strings.Repeat(tileID+RefIDSeparator, 19)+tileID)
+	}
+
+	require.NoError(t, tg.InitializeTiles(tiles))
+
+	return tg
+}
+
+func drawAll(tg *TileGrid) {
+	tg.drawCached(ebiten.NewImage(160, 160), topdown.Pt(0.0, 0.0), 1, 1, 0, tg.nCols-1, 0, tg.nRows-1)
+}
@@ -0,0 +1,75 @@
+package tilegrid_test
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/camera"
+	"github.com/jamestunnell/topdown/tilegrid"
+)
+
+func BenchmarkDraw(b *testing.B) {
+	for _, uncached := range []bool{true, false} {
+		for _, zoom := range []float64{0.25, 0.5, 1, 2} {
+			name := fmt.Sprintf("cached/zoom=%v", zoom)
+			if uncached {
+				name = fmt.Sprintf("uncached/zoom=%v", zoom)
+			}
+
+			b.Run(name, func(b *testing.B) {
+				tg := newBenchGrid(b, 200, 200)
+				tg.Uncached = uncached
+
+				cam, err := camera.New(topdown.Sz(640, 480))
+
+				require.NoError(b, err)
+
+				cam.Zoom(zoom)
+				cam.Move(topdown.Pt(1600.0, 1600.0))
+
+				screen := ebiten.NewImage(640, 480)
+
+				b.ResetTimer()
+
+				for i := 0; i < b.N; i++ {
+					tg.Draw(screen, cam)
+				}
+			})
+		}
+	}
+}
+
+// newBenchGrid makes a grid of 16x16 tiles in a checkered pattern.
+func newBenchGrid(b *testing.B, nCols, nRows int) *tilegrid.TileGrid {
+	tiles := map[string]*tilegrid.Tile{}
+
+	for i, c := range []color.Color{color.White, color.Black} {
+		img := ebiten.NewImage(16, 16)
+
+		img.Fill(c)
+
+		tiles[fmt.Sprint(i)] = &tilegrid.Tile{Image: img, XScale: 1, YScale: 1}
+	}
+
+	tg := tilegrid.New(topdown.Sz(16, 16))
+
+	for row := 0; row < nRows; row++ {
+		tileIDs := make([]string, nCols)
+
+		for col := range tileIDs {
+			tileIDs[col] = fmt.Sprint((row + col) % 2)
+		}
+
+		tg.TileRows = append(tg.TileRows, strings.Join(tileIDs, tilegrid.RefIDSeparator))
+	}
+
+	require.NoError(b, tg.InitializeTiles(tiles))
+
+	return tg
+}
@@ -0,0 +1,278 @@
+package tilegrid
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Tile data encodings, which store the cells as indices into a palette of
+// tile IDs, in row order.
+const (
+	// TileDataIndices stores the indices as an array.
+	TileDataIndices = "indices"
+	// TileDataRLE stores runs of the same index as count and index pairs.
+	// Runs can continue from one row to the next.
+	TileDataRLE = "rle"
+	// TileDataBase64Zlib stores the indices as little-endian uint32 values,
+	// compressed with zlib and encoded with base64.
+	TileDataBase64Zlib = "base64zlib"
+)
+
+// MaxTileDataCells is the most cells that tile data can decode to, so a
+// malformed file cannot allocate without bound.
+const MaxTileDataCells = 1 << 24
+
+// TileData is a compact alternative to tile rows for large grids.
+type TileData struct {
+	Encoding string `json:"encoding"`
+	// Columns is the number of columns, which divides the cells into rows.
+	Columns int `json:"columns"`
+	// Palette has the tile IDs that the indices refer to, which can include
+	// the empty tile ID.
+	Palette []string `json:"palette"`
+	Indices []int    `json:"indices,omitempty"`
+	Runs    []int    `json:"runs,omitempty"`
+	Data    string   `json:"data,omitempty"`
+}
+
+// EncodeTileRows encodes tile rows with the given encoding. Rows shorter
+// than the longest are padded with the empty tile. The palette has the
+// tile IDs in the order they first appear. There must be at least one
+// tile, as tile data always has cells.
+func EncodeTileRows(tileRows []string, encoding, emptyTile string) (*TileData, error) {
+	rows := make([][]string, len(tileRows))
+
+	for i, tileRow := range tileRows {
+		rows[i] = strings.Split(tileRow, RefIDSeparator)
+	}
+
+	return encodeTileIDs(rows, encoding, emptyTile)
+}
+
+// encodeTileIDs encodes the tile IDs of rows, like EncodeTileRows.
+func encodeTileIDs(rows [][]string, encoding, emptyTile string) (*TileData, error) {
+	paletteIndices := map[string]int{}
+	data := &TileData{Encoding: encoding, Palette: []string{}}
+	indices := []int{}
+
+	for _, tileIDs := range rows {
+		if len(tileIDs) > data.Columns {
+			data.Columns = len(tileIDs)
+		}
+	}
+
+	if data.Columns == 0 {
+		return nil, fmt.Errorf("there are no tiles to encode")
+	}
+
+	for _, tileIDs := range rows {
+		for col := 0; col < data.Columns; col++ {
+			tileID := emptyTile
+			if col < len(tileIDs) {
+				tileID = tileIDs[col]
+			}
+
+			index, found := paletteIndices[tileID]
+			if !found {
+				index = len(data.Palette)
+				paletteIndices[tileID] = index
+				data.Palette = append(data.Palette, tileID)
+			}
+
+			indices = append(indices, index)
+		}
+	}
+
+	switch encoding {
+	case TileDataIndices:
+		data.Indices = indices
+	case TileDataRLE:
+		data.Runs = encodeRuns(indices)
+	case TileDataBase64Zlib:
+		encoded, err := encodeBase64Zlib(indices)
+		if err != nil {
+			return nil, err
+		}
+
+		data.Data = encoded
+	default:
+		return nil, fmt.Errorf("unknown encoding '%s'", encoding)
+	}
+
+	return data, nil
+}
+
+// Decode gets the tile IDs of the cells, by row.
+func (d *TileData) Decode() ([][]string, error) {
+	if d.Columns <= 0 {
+		return nil, fmt.Errorf("column count %d is not positive", d.Columns)
+	}
+
+	var indices []int
+
+	switch d.Encoding {
+	case TileDataIndices:
+		indices = d.Indices
+	case TileDataRLE:
+		decoded, err := decodeRuns(d.Runs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode runs: %w", err)
+		}
+
+		indices = decoded
+	case TileDataBase64Zlib:
+		decoded, err := decodeBase64Zlib(d.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode data: %w", err)
+		}
+
+		indices = decoded
+	default:
+		return nil, fmt.Errorf("unknown encoding '%s'", d.Encoding)
+	}
+
+	if len(indices) > MaxTileDataCells {
+		return nil, fmt.Errorf("%d cells are more than the limit %d", len(indices), MaxTileDataCells)
+	}
+
+	if len(indices)%d.Columns != 0 {
+		return nil, fmt.Errorf("%d cells do not fill rows of %d columns", len(indices), d.Columns)
+	}
+
+	rows := make([][]string, len(indices)/d.Columns)
+
+	for i := range rows {
+		rows[i] = make([]string, d.Columns)
+
+		for j := range rows[i] {
+			index := indices[i*d.Columns+j]
+			if index < 0 || index >= len(d.Palette) {
+				return nil, fmt.Errorf("index %d at row %d, col %d is not in the palette", index, i, j)
+			}
+
+			rows[i][j] = d.Palette[index]
+		}
+	}
+
+	return rows, nil
+}
+
+// TileRows decodes the data into tile rows.
+func (d *TileData) TileRows() ([]string, error) {
+	rows, err := d.Decode()
+	if err != nil {
+		return nil, err
+	}
+
+	return joinRows(rows), nil
+}
+
+func encodeRuns(indices []int) []int {
+	runs := []int{}
+
+	for i := 0; i < len(indices); {
+		n := 1
+		for i+n < len(indices) && indices[i+n] == indices[i] {
+			n++
+		}
+
+		runs = append(runs, n, indices[i])
+
+		i += n
+	}
+
+	return runs
+}
+
+func decodeRuns(runs []int) ([]int, error) {
+	if len(runs)%2 != 0 {
+		return nil, fmt.Errorf("runs have an odd length %d", len(runs))
+	}
+
+	total := 0
+
+	// the counts are checked before allocating any cells
+	for i := 0; i < len(runs); i += 2 {
+		if runs[i] <= 0 {
+			return nil, fmt.Errorf("run %d has a count %d that is not positive", i/2, runs[i])
+		}
+
+		if runs[i] > MaxTileDataCells-total {
+			return nil, fmt.Errorf("run %d has a count %d that makes more than %d cells", i/2, runs[i], MaxTileDataCells)
+		}
+
+		total += runs[i]
+	}
+
+	indices := make([]int, 0, total)
+
+	for i := 0; i < len(runs); i += 2 {
+		for n := 0; n < runs[i]; n++ {
+			indices = append(indices, runs[i+1])
+		}
+	}
+
+	return indices, nil
+}
+
+func encodeBase64Zlib(indices []int) (string, error) {
+	var buf bytes.Buffer
+
+	w := zlib.NewWriter(&buf)
+	values := make([]byte, 4*len(indices))
+
+	for i, index := range indices {
+		binary.LittleEndian.PutUint32(values[4*i:], uint32(index))
+	}
+
+	if _, err := w.Write(values); err != nil {
+		return "", fmt.Errorf("failed to compress indices: %w", err)
+	}
+
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("failed to compress indices: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+func decodeBase64Zlib(data string) ([]int, error) {
+	compressed, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode base64: %w", err)
+	}
+
+	r, err := zlib.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("failed to make zlib reader: %w", err)
+	}
+
+	defer r.Close()
+
+	// one value past the limit is enough to reject the data
+	values, err := io.ReadAll(io.LimitReader(r, 4*(MaxTileDataCells+1)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress: %w", err)
+	}
+
+	if len(values) > 4*MaxTileDataCells {
+		return nil, fmt.Errorf("data has more than %d cells", MaxTileDataCells)
+	}
+
+	if len(values)%4 != 0 {
+		return nil, fmt.Errorf("data length %d is not a multiple of 4", len(values))
+	}
+
+	indices := make([]int, len(values)/4)
+
+	for i := range indices {
+		indices[i] = int(binary.LittleEndian.Uint32(values[4*i:]))
+	}
+
+	return indices, nil
+}
@@ -0,0 +1,188 @@
+package tilegrid_test
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/resource/restest"
+	"github.com/jamestunnell/topdown/tilegrid"
+)
+
+var tileDataEncodings = []string{tilegrid.TileDataIndices, tilegrid.TileDataRLE, tilegrid.TileDataBase64Zlib}
+
+func TestTileDataRoundTrip(t *testing.T) {
+	tileRows := []string{"G G G W", "G - - W", "W W W W"}
+
+	for _, encoding := range tileDataEncodings {
+		t.Run(encoding, func(t *testing.T) {
+			data, err := tilegrid.EncodeTileRows(tileRows, encoding, "-")
+
+			require.NoError(t, err)
+			assert.Equal(t, 4, data.Columns)
+			assert.Equal(t, []string{"G", "W", "-"}, data.Palette)
+
+			decoded, err := data.TileRows()
+
+			require.NoError(t, err)
+			assert.Equal(t, tileRows, decoded)
+		})
+	}
+}
+
+func TestTileDataRLE(t *testing.T) {
+	data, err := tilegrid.EncodeTileRows([]string{"G G G W", "W W - -"}, tilegrid.TileDataRLE, "-")
+
+	require.NoError(t, err)
+
+	// runs continue to the next row
+	assert.Equal(t, []int{3, 0, 3, 1, 2, 2}, data.Runs)
+}
+
+func TestTileDataEmpty(t *testing.T) {
+	for _, encoding := range tileDataEncodings {
+		_, err := tilegrid.EncodeTileRows([]string{}, encoding, "-")
+
+		assert.Error(t, err, encoding)
+	}
+}
+
+func TestTileDataPadsShortRows(t *testing.T) {
+	for _, encoding := range tileDataEncodings {
+		t.Run(encoding, func(t *testing.T) {
+			data, err := tilegrid.EncodeTileRows([]string{"G G W", "G", "W W"}, encoding, "-")
+
+			require.NoError(t, err)
+			assert.Equal(t, 3, data.Columns)
+
+			// the encoded data passes the schema and loads the padded rows
+			dir := t.TempDir()
+			d, err := json.Marshal(data)
+
+			require.NoError(t, err)
+
+			bg := `{"origin": {"x": 0, "y": 0}, "tileSize": {"w": 8, "h": 8}, "tileLinks": {"G": {}, "W": {}}, "tileData": ` +
+				string(d) + `}`
+
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "padded.background"), []byte(bg), 0o600))
+
+			loaded := getBackground(t, dir, "padded.background")
+
+			assert.Equal(t, []string{"G G W", "G - -", "W W -"}, loaded.CurrentTileRows())
+		})
+	}
+}
+
+func TestTileDataInvalid(t *testing.T) {
+	testCases := map[string]*tilegrid.TileData{
+		"no columns":       {Encoding: tilegrid.TileDataIndices, Palette: []string{"G"}, Indices: []int{0}},
+		"negative columns": {Encoding: tilegrid.TileDataIndices, Columns: -1, Palette: []string{"G"}},
+		"partial row":      {Encoding: tilegrid.TileDataIndices, Columns: 2, Palette: []string{"G"}, Indices: []int{0, 0, 0}},
+		"not in palette":   {Encoding: tilegrid.TileDataIndices, Columns: 1, Palette: []string{"G"}, Indices: []int{1}},
+		"odd runs":         {Encoding: tilegrid.TileDataRLE, Columns: 1, Palette: []string{"G"}, Runs: []int{1, 0, 1}},
+		"zero count":       {Encoding: tilegrid.TileDataRLE, Columns: 1, Palette: []string{"G"}, Runs: []int{0, 0}},
+		"too many cells":   {Encoding: tilegrid.TileDataRLE, Columns: 1, Palette: []string{"G"}, Runs: []int{tilegrid.MaxTileDataCells, 0, 1, 0}},
+		"huge count":       {Encoding: tilegrid.TileDataRLE, Columns: 1, Palette: []string{"G"}, Runs: []int{math.MaxInt, 0}},
+		"not base64":       {Encoding: tilegrid.TileDataBase64Zlib, Columns: 1, Palette: []string{"G"}, Data: "!!"},
+		"unknown encoding": {Encoding: "csv", Columns: 1, Palette: []string{"G"}},
+	}
+
+	for name, data := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := data.Decode()
+
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := tilegrid.EncodeTileRows([]string{"G G"}, "csv", "-")
+
+	assert.Error(t, err)
+}
+
+func TestBackgroundTileData(t *testing.T) {
+	for _, encoding := range tileDataEncodings {
+		t.Run(encoding, func(t *testing.T) {
+			dir := t.TempDir()
+			tg := makeEditGrid(t)
+
+			// the tile rows are encoded when saving
+			tg.TileData = &tilegrid.TileData{Encoding: encoding}
+
+			require.NoError(t, tg.Save(filepath.Join(dir, "encoded.background")))
+
+			bg := getBackground(t, dir, "encoded.background")
+
+			// the tile data is not kept as tile rows
+			assert.Empty(t, bg.TileRows)
+			assert.Equal(t, []string{"G G W", "G G W"}, bg.CurrentTileRows())
+			assert.Len(t, bg.StaticColliderShapes(), 1)
+
+			// edits are saved with the same encoding
+			require.NoError(t, bg.SetTile(topdown.Pt(0, 0), "M"))
+			require.NoError(t, bg.Save(filepath.Join(dir, "edited.background")))
+
+			d, err := os.ReadFile(filepath.Join(dir, "edited.background"))
+
+			require.NoError(t, err)
+			assert.NotContains(t, string(d), "tileRows")
+
+			bg = getBackground(t, dir, "edited.background")
+
+			assert.Equal(t, encoding, bg.TileData.Encoding)
+			assert.Equal(t, []string{"M G W", "G G W"}, bg.CurrentTileRows())
+		})
+	}
+}
+
+func TestBackgroundTileDataSchema(t *testing.T) {
+	testCases := map[string]string{
+		"missing runs": `"tileData": {"encoding": "rle", "columns": 1, "palette": ["G"], "indices": [0]}`,
+		"no columns":   `"tileData": {"encoding": "indices", "columns": 0, "palette": ["G"], "indices": [0]}`,
+		"both formats": `"tileRows": ["G"], "tileData": {"encoding": "indices", "columns": 1, "palette": ["G"], "indices": [0]}`,
+		"neither":      `"hidden": true`,
+	}
+
+	for name, field := range testCases {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			d := `{"origin": {"x": 0, "y": 0}, "tileSize": {"w": 8, "h": 8}, "tileLinks": {"G": {}}, ` + field + `}`
+
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "bad.background"), []byte(d), 0o600))
+
+			backgroundType, err := tilegrid.NewBackgroundType()
+
+			require.NoError(t, err)
+
+			mgr := restest.SetupManager(t, dir, backgroundType)
+
+			_, err = mgr.Get("bad.background")
+
+			assert.Error(t, err)
+		})
+	}
+}
+
+func getBackground(t *testing.T, dir, name string) *tilegrid.Background {
+	backgroundType, err := tilegrid.NewBackgroundType()
+
+	require.NoError(t, err)
+
+	mgr := restest.SetupManager(t, dir, backgroundType)
+
+	r, err := mgr.Get(name)
+
+	require.NoError(t, err)
+
+	bg, ok := r.(*tilegrid.Background)
+
+	require.True(t, ok)
+
+	return bg
+}
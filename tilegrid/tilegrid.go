@@ -2,17 +2,20 @@ package tilegrid
 
 import (
 	"fmt"
-	"math"
 	"strconv"
 	"strings"
+	"time"
 
 	mapset "github.com/deckarep/golang-set/v2"
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/rs/zerolog/log"
+	"github.com/zergon321/cirno"
 
 	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/animation"
 	"github.com/jamestunnell/topdown/camera"
-	"github.com/jamestunnell/topdown/drawing"
 	"github.com/jamestunnell/topdown/mathutil"
+	"github.com/jamestunnell/topdown/movecollide"
 	"github.com/jamestunnell/topdown/resource"
 	"github.com/jamestunnell/topdown/sliceutil"
 	"github.com/jamestunnell/topdown/sprite"
@@ -21,19 +24,53 @@ import (
 type TileGrid struct {
 	Origin    topdown.Point[float64] `json:"origin"`
 	TileSize  topdown.Size[int]      `json:"tileSize"`
-	TileLinks map[string]string      `json:"tileLinks"`
-	TileRows  []string               `json:"tileRows"`
+	TileLinks map[string]*TileLink   `json:"tileLinks"`
+	// TileRows are the rows the grid is made from. Edits are not made to
+	// them, so CurrentTileRows gets the rows with the edits.
+	TileRows []string `json:"tileRows,omitempty"`
+	// TileData is used instead of the tile rows for large grids. It is
+	// decoded when the grid is initialized, without making tile rows.
+	TileData *TileData `json:"tileData,omitempty"`
+	// DrawOrder is the drawing layer. The world background layer is used by default.
+	DrawOrder int `json:"drawLayer,omitempty"`
+	// Opacity ranges from 0 (transparent) to 1 (opaque), and is 1 by default.
+	Opacity *float64 `json:"opacity,omitempty"`
+	Hidden  bool     `json:"hidden,omitempty"`
+	// EmptyTile is the tile ID used in rows where there is no tile.
+	// DefaultEmptyTile is used if it is blank.
+	EmptyTile string `json:"emptyTile,omitempty"`
+	// Uncached draws each visible tile every frame, instead of drawing
+	// cached images of the static tiles. Only orthogonal grids are cached.
+	Uncached bool `json:"uncached,omitempty"`
+	// Projection places the tiles, like ProjectionIsometric. Orthogonal
+	// projection is used if it is blank.
+	Projection string `json:"projection,omitempty"`
 
 	worldArea topdown.Rectangle[float64]
 	center    topdown.Point[float64]
 	rows      []*Row
+	tileIDs   [][]string
 	nRows     int
 	nCols     int
+	colliders []cirno.Shape
+	elapsed   time.Duration
+	cache     map[topdown.Point[int]]*cachedChunk
+	frame     int
+	tiles     map[string]*Tile
+	listeners map[string]TileChangeListener
 }
 
 type Tile struct {
 	Image          *ebiten.Image
 	XScale, YScale float64
+	Solid          bool
+	Collider       *movecollide.ColliderSpec
+	Surface        *movecollide.Surface
+	Opaque         bool
+	FlipX, FlipY   bool
+	FlipDiagonal   bool
+	// Frames animate the tile, if there are any. The image is the first frame.
+	Frames []animation.Frame
 }
 
 type Row struct {
@@ -41,14 +78,15 @@ type Row struct {
 }
 
 const (
-	RefIDSeparator = " "
+	RefIDSeparator   = " "
+	DefaultEmptyTile = "-"
 )
 
 func New(tileSize topdown.Size[int]) *TileGrid {
 	tg := &TileGrid{
 		Origin:    topdown.Pt[float64](0, 0),
 		TileSize:  tileSize,
-		TileLinks: map[string]string{},
+		TileLinks: map[string]*TileLink{},
 		TileRows:  []string{},
 		rows:      []*Row{},
 	}
@@ -57,50 +95,113 @@ func New(tileSize topdown.Size[int]) *TileGrid {
 }
 
 func (tg *TileGrid) Initialize(mgr resource.Manager) error {
+	tiles, err := MakeTiles(mgr, tg.TileLinks, tg.TileSize)
+	if err != nil {
+		return err
+	}
+
+	return tg.InitializeTiles(tiles)
+}
+
+// MakeTiles makes the tiles for the given tile links, scaling the
+// sprites to fit the tile size. Tiles without a sprite or animation are
+// not drawn.
+func MakeTiles(mgr resource.Manager, links map[string]*TileLink, tileSize topdown.Size[int]) (map[string]*Tile, error) {
 	tiles := map[string]*Tile{}
 
-	for tileID, spriteLink := range tg.TileLinks {
-		l, err := sprite.ParseLink(spriteLink)
-		if err != nil {
-			return fmt.Errorf("failed to parse sprite link '%s': %w", spriteLink, err)
+	for tileID, tileLink := range links {
+		if tileLink.Collider != nil && tileLink.Collider.Approximated() {
+			log.Warn().Str("tile", tileID).Msg("tile collider polygon collides as its enclosing rectangle")
 		}
 
-		sprite, found := l.FindSprite(mgr)
-		if !found {
-			return fmt.Errorf("failed to find sprite with link '%s'", spriteLink)
+		tile := &Tile{
+			XScale:       1.0,
+			YScale:       1.0,
+			Solid:        tileLink.Solid,
+			Collider:     tileLink.Collider,
+			Surface:      tileLink.Surface,
+			Opaque:       tileLink.Opaque,
+			FlipX:        tileLink.FlipX,
+			FlipY:        tileLink.FlipY,
+			FlipDiagonal: tileLink.FlipDiagonal,
 		}
 
-		rect := sprite.Image.Bounds()
-		dx := rect.Dx()
-		dy := rect.Dy()
+		switch {
+		case tileLink.Animation != nil:
+			frames, err := tileLink.Animation.MakeFrames(mgr)
+			if err != nil {
+				return nil, fmt.Errorf("failed to make animation frames for tile '%s': %w", tileID, err)
+			}
 
-		tile := &Tile{
-			Image:  sprite.Image,
-			XScale: 1.0,
-			YScale: 1.0,
+			tile.Frames = frames
+			tile.Image = frames[0].Image
+		case tileLink.Sprite != "":
+			img, err := findSpriteImage(mgr, tileLink.Sprite)
+			if err != nil {
+				return nil, err
+			}
+
+			tile.Image = img
 		}
 
-		if dx != tg.TileSize.Width || dy != tg.TileSize.Height {
-			tile.XScale = float64(tg.TileSize.Width) / float64(dx)
-			tile.YScale = float64(tg.TileSize.Height) / float64(dy)
+		if tile.Image != nil {
+			rect := tile.Image.Bounds()
+			dx := rect.Dx()
+			dy := rect.Dy()
+
+			// the diagonal flip swaps the width and height
+			if tile.FlipDiagonal {
+				dx, dy = dy, dx
+			}
+
+			if dx != tileSize.Width || dy != tileSize.Height {
+				tile.XScale = float64(tileSize.Width) / float64(dx)
+				tile.YScale = float64(tileSize.Height) / float64(dy)
+			}
 		}
 
 		tiles[tileID] = tile
 	}
 
+	return tiles, nil
+}
+
+func findSpriteImage(mgr resource.Manager, spriteLink string) (*ebiten.Image, error) {
+	l, err := sprite.ParseLink(spriteLink)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse sprite link '%s': %w", spriteLink, err)
+	}
+
+	sprite, found := l.FindSprite(mgr)
+	if !found {
+		return nil, fmt.Errorf("failed to find sprite with link '%s'", spriteLink)
+	}
+
+	return sprite.Image, nil
+}
+
+// InitializeTiles lays out the grid with already made tiles.
+func (tg *TileGrid) InitializeTiles(tiles map[string]*Tile) error {
+	if err := validateProjection(tg.Projection); err != nil {
+		return err
+	}
+
 	if err := tg.MakeRows(tiles); err != nil {
 		return fmt.Errorf("failed to make rows: %w", err)
 	}
 
-	dX := float64(tg.nCols * tg.TileSize.Width)
-	dY := float64(tg.nRows * tg.TileSize.Height)
+	size := tg.areaSize()
 	tg.worldArea = topdown.Rectangle[float64]{
 		Min: tg.Origin,
-		Max: topdown.Pt(tg.Origin.X+dX, tg.Origin.Y+dY),
+		Max: topdown.Pt(tg.Origin.X+size.Width, tg.Origin.Y+size.Height),
 	}
 
 	tg.center = tg.worldArea.Min.Add(tg.worldArea.Size().Center())
 
+	if err := tg.MakeColliders(); err != nil {
+		return fmt.Errorf("failed to make colliders: %w", err)
+	}
+
 	return nil
 }
 
@@ -109,16 +210,24 @@ func (tg *TileGrid) Center() topdown.Point[float64] {
 }
 
 func (tg *TileGrid) MakeRows(tiles map[string]*Tile) error {
-	rows := make([]*Row, len(tg.TileRows))
+	tileIDRows, err := tg.tileIDRows()
+	if err != nil {
+		return err
+	}
+
+	rows := make([]*Row, len(tileIDRows))
 
 	columnCounts := mapset.NewSet[int]()
 
-	for i, tileRow := range tg.TileRows {
-		tileIDs := strings.Split(tileRow, RefIDSeparator)
+	for i, tileIDs := range tileIDRows {
 		nCols := len(tileIDs)
 		rowTiles := make([]*Tile, nCols)
 
 		for j, tileID := range tileIDs {
+			if tileID == tg.emptyTile() {
+				continue
+			}
+
 			tile, found := tiles[tileID]
 			if !found {
 				return fmt.Errorf("tile '%s' not defined", tileID)
@@ -153,12 +262,141 @@ func (tg *TileGrid) MakeRows(tiles map[string]*Tile) error {
 	tg.nRows = len(rows)
 	tg.nCols = columnCounts.ToSlice()[0]
 	tg.rows = rows
+	tg.tileIDs = tileIDRows
+	tg.clearCache()
+	tg.tiles = tiles
+
+	return nil
+}
+
+// tileIDRows gets the tile IDs by row, from the tile data if there is
+// any.
+func (tg *TileGrid) tileIDRows() ([][]string, error) {
+	if tg.TileData == nil {
+		rows := make([][]string, len(tg.TileRows))
+
+		for i, tileRow := range tg.TileRows {
+			rows[i] = strings.Split(tileRow, RefIDSeparator)
+		}
+
+		return rows, nil
+	}
+
+	rows, err := tg.TileData.Decode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode tile data: %w", err)
+	}
+
+	return rows, nil
+}
+
+// MakeColliders makes static colliders for the solid tiles. Adjacent solid
+// tiles are merged into rectangles, while tiles with a custom collider get
+// their own shape. Other than in orthogonal grids, solid tiles each get a
+// circle that fits in the tile.
+func (tg *TileGrid) MakeColliders() error {
+	solid := make([][]bool, tg.nRows)
+	colliders := []cirno.Shape{}
+	tileW, tileH := float64(tg.TileSize.Width), float64(tg.TileSize.Height)
+
+	for row, r := range tg.rows {
+		solid[row] = make([]bool, len(r.Tiles))
+
+		for col, tile := range r.Tiles {
+			if tile == nil || (!tile.Solid && tile.Collider == nil) {
+				continue
+			}
+
+			if tile.Collider == nil && tg.orthogonal() {
+				solid[row][col] = true
+
+				continue
+			}
+
+			spec := tile.Collider
+			if spec == nil {
+				spec = &movecollide.ColliderSpec{Shape: movecollide.ColliderShapeCircle, Radius: tg.solidRadius()}
+			}
+
+			pos := tg.TileCenter(topdown.Pt(col, row))
+
+			shape, err := movecollide.NewCollider(spec, topdown.Vec(pos.X, pos.Y))
+			if err != nil {
+				return fmt.Errorf("failed to make collider for tile at row %d, col %d: %w", row, col, err)
+			}
+
+			colliders = append(colliders, shape)
+		}
+	}
+
+	for _, rect := range MergeCells(solid) {
+		w, h := float64(rect.Dx())*tileW, float64(rect.Dy())*tileH
+		center := cirno.NewVector(
+			tg.Origin.X+float64(rect.Min.X)*tileW+w/2,
+			tg.Origin.Y+float64(rect.Min.Y)*tileH+h/2)
+
+		shape, err := cirno.NewRectangle(center, w, h, 0)
+		if err != nil {
+			return fmt.Errorf("failed to make collider rect: %w", err)
+		}
+
+		colliders = append(colliders, shape)
+	}
+
+	tg.colliders = colliders
 
 	return nil
 }
 
+// StaticColliderShapes gets the colliders made for the solid tiles.
+func (tg *TileGrid) StaticColliderShapes() []cirno.Shape {
+	return tg.colliders
+}
+
+// CollisionLayer puts the tile colliders in the wall layer.
+func (tg *TileGrid) CollisionLayer() movecollide.Layer {
+	return movecollide.LayerWall
+}
+
+func (tg *TileGrid) CollisionMask() movecollide.Layer {
+	return movecollide.LayerAll
+}
+
 func (tg *TileGrid) DrawLayer() int {
-	return drawing.LayerWorldBackground
+	return tg.DrawOrder
+}
+
+// Visible checks if the grid is drawn.
+func (tg *TileGrid) Visible() bool {
+	return !tg.Hidden
+}
+
+// SetVisible shows or hides the grid.
+func (tg *TileGrid) SetVisible(visible bool) {
+	tg.Hidden = !visible
+}
+
+// SetOpacity sets the opacity, from 0 (transparent) to 1 (opaque).
+func (tg *TileGrid) SetOpacity(opacity float64) {
+	opacity = mathutil.Clamp(opacity, 0, 1)
+
+	tg.Opacity = &opacity
+}
+
+func (tg *TileGrid) opacity() float64 {
+	if tg.Opacity == nil {
+		return 1
+	}
+
+	return *tg.Opacity
+}
+
+func (tg *TileGrid) emptyTile() string {
+	if tg.EmptyTile == "" {
+		return DefaultEmptyTile
+	}
+
+	return tg.EmptyTile
 }
 
 func (tg *TileGrid) DrawSortValue() float64 {
@@ -166,20 +404,51 @@ func (tg *TileGrid) DrawSortValue() float64 {
 }
 
 func (tg *TileGrid) VisibleColumns(visible topdown.Rectangle[float64]) (int, int) {
-	first := int((visible.Min.X - tg.Origin.X) / float64(tg.TileSize.Width))
-	last := int(math.Ceil((visible.Max.X - tg.Origin.X) / float64(tg.TileSize.Width)))
+	first, last, _, _ := tg.visibleCells(visible)
 
 	return mathutil.Clamp(first, 0, tg.nCols-1), mathutil.Clamp(last, 0, tg.nCols-1)
 }
 
 func (tg *TileGrid) VisibleRows(visible topdown.Rectangle[float64]) (int, int) {
-	first := int((visible.Min.Y - tg.Origin.Y) / float64(tg.TileSize.Height))
-	last := int(math.Ceil((visible.Max.Y - tg.Origin.Y) / float64(tg.TileSize.Height)))
+	_, _, first, last := tg.visibleCells(visible)
 
 	return mathutil.Clamp(first, 0, tg.nRows-1), mathutil.Clamp(last, 0, tg.nRows-1)
 }
 
+// TileAt gets the tile at a world position.
+func (tg *TileGrid) TileAt(pos topdown.Point[float64]) (*Tile, bool) {
+	cell, inside := tg.WorldToTile(pos)
+	if !inside {
+		return nil, false
+	}
+
+	tile := tg.rows[cell.Y].Tiles[cell.X]
+
+	return tile, tile != nil
+}
+
+// SurfaceAt gets the surface of the tile at a world position, if it has one.
+func (tg *TileGrid) SurfaceAt(pos topdown.Point[float64]) (*movecollide.Surface, bool) {
+	tile, found := tg.TileAt(pos)
+	if !found || tile.Surface == nil {
+		return nil, false
+	}
+
+	return tile.Surface, true
+}
+
+// UpdateAnimation advances the tile animations. All the instances of a
+// tile show the same frame, since the frame only depends on the time.
+func (tg *TileGrid) UpdateAnimation(delta time.Duration) {
+	tg.elapsed += delta
+}
+
 func (tg *TileGrid) Draw(screen *ebiten.Image, cam camera.Camera) {
+	opacity := tg.opacity()
+	if tg.Hidden || opacity <= 0 {
+		return
+	}
+
 	visible := cam.WorldArea()
 
 	// skip drawing if there is no visible portion of the tile grid
@@ -193,27 +462,100 @@ func (tg *TileGrid) Draw(screen *ebiten.Image, cam camera.Camera) {
 
 	origin, _ := cam.ConvertWorldToScreen(tg.Origin)
 	zoom := cam.ZoomLevel()
-	width := float64(tg.TileSize.Width) * zoom
-	height := float64(tg.TileSize.Height) * zoom
 
-	for row := firstRow; row <= lastRow; row++ {
-		for col := firstColumn; col <= lastColumn; col++ {
-			tile := tg.rows[row].Tiles[col]
-			opts := &ebiten.DrawImageOptions{}
+	if !tg.Uncached && tg.orthogonal() {
+		tg.drawCached(screen, origin, zoom, opacity, firstColumn, lastColumn, firstRow, lastRow)
 
-			sx := zoom * tile.XScale
-			sy := zoom * tile.YScale
+		return
+	}
 
-			if sx != 1 || sy != 1 {
-				opts.GeoM.Scale(sx, sy)
-			}
+	tg.eachCellInDrawOrder(firstColumn, lastColumn, firstRow, lastRow, func(col, row int) {
+		tile := tg.rows[row].Tiles[col]
+		if tile == nil || tile.Image == nil {
+			return
+		}
+
+		offset := tg.cellOffset(col, row)
+
+		tile.draw(screen, tile.ImageAt(tg.elapsed), origin.X+offset.X*zoom, origin.Y+offset.Y*zoom, zoom, opacity)
+	})
+}
+
+// draw draws a tile image with its top-left corner at x, y, scaled by the zoom.
+func (t *Tile) draw(dst, img *ebiten.Image, x, y, zoom, opacity float64) {
+	opts := &ebiten.DrawImageOptions{}
+
+	if opacity < 1 {
+		opts.ColorM.Scale(1, 1, 1, opacity)
+	}
+
+	t.flip(&opts.GeoM)
+
+	sx := zoom * t.XScale
+	sy := zoom * t.YScale
+
+	if sx != 1 || sy != 1 {
+		opts.GeoM.Scale(sx, sy)
+	}
+
+	opts.GeoM.Translate(x, y)
+
+	dst.DrawImage(img, opts)
+}
+
+// flip applies the tile flips to a transform, keeping the flipped image
+// where the unflipped image would be.
+func (t *Tile) flip(geoM *ebiten.GeoM) {
+	rect := t.Image.Bounds()
+	w, h := float64(rect.Dx()), float64(rect.Dy())
 
-			minX := origin.X + float64(col)*width
-			minY := origin.Y + float64(row)*height
+	if t.FlipDiagonal {
+		// swap x and y
+		geoM.SetElement(0, 0, 0)
+		geoM.SetElement(0, 1, 1)
+		geoM.SetElement(1, 0, 1)
+		geoM.SetElement(1, 1, 0)
 
-			opts.GeoM.Translate(minX, minY)
+		w, h = h, w
+	}
+
+	if t.FlipX {
+		geoM.Scale(-1, 1)
+		geoM.Translate(w, 0)
+	}
+
+	if t.FlipY {
+		geoM.Scale(1, -1)
+		geoM.Translate(0, h)
+	}
+}
+
+// ImageAt gets the tile image at the given time, which is the frame shown
+// at that point in the looping animation if the tile is animated.
+func (t *Tile) ImageAt(elapsed time.Duration) *ebiten.Image {
+	if len(t.Frames) == 0 {
+		return t.Image
+	}
 
-			screen.DrawImage(tile.Image, opts)
+	var period time.Duration
+
+	for _, frame := range t.Frames {
+		period += frame.Duration
+	}
+
+	if period <= 0 {
+		return t.Image
+	}
+
+	offset := elapsed % period
+
+	for _, frame := range t.Frames {
+		if offset < frame.Duration {
+			return frame.Image
 		}
+
+		offset -= frame.Duration
 	}
+
+	return t.Image
 }
@@ -0,0 +1,182 @@
+package tilegrid_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zergon321/cirno"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/animation"
+	"github.com/jamestunnell/topdown/movecollide"
+	"github.com/jamestunnell/topdown/tilegrid"
+)
+
+func TestTileLinkJSON(t *testing.T) {
+	var links map[string]*tilegrid.TileLink
+
+	d := []byte(`{
+		"A": "grass.spritesheet#abc",
+		"B": {"sprite": "wall.spritesheet#def", "solid": true},
+		"C": {"sprite": "rock.spritesheet#ghi", "collider": {"shape": "circle", "radius": 4}},
+		"D": {"sprite": "wall.spritesheet#def", "flipX": true, "flipDiagonal": true, "properties": {"kind": "wall"}},
+		"E": {"solid": true, "opaque": true},
+		"F": {"animation": {"spriteSheet": "water.spritesheet", "tag": "ripple", "frameDuration": "100ms"}},
+		"G": {"animation": {"frames": [{"sprite": "lava.spritesheet#a", "duration": "150ms"}]}}
+	}`)
+
+	require.NoError(t, json.Unmarshal(d, &links))
+
+	assert.Equal(t, &tilegrid.TileLink{Sprite: "grass.spritesheet#abc"}, links["A"])
+	assert.Equal(t, &tilegrid.TileLink{Sprite: "wall.spritesheet#def", Solid: true}, links["B"])
+	assert.Equal(t, "rock.spritesheet#ghi", links["C"].Sprite)
+	require.NotNil(t, links["C"].Collider)
+	assert.Equal(t, movecollide.ColliderShapeCircle, links["C"].Collider.Shape)
+	assert.Equal(t, &tilegrid.TileLink{
+		Sprite:       "wall.spritesheet#def",
+		FlipX:        true,
+		FlipDiagonal: true,
+		Properties:   tilegrid.Properties{"kind": "wall"},
+	}, links["D"])
+	assert.Equal(t, &tilegrid.TileLink{Solid: true, Opaque: true}, links["E"])
+	assert.Equal(t, &tilegrid.TileAnimation{
+		SpriteSheet:   "water.spritesheet",
+		Tag:           "ripple",
+		FrameDuration: "100ms",
+	}, links["F"].Animation)
+	assert.Equal(t, &tilegrid.TileAnimation{
+		Frames: []*tilegrid.TileFrame{{Sprite: "lava.spritesheet#a", Duration: "150ms"}},
+	}, links["G"].Animation)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"A": 5}`), &links))
+}
+
+func TestMakeTilesWithoutSprite(t *testing.T) {
+	links := map[string]*tilegrid.TileLink{
+		"W": {Solid: true},
+	}
+
+	tiles, err := tilegrid.MakeTiles(nil, links, topdown.Size[int]{Width: 10, Height: 10})
+
+	require.NoError(t, err)
+	require.Contains(t, tiles, "W")
+	assert.Nil(t, tiles["W"].Image)
+	assert.True(t, tiles["W"].Solid)
+}
+
+func TestTileImageAt(t *testing.T) {
+	a, b := &ebiten.Image{}, &ebiten.Image{}
+	tile := &tilegrid.Tile{
+		Image: a,
+		Frames: []animation.Frame{
+			{Image: a, Duration: 100 * time.Millisecond},
+			{Image: b, Duration: 50 * time.Millisecond},
+		},
+	}
+
+	assert.Same(t, a, tile.ImageAt(0))
+	assert.Same(t, a, tile.ImageAt(99*time.Millisecond))
+	assert.Same(t, b, tile.ImageAt(100*time.Millisecond))
+	assert.Same(t, b, tile.ImageAt(149*time.Millisecond))
+	// loops
+	assert.Same(t, a, tile.ImageAt(150*time.Millisecond))
+	assert.Same(t, b, tile.ImageAt(420*time.Millisecond))
+
+	still := &tilegrid.Tile{Image: a}
+
+	assert.Same(t, a, still.ImageAt(time.Second))
+}
+
+func TestMakeTilesAnimationInvalid(t *testing.T) {
+	links := map[string]*tilegrid.TileLink{
+		"L": {Animation: &tilegrid.TileAnimation{
+			Frames: []*tilegrid.TileFrame{{Sprite: "lava.spritesheet#a", Duration: "0s"}},
+		}},
+	}
+
+	_, err := tilegrid.MakeTiles(nil, links, topdown.Size[int]{Width: 10, Height: 10})
+
+	assert.Error(t, err)
+}
+
+func TestMakeColliders(t *testing.T) {
+	tg := tilegrid.New(topdown.Size[int]{Width: 10, Height: 10})
+
+	tg.Origin = topdown.Pt(100.0, 0.0)
+	tg.TileRows = []string{
+		"W W .",
+		"W W R",
+	}
+
+	tiles := map[string]*tilegrid.Tile{
+		".": {},
+		"W": {Solid: true},
+		"R": {Collider: &movecollide.ColliderSpec{Shape: movecollide.ColliderShapeCircle, Radius: 3}},
+	}
+
+	require.NoError(t, tg.MakeRows(tiles))
+	require.NoError(t, tg.MakeColliders())
+
+	shapes := tg.StaticColliderShapes()
+
+	require.Len(t, shapes, 2)
+
+	// custom colliders come first, centered in their tile
+	circle, ok := shapes[0].(*cirno.Circle)
+
+	require.True(t, ok)
+	assert.Equal(t, cirno.NewVector(125, 15), circle.Center())
+
+	// the solid tiles are merged into one rect
+	rect, ok := shapes[1].(*cirno.Rectangle)
+
+	require.True(t, ok)
+	assert.Equal(t, cirno.NewVector(110, 10), rect.Center())
+	assert.Equal(t, 20.0, rect.Width())
+	assert.Equal(t, 20.0, rect.Height())
+}
+
+func TestSurfaceAt(t *testing.T) {
+	mud := &movecollide.Surface{Name: "mud", SpeedScale: 0.5}
+	tg := tilegrid.New(topdown.Size[int]{Width: 10, Height: 10})
+
+	tg.Origin = topdown.Pt(-10.0, 0.0)
+	tg.TileRows = []string{
+		". M",
+		"M .",
+	}
+
+	tiles := map[string]*tilegrid.Tile{
+		".": {},
+		"M": {Surface: mud},
+	}
+
+	require.NoError(t, tg.MakeRows(tiles))
+
+	surface, found := tg.SurfaceAt(topdown.Pt(5.0, 5.0))
+
+	assert.True(t, found)
+	assert.Equal(t, mud, surface)
+
+	surface, found = tg.SurfaceAt(topdown.Pt(-5.0, 15.0))
+
+	assert.True(t, found)
+	assert.Equal(t, mud, surface)
+
+	_, found = tg.SurfaceAt(topdown.Pt(-5.0, 5.0))
+
+	assert.False(t, found)
+
+	_, found = tg.SurfaceAt(topdown.Pt(-15.0, 5.0))
+
+	assert.False(t, found)
+
+	_, found = tg.TileAt(topdown.Pt(5.0, 25.0))
+
+	assert.False(t, found)
+}
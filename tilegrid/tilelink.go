@@ -0,0 +1,76 @@
+package tilegrid
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jamestunnell/topdown/movecollide"
+)
+
+// TileLink links a tile ID to a sprite, along with the tile properties.
+// In JSON, it is either the sprite link alone or an object.
+type TileLink struct {
+	// Sprite is the sprite link. A tile without a sprite or animation is
+	// not drawn, but can still be solid or have a surface.
+	Sprite string `json:"sprite,omitempty"`
+	// Animation animates the tile, and is used instead of the sprite.
+	Animation *TileAnimation `json:"animation,omitempty"`
+	// Solid tiles get a static collider. Adjacent solid tiles without a
+	// custom collider are merged into as few colliders as possible.
+	Solid bool `json:"solid,omitempty"`
+	// Collider is a custom collider shape, placed relative to the tile
+	// center. A tile with a collider is solid.
+	Collider *movecollide.ColliderSpec `json:"collider,omitempty"`
+	// Surface is the surface of the tile, like mud or ice.
+	Surface *movecollide.Surface `json:"surface,omitempty"`
+	// Opaque tiles block sight (see FieldOfView).
+	Opaque bool `json:"opaque,omitempty"`
+	// FlipX, FlipY and FlipDiagonal flip the sprite horizontally, vertically
+	// and across the diagonal from top-left to bottom-right. The diagonal
+	// flip is done first, so it can be combined with the others to rotate.
+	FlipX        bool `json:"flipX,omitempty"`
+	FlipY        bool `json:"flipY,omitempty"`
+	FlipDiagonal bool `json:"flipDiagonal,omitempty"`
+	// Properties are custom tile properties.
+	Properties Properties `json:"properties,omitempty"`
+}
+
+// UnmarshalJSON parses a sprite link, or an object with the sprite link and tile properties.
+func (tl *TileLink) UnmarshalJSON(d []byte) error {
+	var sprite string
+
+	if err := json.Unmarshal(d, &sprite); err == nil {
+		*tl = TileLink{Sprite: sprite}
+
+		return nil
+	}
+
+	type plainLink TileLink
+
+	var pl plainLink
+
+	if err := json.Unmarshal(d, &pl); err != nil {
+		return fmt.Errorf("tile link is not a string or object: %w", err)
+	}
+
+	*tl = TileLink(pl)
+
+	return nil
+}
+
+// MarshalJSON writes a link with only a sprite as the sprite link, and
+// other links as an object.
+func (tl TileLink) MarshalJSON() ([]byte, error) {
+	if tl.Sprite != "" && tl.spriteOnly() {
+		return json.Marshal(tl.Sprite)
+	}
+
+	type plainLink TileLink
+
+	return json.Marshal(plainLink(tl))
+}
+
+func (tl TileLink) spriteOnly() bool {
+	return tl.Animation == nil && !tl.Solid && tl.Collider == nil && tl.Surface == nil && !tl.Opaque &&
+		!tl.FlipX && !tl.FlipY && !tl.FlipDiagonal && len(tl.Properties) == 0
+}
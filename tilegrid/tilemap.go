@@ -0,0 +1,316 @@
+package tilegrid
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zergon321/cirno"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/drawing"
+	"github.com/jamestunnell/topdown/movecollide"
+	"github.com/jamestunnell/topdown/resource"
+)
+
+// TileMap is a map made of named tile layers, which share the origin,
+// tile size and tile links. Each layer is drawn on its own drawing layer,
+// so that overhead layers like roofs can cover characters.
+type TileMap struct {
+	Origin    topdown.Point[float64] `json:"origin"`
+	TileSize  topdown.Size[int]      `json:"tileSize"`
+	TileLinks map[string]*TileLink   `json:"tileLinks"`
+	// EmptyTile is the tile ID used in rows where there is no tile.
+	// DefaultEmptyTile is used if it is blank.
+	EmptyTile string `json:"emptyTile,omitempty"`
+	// Projection places the tiles of all the layers, like
+	// ProjectionIsometric. Orthogonal projection is used if it is blank.
+	Projection string       `json:"projection,omitempty"`
+	Layers     []*TileLayer `json:"layers"`
+	// Spawns are the places to spawn entities.
+	Spawns []*SpawnPoint `json:"spawns,omitempty"`
+	// Triggers are areas for the game to make triggers from.
+	Triggers []*Area `json:"triggers,omitempty"`
+	// Colliders are areas that get static colliders, along with the solid tiles.
+	Colliders  []*Area    `json:"colliders,omitempty"`
+	Properties Properties `json:"properties,omitempty"`
+
+	grids     []*TileGrid
+	colliders []cirno.Shape
+	terrains  map[string]*TerrainGrid
+}
+
+// TileLayer is a named layer of tiles. Rows can be left short, or left
+// out at the end, where a layer has no more tiles.
+type TileLayer struct {
+	Name string `json:"name"`
+	// DrawOrder is the drawing layer. The world background layer is used by default.
+	DrawOrder int `json:"drawLayer,omitempty"`
+	// Opacity ranges from 0 (transparent) to 1 (opaque), and is 1 by default.
+	Opacity  *float64 `json:"opacity,omitempty"`
+	Hidden   bool     `json:"hidden,omitempty"`
+	TileRows []string `json:"tileRows"`
+	// AutoTile is the autotile rules ref. The tile rows are picked from the
+	// terrain rows by the rules when the map is initialized.
+	AutoTile    string     `json:"autoTile,omitempty"`
+	TerrainRows []string   `json:"terrainRows,omitempty"`
+	Properties  Properties `json:"properties,omitempty"`
+}
+
+func (m *TileMap) Initialize(mgr resource.Manager) error {
+	if err := m.pickTerrainTiles(mgr); err != nil {
+		return err
+	}
+
+	tiles, err := MakeTiles(mgr, m.TileLinks, m.TileSize)
+	if err != nil {
+		return err
+	}
+
+	return m.InitializeTiles(tiles)
+}
+
+// pickTerrainTiles sets the tile rows of the autotiled layers.
+func (m *TileMap) pickTerrainTiles(mgr resource.Manager) error {
+	terrains := map[string]*TerrainGrid{}
+
+	for _, layer := range m.Layers {
+		if layer.AutoTile == "" {
+			continue
+		}
+
+		rules, err := resource.GetAs[*AutoTileRules](mgr, layer.AutoTile)
+		if err != nil {
+			return fmt.Errorf("failed to get autotile rules '%s' for layer '%s': %w", layer.AutoTile, layer.Name, err)
+		}
+
+		for _, rule := range rules.Terrains {
+			for _, tileID := range rule.tileIDs() {
+				if _, found := m.TileLinks[tileID]; !found {
+					return fmt.Errorf("autotile rules '%s' use tile '%s', which is not linked", layer.AutoTile, tileID)
+				}
+			}
+		}
+
+		grid, err := NewTerrainGrid(rules, layer.TerrainRows, m.emptyTile())
+		if err != nil {
+			return fmt.Errorf("failed to make terrain grid for layer '%s': %w", layer.Name, err)
+		}
+
+		layer.TileRows = grid.TileRows(m.emptyTile())
+		terrains[layer.Name] = grid
+	}
+
+	m.terrains = terrains
+
+	return nil
+}
+
+// InitializeTiles makes a tile grid for each layer with already made
+// tiles. Layers are padded with empty tiles to the size of the largest.
+func (m *TileMap) InitializeTiles(tiles map[string]*Tile) error {
+	if len(m.Layers) == 0 {
+		return fmt.Errorf("map has no layers")
+	}
+
+	emptyTile := m.emptyTile()
+	layerRows := make([][][]string, len(m.Layers))
+	names := map[string]bool{}
+	nRows, nCols := 0, 0
+
+	for i, layer := range m.Layers {
+		if names[layer.Name] {
+			return fmt.Errorf("layer name '%s' is not unique", layer.Name)
+		}
+
+		names[layer.Name] = true
+		layerRows[i] = make([][]string, len(layer.TileRows))
+
+		for j, tileRow := range layer.TileRows {
+			tileIDs := strings.Split(tileRow, RefIDSeparator)
+
+			if len(tileIDs) > nCols {
+				nCols = len(tileIDs)
+			}
+
+			layerRows[i][j] = tileIDs
+		}
+
+		if len(layer.TileRows) > nRows {
+			nRows = len(layer.TileRows)
+		}
+	}
+
+	grids := make([]*TileGrid, len(m.Layers))
+
+	for i, layer := range m.Layers {
+		tileRows := make([]string, nRows)
+
+		for j := range tileRows {
+			tileIDs := []string{}
+			if j < len(layerRows[i]) {
+				tileIDs = layerRows[i][j]
+			}
+
+			for len(tileIDs) < nCols {
+				tileIDs = append(tileIDs, emptyTile)
+			}
+
+			tileRows[j] = strings.Join(tileIDs, RefIDSeparator)
+		}
+
+		grid := &TileGrid{
+			Origin:     m.Origin,
+			TileSize:   m.TileSize,
+			TileLinks:  m.TileLinks,
+			TileRows:   tileRows,
+			DrawOrder:  layer.DrawOrder,
+			Opacity:    layer.Opacity,
+			Hidden:     layer.Hidden,
+			EmptyTile:  emptyTile,
+			Projection: m.Projection,
+		}
+
+		if err := grid.InitializeTiles(tiles); err != nil {
+			return fmt.Errorf("failed to initialize layer '%s': %w", layer.Name, err)
+		}
+
+		grids[i] = grid
+	}
+
+	colliders := make([]cirno.Shape, len(m.Colliders))
+
+	for i, area := range m.Colliders {
+		shape, err := area.Shape()
+		if err != nil {
+			return fmt.Errorf("failed to make collider %d '%s': %w", i, area.Name, err)
+		}
+
+		colliders[i] = shape
+	}
+
+	m.grids = grids
+	m.colliders = colliders
+
+	return nil
+}
+
+// SetTerrain changes the terrain of a cell in an autotiled layer, then
+// updates the tiles of the cell and its neighbors. The change listeners
+// of the layer grid are told about each changed tile.
+func (m *TileMap) SetTerrain(layerName string, col, row int, terrain string) error {
+	terrains, found := m.terrains[layerName]
+	if !found {
+		return fmt.Errorf("layer '%s' is not autotiled", layerName)
+	}
+
+	grid, found := m.Layer(layerName)
+	if !found {
+		return fmt.Errorf("layer '%s' is not initialized", layerName)
+	}
+
+	changed, err := terrains.SetTerrain(col, row, terrain)
+	if err != nil {
+		return err
+	}
+
+	for _, cell := range changed {
+		tileID, _ := terrains.TileID(cell.X, cell.Y)
+		if tileID == "" {
+			tileID = m.emptyTile()
+		}
+
+		if err := grid.SetTile(cell, tileID); err != nil {
+			return err
+		}
+	}
+
+	for _, layer := range m.Layers {
+		if layer.Name == layerName {
+			layer.TerrainRows = terrains.TerrainRows()
+			layer.TileRows = terrains.TileRows(m.emptyTile())
+		}
+	}
+
+	return nil
+}
+
+// UpdateAnimation advances the tile animations of all the layers.
+func (m *TileMap) UpdateAnimation(delta time.Duration) {
+	for _, grid := range m.grids {
+		grid.UpdateAnimation(delta)
+	}
+}
+
+// Layer gets the tile grid made for the layer with the given name.
+func (m *TileMap) Layer(name string) (*TileGrid, bool) {
+	for i, layer := range m.Layers {
+		if layer.Name == name && i < len(m.grids) {
+			return m.grids[i], true
+		}
+	}
+
+	return nil, false
+}
+
+// Drawables gets the tile grids made for the layers, in layer order.
+func (m *TileMap) Drawables() []drawing.Drawable {
+	drawables := make([]drawing.Drawable, len(m.grids))
+
+	for i, grid := range m.grids {
+		drawables[i] = grid
+	}
+
+	return drawables
+}
+
+// StaticColliderShapes gets the colliders made for the solid tiles of all
+// the layers, followed by the collider areas.
+func (m *TileMap) StaticColliderShapes() []cirno.Shape {
+	shapes := []cirno.Shape{}
+
+	for _, grid := range m.grids {
+		shapes = append(shapes, grid.StaticColliderShapes()...)
+	}
+
+	return append(shapes, m.colliders...)
+}
+
+// CollisionLayer puts the tile colliders in the wall layer.
+func (m *TileMap) CollisionLayer() movecollide.Layer {
+	return movecollide.LayerWall
+}
+
+func (m *TileMap) CollisionMask() movecollide.Layer {
+	return movecollide.LayerAll
+}
+
+func (m *TileMap) emptyTile() string {
+	if m.EmptyTile == "" {
+		return DefaultEmptyTile
+	}
+
+	return m.EmptyTile
+}
+
+// Opaque checks if a tile in any of the layers blocks sight in a cell.
+func (m *TileMap) Opaque(cell topdown.Point[int]) bool {
+	for _, grid := range m.grids {
+		if grid.Opaque(cell) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SurfaceAt gets the surface of the top-most tile with a surface at a world position.
+func (m *TileMap) SurfaceAt(pos topdown.Point[float64]) (*movecollide.Surface, bool) {
+	for i := len(m.grids) - 1; i >= 0; i-- {
+		if surface, found := m.grids[i].SurfaceAt(pos); found {
+			return surface, true
+		}
+	}
+
+	return nil, false
+}
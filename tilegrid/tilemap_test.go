@@ -0,0 +1,139 @@
+package tilegrid_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jamestunnell/topdown"
+	"github.com/jamestunnell/topdown/drawing"
+	"github.com/jamestunnell/topdown/movecollide"
+	"github.com/jamestunnell/topdown/tilegrid"
+)
+
+func TestTileMapLayers(t *testing.T) {
+	mud := &movecollide.Surface{Name: "mud"}
+	bridge := &movecollide.Surface{Name: "bridge"}
+	half := 0.5
+	m := &tilegrid.TileMap{
+		TileSize: topdown.Size[int]{Width: 10, Height: 10},
+		Layers: []*tilegrid.TileLayer{
+			{
+				Name:     "ground",
+				TileRows: []string{"M M M", "W M M", "M M M"},
+			},
+			{
+				Name:      "overhead",
+				DrawOrder: drawing.LayerWorldOverlay,
+				Opacity:   &half,
+				// sparse, with short rows
+				TileRows: []string{"-", "- B"},
+			},
+		},
+	}
+
+	tiles := map[string]*tilegrid.Tile{
+		"M": {Surface: mud},
+		"W": {Solid: true},
+		"B": {Surface: bridge},
+	}
+
+	require.NoError(t, m.InitializeTiles(tiles))
+
+	ground, found := m.Layer("ground")
+
+	require.True(t, found)
+	assert.Equal(t, drawing.LayerWorldBackground, ground.DrawLayer())
+
+	overhead, found := m.Layer("overhead")
+
+	require.True(t, found)
+	assert.Equal(t, drawing.LayerWorldOverlay, overhead.DrawLayer())
+	assert.True(t, overhead.Visible())
+
+	// padded to the size of the ground layer
+	assert.Equal(t, []string{"- - -", "- B -", "- - -"}, overhead.TileRows)
+
+	_, found = overhead.TileAt(topdown.Pt(5.0, 5.0))
+
+	assert.False(t, found)
+
+	_, found = m.Layer("roof")
+
+	assert.False(t, found)
+
+	// the top-most layer with a surface wins
+	surface, _ := m.SurfaceAt(topdown.Pt(15.0, 15.0))
+
+	assert.Equal(t, bridge, surface)
+
+	surface, _ = m.SurfaceAt(topdown.Pt(5.0, 5.0))
+
+	assert.Equal(t, mud, surface)
+
+	assert.Len(t, m.StaticColliderShapes(), 1)
+
+	overhead.SetVisible(false)
+
+	assert.False(t, overhead.Visible())
+}
+
+func TestTileMapInvalid(t *testing.T) {
+	tiles := map[string]*tilegrid.Tile{"A": {}}
+	maps := map[string]*tilegrid.TileMap{
+		"no layers": {},
+		"duplicate names": {
+			Layers: []*tilegrid.TileLayer{
+				{Name: "a", TileRows: []string{"A"}},
+				{Name: "a", TileRows: []string{"A"}},
+			},
+		},
+		"unknown tile": {
+			Layers: []*tilegrid.TileLayer{
+				{Name: "a", TileRows: []string{"A B"}},
+			},
+		},
+	}
+
+	for name, m := range maps {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, m.InitializeTiles(tiles))
+		})
+	}
+}
+
+func TestTileMapColliderAreas(t *testing.T) {
+	m := &tilegrid.TileMap{
+		TileSize: topdown.Size[int]{Width: 10, Height: 10},
+		Layers: []*tilegrid.TileLayer{
+			{Name: "ground", TileRows: []string{"W -"}},
+		},
+		Colliders: []*tilegrid.Area{
+			{
+				Name:     "fence",
+				Position: topdown.Vec(20, 0),
+				Collider: &movecollide.ColliderSpec{
+					Shape:  movecollide.ColliderShapeRect,
+					Size:   &topdown.Size[float64]{Width: 4, Height: 10},
+					Offset: topdown.Vec(2, 5),
+				},
+			},
+		},
+	}
+
+	require.NoError(t, m.InitializeTiles(map[string]*tilegrid.Tile{"W": {Solid: true}}))
+
+	shapes := m.StaticColliderShapes()
+
+	require.Len(t, shapes, 2)
+
+	min, max := movecollide.ShapeBounds(shapes[1])
+
+	assert.InDelta(t, 20, min.X, 1e-9)
+	assert.InDelta(t, 24, max.X, 1e-9)
+
+	m.Colliders[0].Collider = &movecollide.ColliderSpec{Shape: movecollide.ColliderShapeCircle}
+
+	assert.Error(t, m.InitializeTiles(map[string]*tilegrid.Tile{"W": {Solid: true}}))
+}